@@ -0,0 +1,103 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Rebuild contracts
+// @Description Force a rebuild of the cached contract artifacts used by deployments. The build runs in the background
+// @Tags admin
+// @Produce json
+// @Success 200 {object} StandardResponse
+// @Failure 500 {object} StandardResponse
+// @Router /admin/build [post]
+func handleBuild(c *gin.Context) {
+	build, err := service.StartBuild(service.CurrentConfig().Paths.Contracts, true)
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    500,
+			Message: "Build failed to start",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Build started",
+		Data:    build,
+	})
+}
+
+// @Summary Get build status
+// @Description Get the status of the latest build of the cached contract artifacts
+// @Tags admin
+// @Produce json
+// @Success 200 {object} StandardResponse
+// @Router /admin/build [get]
+func handleGetBuild(c *gin.Context) {
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    service.CurrentBuild(),
+	})
+}
+
+// @Summary Get readiness details
+// @Description Run the checks of GET /readyz and return their details and errors, such as the RPC URLs, the deployer address and balance and the build error
+// @Tags admin
+// @Produce json
+// @Success 200 {object} StandardResponse{data=service.Readiness}
+// @Failure 503 {object} StandardResponse{data=service.Readiness}
+// @Router /admin/readiness [get]
+func handleGetReadiness(c *gin.Context) {
+	readiness := service.CheckReadiness(c.Request.Context())
+	if !readiness.Ready {
+		respond(c, StandardResponse{
+			Code:    503,
+			Message: "Not ready",
+			Data:    readiness,
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Ready",
+		Data:    readiness,
+	})
+}
+
+// @Summary Reload config
+// @Description Read the .env and config files again and apply the networks, signer, auth, limits and webhooks sections, as SIGHUP does. An invalid config is rejected and the current one kept. Changes to other sections are reported and take effect on restart
+// @Tags admin
+// @Produce json
+// @Success 200 {object} StandardResponse{data=service.ConfigReload}
+// @Failure 422 {object} StandardResponse
+// @Router /admin/reload [post]
+func handleReload(c *gin.Context) {
+	reload, err := service.ReloadConfig()
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    422,
+			Message: "Config reload rejected",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Config reloaded",
+		Data:    reload,
+	})
+}
+
+func RegisterAdminRoutes(router gin.IRouter) {
+	router.POST("/admin/build", handleBuild)
+	router.GET("/admin/build", handleGetBuild)
+	router.GET("/admin/readiness", handleGetReadiness)
+	router.POST("/admin/reload", handleReload)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+)
+
+var addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// ContractActionsRequest represents the request body for running actions against a deployed contract
+// @ContractActionsRequest
+type ContractActionsRequest struct {
+	// Registered contract type of the target contract, e.g. IAO/staking/token/payment
+	ContractType string `json:"contract_type" binding:"required" example:"payment"`
+	// Network the contract is deployed to, defaults to dbc-mainnet
+	Network string `json:"network" example:"dbc-mainnet"`
+	// Actions executed in order with the deployer signer
+	Actions []service.Action `json:"actions" binding:"required,min=1,dive"`
+}
+
+// @Summary Run contract actions
+// @Description Execute admin actions against a deployed contract with the deployer signer
+// @Tags contracts
+// @Accept json
+// @Produce json
+// @Param address path string true "Contract address"
+// @Param request body ContractActionsRequest true "Actions to execute"
+// @Success 200 {object} StandardResponse
+// @Failure 400 {object} StandardResponse
+// @Failure 422 {object} StandardResponse
+// @Failure 429 {object} StandardResponse
+// @Failure 500 {object} StandardResponse
+// @Failure 503 {object} StandardResponse
+// @Router /contracts/{address}/actions [post]
+func handleContractActions(c *gin.Context) {
+	address := c.Param("address")
+	if !addressPattern.MatchString(address) {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid contract address",
+			Data:    gin.H{"error": "invalid contract address: " + address},
+		})
+		return
+	}
+
+	var req ContractActionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond(c, StandardResponse{
+			Code:    requestStatus(err),
+			Message: "Invalid request parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	spec, err := service.LookupContractType(req.ContractType)
+	if err == nil {
+		err = service.ValidateActions(spec, req.Actions)
+	}
+	network, lookupErr := service.LookupNetwork(req.Network)
+	if err == nil {
+		err = lookupErr
+	}
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    422,
+			Message: "Invalid actions",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	job, ok := runJob(c, &service.Job{
+		Kind:         service.JobActions,
+		ContractType: spec.Name,
+		Network:      network.Name,
+		Contract:     address,
+		Actions:      req.Actions,
+	})
+	if !ok {
+		return
+	}
+	if err := job.Err(); err != nil {
+		respond(c, StandardResponse{
+			Code:    500,
+			Message: "Actions failed",
+			Data:    gin.H{"job_id": job.ID, "actions": job.ActionResults, "error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Actions successful",
+		Data:    gin.H{"job_id": job.ID, "actions": job.ActionResults},
+	})
+}
+
+func RegisterContractActionRoutes(router gin.IRouter) {
+	router.POST("/contracts/:address/actions", handleContractActions)
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContractCallRequest represents the request body for a read-only contract call
+// @ContractCallRequest
+type ContractCallRequest struct {
+	// Registered contract type of the target contract, e.g. IAO/staking/token/payment.
+	// Defaults to the type of the deployment recorded at the address.
+	ContractType string `json:"contract_type" example:"IAO"`
+	// Compiled contract providing the ABI, e.g. XAAIAO.sol:XAAIAO. Overrides the contract of the type.
+	Artifact string `json:"artifact" example:"XAAIAO.sol:XAAIAO"`
+	// View function name, or signature when overloaded
+	Function string `json:"function" binding:"required" example:"isStarted"`
+	// Function arguments: numbers as JSON numbers or decimal or 0x strings, tuples as arrays or objects
+	Args []json.RawMessage `json:"args" swaggertype:"array,object"`
+	// Network the contract is deployed to, defaults to the network of the recorded deployment, then dbc-mainnet
+	Network string `json:"network" example:"dbc-mainnet"`
+	// Block number, or latest (default), pending or earliest
+	Block string `json:"block" example:"latest"`
+}
+
+// @Summary Call a contract
+// @Description Call a view function of a deployed contract with eth_call, using the ABI of the compiled artifacts, and return the decoded results
+// @Tags contracts
+// @Accept json
+// @Produce json
+// @Param address path string true "Contract address"
+// @Param request body ContractCallRequest true "Function to call"
+// @Success 200 {object} StandardResponse{data=service.CallResult}
+// @Failure 400 {object} StandardResponse
+// @Failure 422 {object} StandardResponse
+// @Failure 500 {object} StandardResponse
+// @Failure 503 {object} StandardResponse
+// @Router /contracts/{address}/call [post]
+func handleContractCall(c *gin.Context) {
+	address := c.Param("address")
+	if !addressPattern.MatchString(address) {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid contract address",
+			Data:    gin.H{"error": "invalid contract address: " + address},
+		})
+		return
+	}
+
+	var req ContractCallRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond(c, StandardResponse{
+			Code:    requestStatus(err),
+			Message: "Invalid request parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+	args, err := decodeArgs(req.Args)
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    422,
+			Message: "Invalid request parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	result, err := service.CallContract(c.Request.Context(), service.ContractCall{
+		Address:      address,
+		Network:      req.Network,
+		ContractType: req.ContractType,
+		Artifact:     req.Artifact,
+		Function:     req.Function,
+		Args:         args,
+		Block:        req.Block,
+	})
+	switch {
+	case errors.Is(err, service.ErrBuildNotReady):
+		respond(c, StandardResponse{
+			Code:    503,
+			Message: "Contracts build not ready",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	case errors.Is(err, service.ErrInvalidCall):
+		respond(c, StandardResponse{
+			Code:    422,
+			Message: "Invalid contract call",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	case errors.Is(err, service.ErrCallReverted):
+		respond(c, StandardResponse{
+			Code:    422,
+			Message: "Contract call reverted",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	case err != nil:
+		respond(c, StandardResponse{
+			Code:    500,
+			Message: "Contract call failed",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    result,
+	})
+}
+
+// decodeArgs decodes the function arguments keeping numbers exact
+func decodeArgs(raw []json.RawMessage) ([]interface{}, error) {
+	args := make([]interface{}, len(raw))
+	for i, arg := range raw {
+		decoder := json.NewDecoder(bytes.NewReader(arg))
+		decoder.UseNumber()
+		if err := decoder.Decode(&args[i]); err != nil {
+			return nil, fmt.Errorf("invalid arg %d: %v", i, err)
+		}
+	}
+	return args, nil
+}
+
+func RegisterContractCallRoutes(router gin.IRouter) {
+	router.POST("/contracts/:address/call", handleContractCall)
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListEventsQuery represents the query parameters for listing indexed contract events
+// @ListEventsQuery
+type ListEventsQuery struct {
+	// Event name or signature, e.g. DepositTokenIn
+	Event     string `form:"event"`
+	Network   string `form:"network"`
+	FromBlock uint64 `form:"from_block"`
+	// Last block, inclusive
+	ToBlock  uint64 `form:"to_block"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1"`
+}
+
+// ListEventsResponse is one page of indexed contract events
+type ListEventsResponse struct {
+	Items    []service.IndexedEvent `json:"items"`
+	Total    int                    `json:"total"`
+	Page     int                    `json:"page"`
+	PageSize int                    `json:"page_size"`
+	// Last block indexed for the contract, null before the first poll
+	IndexedBlock *uint64 `json:"indexed_block"`
+}
+
+// @Summary List contract events
+// @Description List the events of a contract deployed by the service, decoded with the ABI of the compiled artifacts and indexed in chain order. Events of blocks replaced by a reorg are dropped and indexed again
+// @Tags contracts
+// @Produce json
+// @Param address path string true "Contract address"
+// @Param event query string false "Event name or signature"
+// @Param network query string false "Network"
+// @Param from_block query int false "First block"
+// @Param to_block query int false "Last block"
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size, at most 100" default(20)
+// @Success 200 {object} StandardResponse{data=ListEventsResponse}
+// @Failure 400 {object} StandardResponse
+// @Router /contracts/{address}/events [get]
+func handleListContractEvents(c *gin.Context) {
+	address := c.Param("address")
+	if !addressPattern.MatchString(address) {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid contract address",
+			Data:    gin.H{"error": "invalid contract address: " + address},
+		})
+		return
+	}
+
+	var query ListEventsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid query parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	filter := service.EventFilter{
+		Contract:  address,
+		Network:   query.Network,
+		Event:     query.Event,
+		FromBlock: query.FromBlock,
+		ToBlock:   query.ToBlock,
+		Page:      query.Page,
+		PageSize:  query.PageSize,
+	}
+	filter.Normalize()
+	events, total := service.ListEvents(filter)
+	response := ListEventsResponse{
+		Items:    events,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}
+	if block, ok := service.IndexedBlock(query.Network, address); ok {
+		response.IndexedBlock = &block
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    response,
+	})
+}
+
+func RegisterContractEventRoutes(router gin.IRouter) {
+	router.GET("/contracts/:address/events", handleListContractEvents)
+}
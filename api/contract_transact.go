@@ -0,0 +1,146 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+	"encoding/json"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContractTransactRequest represents the request body for a transaction sent to a deployed contract
+// @ContractTransactRequest
+type ContractTransactRequest struct {
+	// Registered contract type of the target contract, checked against the recorded deployment
+	ContractType string `json:"contract_type" example:"staking"`
+	// Compiled contract providing the ABI, defaults to the artifact recorded with the deployment
+	Artifact string `json:"artifact" example:"NFTStaking.sol:NFTStaking"`
+	// Function name, or signature when overloaded
+	Function string `json:"function" binding:"required" example:"setRewardToken"`
+	// Function arguments: numbers as JSON numbers or decimal or 0x strings, tuples as arrays or objects
+	Args []json.RawMessage `json:"args" swaggertype:"array,object"`
+	// Network the contract is deployed to, defaults to the network of the recorded deployment
+	Network string `json:"network" example:"dbc-mainnet"`
+	// Signer key from the config, defaults to the deployer
+	Signer string `json:"signer" example:"deployer"`
+}
+
+// @Summary Send a contract transaction
+// @Description Send a transaction calling a function of a contract deployed by the service, using the ABI of the compiled artifacts. The call is simulated with eth_call first, must be within the scope of the signer, and is recorded in the audit log with the contract's actions. The response carries the receipt and the decoded events
+// @Tags contracts
+// @Accept json
+// @Produce json
+// @Param address path string true "Contract address"
+// @Param request body ContractTransactRequest true "Function to call"
+// @Success 200 {object} StandardResponse
+// @Failure 400 {object} StandardResponse
+// @Failure 403 {object} StandardResponse
+// @Failure 422 {object} StandardResponse
+// @Failure 429 {object} StandardResponse
+// @Failure 500 {object} StandardResponse
+// @Failure 503 {object} StandardResponse
+// @Router /contracts/{address}/transact [post]
+func handleContractTransact(c *gin.Context) {
+	address := c.Param("address")
+	if !addressPattern.MatchString(address) {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid contract address",
+			Data:    gin.H{"error": "invalid contract address: " + address},
+		})
+		return
+	}
+
+	var req ContractTransactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respond(c, StandardResponse{
+			Code:    requestStatus(err),
+			Message: "Invalid request parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+	args, err := decodeArgs(req.Args)
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    422,
+			Message: "Invalid request parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	ctx := service.WithCaller(c.Request.Context(), c.GetString(gin.AuthUserKey))
+	job, err := service.PrepareTransaction(ctx, service.ContractCall{
+		Address:      address,
+		Network:      req.Network,
+		ContractType: req.ContractType,
+		Artifact:     req.Artifact,
+		Function:     req.Function,
+		Args:         args,
+	}, req.Signer)
+	switch {
+	case errors.Is(err, service.ErrBuildNotReady):
+		respond(c, StandardResponse{
+			Code:    503,
+			Message: "Contracts build not ready",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	case errors.Is(err, service.ErrSignerScope):
+		respond(c, StandardResponse{
+			Code:    403,
+			Message: "Transaction not allowed",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	case errors.Is(err, service.ErrInvalidCall):
+		respond(c, StandardResponse{
+			Code:    422,
+			Message: "Invalid transaction",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	case err != nil:
+		respond(c, StandardResponse{
+			Code:    500,
+			Message: "Transaction failed",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	job, ok := runJob(c, job)
+	if !ok {
+		return
+	}
+	var result *service.ActionResult
+	if len(job.ActionResults) > 0 {
+		result = &job.ActionResults[0]
+	}
+	if err := job.Err(); err != nil {
+		code, message := 500, "Transaction failed"
+		switch {
+		case errors.Is(err, service.ErrCallReverted):
+			code, message = 422, "Transaction simulation reverted"
+		case errors.Is(err, service.ErrSignerScope):
+			code, message = 403, "Transaction not allowed"
+		}
+		respond(c, StandardResponse{
+			Code:    code,
+			Message: message,
+			Data:    gin.H{"job_id": job.ID, "transaction": result, "error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Transaction successful",
+		Data:    gin.H{"job_id": job.ID, "transaction": result},
+	})
+}
+
+func RegisterContractTransactRoutes(router gin.IRouter) {
+	router.POST("/contracts/:address/transact", handleContractTransact)
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List contract types
+// @Description List the deployable contract types with the networks they can be deployed to, their post-deploy actions and the build and toolchain versions deployments use
+// @Tags deployment
+// @Produce json
+// @Success 200 {object} StandardResponse{data=[]service.ContractTypeInfo}
+// @Router /contract-types [get]
+func handleListContractTypes(c *gin.Context) {
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    service.DescribeContractTypes(),
+	})
+}
+
+// @Summary Get contract type schema
+// @Description Get the JSON Schema of the body of POST /deploy/{type}, generated from the type's request struct and its binding and example tags, or from the schema declared with the type
+// @Tags deployment
+// @Produce json
+// @Param type path string true "Contract type"
+// @Success 200 {object} StandardResponse{data=service.Schema}
+// @Failure 404 {object} StandardResponse
+// @Failure 500 {object} StandardResponse
+// @Router /contract-types/{type}/schema [get]
+func handleGetContractTypeSchema(c *gin.Context) {
+	spec, err := service.LookupContractType(c.Param("type"))
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    404,
+			Message: "Unknown contract type",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	schema, err := deploySchema(spec)
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    500,
+			Message: "Invalid contract type schema",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    schema,
+	})
+}
+
+// deploySchema returns the schema of a deploy request body for spec: the
+// typed request of a built-in type, or the shared request fields, merged
+// with the schema declared with the type
+func deploySchema(spec *service.ContractTypeSpec) (*service.Schema, error) {
+	var schema *service.Schema
+	if newRequest, ok := deployRequests[strings.ToLower(spec.Name)]; ok {
+		schema = service.SchemaOf(newRequest())
+	} else {
+		schema = service.SchemaOf(deployEnvelope{})
+	}
+	schema.Description = "Request body of POST /deploy/" + spec.Name
+
+	if len(spec.Schema) > 0 {
+		var declared service.Schema
+		if err := json.Unmarshal(spec.Schema, &declared); err != nil {
+			return nil, err
+		}
+		for name, prop := range declared.Properties {
+			schema.Properties[name] = prop
+		}
+		for _, name := range declared.Required {
+			if !slices.Contains(schema.Required, name) {
+				schema.Required = append(schema.Required, name)
+			}
+		}
+		schema.AdditionalProperties = declared.AdditionalProperties
+	}
+
+	if network, ok := schema.Properties["network"]; ok {
+		for _, n := range service.Networks() {
+			network.Enum = append(network.Enum, n.Name)
+		}
+	}
+	return schema, nil
+}
+
+func RegisterContractTypeRoutes(router gin.IRouter) {
+	router.GET("/contract-types", handleListContractTypes)
+	router.GET("/contract-types/:type/schema", handleGetContractTypeSchema)
+}
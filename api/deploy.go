@@ -0,0 +1,222 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// deployRequests maps the built-in contract types to their typed request,
+// whose binding tags validate the request body
+var deployRequests = map[string]func() interface{}{
+	"iao":     func() interface{} { return &DeployIAORequest{} },
+	"staking": func() interface{} { return &DeployStakingRequest{} },
+	"token":   func() interface{} { return &DeployTokenRequest{} },
+	"payment": func() interface{} { return &DeployPaymentRequest{} },
+}
+
+// @Summary Deploy contract of any type
+// @Description Deploy a contract of a registered type. The body is validated against the type's request struct or JSON schema
+// @Tags deployment
+// @Accept json
+// @Produce json
+// @Param type path string true "Contract type"
+// @Param request body object true "Deployment parameters"
+// @Success 200 {object} StandardResponse
+// @Failure 400 {object} StandardResponse
+// @Failure 404 {object} StandardResponse
+// @Failure 422 {object} StandardResponse
+// @Failure 429 {object} StandardResponse
+// @Failure 500 {object} StandardResponse
+// @Failure 503 {object} StandardResponse
+// @Router /deploy/{type} [post]
+func handleDeploy(c *gin.Context) {
+	deploy(c, c.Param("type"))
+}
+
+func deploy(c *gin.Context, typeName string) {
+	spec, err := service.LookupContractType(typeName)
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    unknownTypeStatus(c),
+			Message: "Unknown contract type",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	body, err := c.GetRawData()
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid request parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	req, message, err := validateDeploy(c.Request.Context(), spec, body)
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    requestStatus(err),
+			Message: message,
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+	network := req.network
+	trace.SpanFromContext(c.Request.Context()).SetAttributes(
+		attribute.String("deploy.contract_type", spec.Name),
+		attribute.String("deploy.network", network.Name),
+	)
+
+	job, ok := runJob(c, &service.Job{
+		Kind:         service.JobDeploy,
+		ContractType: spec.Name,
+		Network:      network.Name,
+		Params:       req.envVars,
+		Actions:      req.actions,
+	})
+	if !ok {
+		return
+	}
+
+	err = job.Err()
+	if errors.Is(err, service.ErrVerifyFailed) {
+		respond(c, StandardResponse{
+			Code:    500,
+			Message: "Deployment successful, verification failed",
+			Data:    gin.H{"job_id": job.ID, "proxy_address": job.ProxyAddress, "error": err.Error()},
+		})
+		return
+	}
+	if errors.Is(err, service.ErrActionsFailed) {
+		respond(c, StandardResponse{
+			Code:    500,
+			Message: "Post-deploy actions failed",
+			Data:    gin.H{"job_id": job.ID, "proxy_address": job.ProxyAddress, "actions": job.ActionResults, "error": err.Error()},
+		})
+		return
+	}
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    500,
+			Message: "Deployment failed",
+			Data:    gin.H{"job_id": job.ID, "error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Deployment successful",
+		Data:    gin.H{"job_id": job.ID, "proxy_address": job.ProxyAddress, "actions": job.ActionResults},
+	})
+}
+
+// validatedDeploy is a deploy request that passed validation
+type validatedDeploy struct {
+	network *service.Network
+	envVars map[string]string
+	actions []service.Action
+}
+
+// validateDeploy validates body against spec, returning the response message
+// when the request is rejected
+func validateDeploy(ctx context.Context, spec *service.ContractTypeSpec, body []byte) (req *validatedDeploy, message string, err error) {
+	_, span := service.StartSpan(ctx, service.SpanValidate)
+	defer func() {
+		service.EndSpan(span, err)
+	}()
+
+	params, envelope, err := bindDeployRequest(spec, body)
+	if err != nil {
+		return nil, "Invalid request parameters", err
+	}
+	network, err := service.LookupNetwork(envelope.Network)
+	if err != nil {
+		return nil, "Unknown network", err
+	}
+	if err := service.ValidateActions(spec, envelope.Actions); err != nil {
+		return nil, "Invalid post-deploy actions", err
+	}
+	envVars, err := spec.EnvVars(params)
+	if err != nil {
+		return nil, "Invalid request parameters", err
+	}
+	if err := service.ValidateActionOwner(ctx, spec, network, envVars, envelope.Actions); errors.Is(err, service.ErrActionOwner) {
+		return nil, "Invalid post-deploy actions", err
+	} else if err != nil {
+		return nil, "Failed to validate post-deploy actions", internalError{err}
+	}
+	return &validatedDeploy{network: network, envVars: envVars, actions: envelope.Actions}, "", nil
+}
+
+// NewDeployJob validates body as a deploy request for the contract type
+// typeName and returns the job deploying it, for the CLI local mode
+func NewDeployJob(ctx context.Context, typeName string, body []byte) (*service.Job, error) {
+	spec, err := service.LookupContractType(typeName)
+	if err != nil {
+		return nil, err
+	}
+	req, message, err := validateDeploy(ctx, spec, body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", message, err)
+	}
+	return &service.Job{
+		Kind:         service.JobDeploy,
+		ContractType: spec.Name,
+		Network:      req.network.Name,
+		Params:       req.envVars,
+		Actions:      req.actions,
+	}, nil
+}
+
+// deployEnvelope holds the request fields shared by every contract type
+type deployEnvelope struct {
+	Network string           `json:"network"`
+	Actions []service.Action `json:"actions" binding:"omitempty,dive"`
+}
+
+// bindDeployRequest validates body against the typed request of a built-in
+// type and the type's JSON schema, and returns the deploy params and the
+// shared request fields
+func bindDeployRequest(spec *service.ContractTypeSpec, body []byte) (map[string]interface{}, *deployEnvelope, error) {
+	if newRequest, ok := deployRequests[strings.ToLower(spec.Name)]; ok {
+		if err := binding.JSON.BindBody(body, newRequest()); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var envelope deployEnvelope
+	if err := binding.JSON.BindBody(body, &envelope); err != nil {
+		return nil, nil, err
+	}
+
+	var params map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&params); err != nil {
+		return nil, nil, err
+	}
+	delete(params, "network")
+	delete(params, "actions")
+
+	if err := spec.ValidateParams(params); err != nil {
+		return nil, nil, err
+	}
+	return params, &envelope, nil
+}
+
+func RegisterDeployRoutes(router gin.IRouter) {
+	router.POST("/deploy/:type", handleDeploy)
+}
@@ -4,7 +4,6 @@ import (
 	"auto-deploy-contract/api/middleware"
 	"auto-deploy-contract/service"
 
-	"fmt"
 	"github.com/gin-gonic/gin"
 )
 
@@ -34,19 +33,10 @@ type DeployPaymentRequest struct {
 	VIPPriceFixedCount int `json:"vip_price_fixed_count" binding:"required" example:"100000"`
 	// Monthly price for VIP requests
 	VIPPriceMonthly int `json:"vip_price_monthly" binding:"required" example:"100000"`
-}
-
-func (req *DeployPaymentRequest) ToMap() map[string]string {
-	return map[string]string{
-		"OWNER":                                  req.Owner,
-		"PAYMENT_TOKEN":                          req.PaymentToken,
-		"FREE_REQUEST_COUNT":                     fmt.Sprintf("%d", req.FreeRequestCount),
-		"ADDRESS_FREE_REQUEST_COUNT":             fmt.Sprintf("%d", req.AddressFreeRequestCount),
-		"MIN_USD_BALANCE_FOR_USING_FREE_REQUEST": fmt.Sprintf("%d", req.MinUsdBalanceForUsingFreeRequest),
-		"VIP_MONTHLY_QUOTAS":                     fmt.Sprintf("%d", req.VIPMonthlyQuotas),
-		"VIP_PRICE_FIXED_COUNT":                  fmt.Sprintf("%d", req.VIPPriceFixedCount),
-		"VIP_PRICE_MONTHLY":                      fmt.Sprintf("%d", req.VIPPriceMonthly),
-	}
+	// Network to deploy to, defaults to dbc-mainnet
+	Network string `json:"network" example:"dbc-mainnet"`
+	// Optional post-deploy actions executed with the deployer signer
+	Actions []service.Action `json:"actions" binding:"omitempty,dive"`
 }
 
 // DeployPaymentResponse represents the response for deployment
@@ -64,50 +54,15 @@ type DeployPaymentResponse struct {
 // @Param request body DeployPaymentRequest true "Deployment parameters"
 // @Success 200 {object} StandardResponse
 // @Failure 400 {object} StandardResponse
+// @Failure 422 {object} StandardResponse
+// @Failure 429 {object} StandardResponse
 // @Failure 500 {object} StandardResponse
+// @Failure 503 {object} StandardResponse
 // @Router /deploy/payment [post]
 func handleDeployPayment(c *gin.Context) {
-	if Pending {
-		c.JSON(200, StandardResponse{
-			Code:    400,
-			Message: "Pending",
-			Data:    gin.H{"error": "Pending"},
-		})
-		return
-	}
-
-	Pending = true
-	defer func() {
-		Pending = false
-	}()
-
-	var req DeployPaymentRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(200, StandardResponse{
-			Code:    400,
-			Message: "Invalid request parameters",
-			Data:    gin.H{"error": err.Error()},
-		})
-		return
-	}
-
-	proxyAddr, err := service.DeployContract(service.ContractPath, service.ContractEnvPath, req.ToMap(), service.PAYMENT)
-	if err != nil {
-		c.JSON(200, StandardResponse{
-			Code:    500,
-			Message: "Deployment failed",
-			Data:    gin.H{"error": err.Error()},
-		})
-		return
-	}
-
-	c.JSON(200, StandardResponse{
-		Code:    200,
-		Message: "Deployment successful",
-		Data:    gin.H{"proxy_address": proxyAddr},
-	})
+	deploy(c, "payment")
 }
 
-func RegisterDeployPaymentRoutes(router *gin.Engine) {
+func RegisterDeployPaymentRoutes(router gin.IRouter) {
 	router.POST("/deploy/payment", middleware.BasicAuth(), handleDeployPayment)
 }
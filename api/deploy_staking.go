@@ -16,21 +16,13 @@ import (
 // DeployStakingRequest represents the request body for deployment
 // @DeployStakingRequest
 type DeployStakingRequest struct {
-	ProjectName         string `json:"project_name" binding:"required,nowhitespace" example:"Project"`
-	RewardAmountPerYear string `json:"reward_amount_per_year" binding:"required,nowhitespace" example:"2000000000000000000000000000"`
-	Owner               string `json:"owner" binding:"required,nowhitespace" example:"0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"`
-	RewardToken         string `json:"reward_token" binding:"required,nowhitespace" example:"0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"`
-	NFT                 string `json:"nft"  binding:"required,nowhitespace" example:"0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"`
-}
-
-func (req *DeployStakingRequest) ToMap() map[string]string {
-	return map[string]string{
-		"OWNER":                  req.Owner,
-		"PROJECT_NAME":           req.ProjectName,
-		"REWARD_AMOUNT_PER_YEAR": req.RewardAmountPerYear,
-		"REWARD_TOKEN_CONTRACT":  req.RewardToken,
-		"NFT_CONTRACT":           req.NFT,
-	}
+	ProjectName         string           `json:"project_name" binding:"required,nowhitespace" example:"Project"`
+	RewardAmountPerYear string           `json:"reward_amount_per_year" binding:"required,nowhitespace" example:"2000000000000000000000000000"`
+	Owner               string           `json:"owner" binding:"required,nowhitespace" example:"0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"`
+	RewardToken         string           `json:"reward_token" binding:"required,nowhitespace" example:"0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"`
+	NFT                 string           `json:"nft"  binding:"required,nowhitespace" example:"0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"`
+	Network             string           `json:"network" example:"dbc-mainnet"`
+	Actions             []service.Action `json:"actions" binding:"omitempty,dive"`
 }
 
 // DeployStakingResponse represents the response for deployment
@@ -48,50 +40,15 @@ type DeployStakingResponse struct {
 // @Param request body DeployStakingRequest true "Deployment parameters"
 // @Success 200 {object} StandardResponse
 // @Failure 400 {object} StandardResponse
+// @Failure 422 {object} StandardResponse
+// @Failure 429 {object} StandardResponse
 // @Failure 500 {object} StandardResponse
+// @Failure 503 {object} StandardResponse
 // @Router /deploy/staking [post]
 func handleDeployStaking(c *gin.Context) {
-	if Pending {
-		c.JSON(200, StandardResponse{
-			Code:    400,
-			Message: "Pending",
-			Data:    gin.H{"error": "Pending"},
-		})
-		return
-	}
-
-	Pending = true
-	defer func() {
-		Pending = false
-	}()
-
-	var req DeployStakingRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(200, StandardResponse{
-			Code:    400,
-			Message: "Invalid request parameters",
-			Data:    gin.H{"error": err.Error()},
-		})
-		return
-	}
-
-	proxyAddr, err := service.DeployContract(service.ContractPath, service.ContractEnvPath, req.ToMap(), service.STAKING)
-	if err != nil {
-		c.JSON(200, StandardResponse{
-			Code:    500,
-			Message: "Deployment failed",
-			Data:    gin.H{"error": err.Error()},
-		})
-		return
-	}
-
-	c.JSON(200, StandardResponse{
-		Code:    200,
-		Message: "Deployment successful",
-		Data:    gin.H{"proxy_address": proxyAddr},
-	})
+	deploy(c, "staking")
 }
 
-func RegisterDeployStakingRoutes(router *gin.Engine) {
+func RegisterDeployStakingRoutes(router gin.IRouter) {
 	router.POST("/deploy/staking", handleDeployStaking)
 }
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const tokenRequest = `{
+	"owner": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D",
+	"token_name": "TokenName",
+	"token_symbol": "TN",
+	"token_init_supply": "2000000000000000000000000000",
+	"token_supply_fixed_years": 8,
+	"token_amount_can_mint_per_year": "6000000000000000000000000000",
+	"iao_contract_address": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D",
+	"amount_to_iao": "100000000000000000000000000",
+	"actions": [{"function": "setStakingContract", "args": ["0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"]}]
+}`
+
+// TestDeploy_ActionOwner checks actions for another owner than the deployer
+// are rejected as invalid, while failing to derive the deployer is a server
+// error
+func TestDeploy_ActionOwner(t *testing.T) {
+	router := setupRouter(t, false)
+	t.Cleanup(func() { service.SetExecutor(service.ExecExecutor{}) })
+
+	setKey := func(key string) {
+		cfg := service.CurrentConfig()
+		cfg.Signer.PrivateKey = key
+		service.ApplyConfig(cfg)
+	}
+
+	setKey("0xfailing")
+	service.SetExecutor(&service.FakeExecutor{Responses: []service.FakeResponse{
+		{Name: "cast", Args: []string{"wallet", "address"}, Err: errors.New("exit status 1")},
+	}})
+	status, resp := serve(t, router, "/api/v1/deploy/token", tokenRequest)
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "Failed to validate post-deploy actions", resp.Message)
+
+	setKey("0xdeployer")
+	service.SetExecutor(&service.FakeExecutor{Responses: []service.FakeResponse{
+		{Name: "cast", Args: []string{"wallet", "address"}, Output: []byte("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266\n")},
+	}})
+	status, resp = serve(t, router, "/api/v1/deploy/token", tokenRequest)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.Equal(t, "Invalid post-deploy actions", resp.Message)
+}
@@ -0,0 +1,287 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListDeploymentsQuery represents the query parameters for searching deployments
+// @ListDeploymentsQuery
+type ListDeploymentsQuery struct {
+	ContractType string `form:"contract_type"`
+	Network      string `form:"network"`
+	Owner        string `form:"owner"`
+	Caller       string `form:"caller"`
+	Status       string `form:"status" binding:"omitempty,oneof=success failed"`
+	ProxyAddress string `form:"proxy_address"`
+	ProjectName  string `form:"project_name"`
+	// Start of the creation time range, RFC3339 or YYYY-MM-DD, inclusive
+	From string `form:"from"`
+	// End of the creation time range, RFC3339 or YYYY-MM-DD, exclusive
+	To       string `form:"to"`
+	Sort     string `form:"sort"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1"`
+}
+
+// ListDeploymentsResponse is one page of deployment search results
+type ListDeploymentsResponse struct {
+	Items    []*service.Deployment `json:"items"`
+	Total    int                   `json:"total"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"page_size"`
+}
+
+func (q *ListDeploymentsQuery) filter() (service.DeploymentFilter, error) {
+	filter := service.DeploymentFilter{
+		ContractType: q.ContractType,
+		Network:      q.Network,
+		Owner:        q.Owner,
+		Caller:       q.Caller,
+		Status:       q.Status,
+		ProxyAddress: q.ProxyAddress,
+		ProjectName:  q.ProjectName,
+		Sort:         q.Sort,
+		Page:         q.Page,
+		PageSize:     q.PageSize,
+	}
+	var err error
+	if filter.From, err = parseQueryTime(q.From); err != nil {
+		return filter, fmt.Errorf("invalid from: %v", err)
+	}
+	if filter.To, err = parseQueryTime(q.To); err != nil {
+		return filter, fmt.Errorf("invalid to: %v", err)
+	}
+	return filter, filter.Normalize()
+}
+
+func parseQueryTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// @Summary List deployments
+// @Description Search recorded deployments. String filters match case-insensitively; sort by created_at, finished_at, contract_type, network, status or project_name, prefixed with "-" for descending
+// @Tags deployments
+// @Produce json
+// @Param contract_type query string false "Contract type"
+// @Param network query string false "Network"
+// @Param owner query string false "Owner address"
+// @Param caller query string false "User who requested the deployment"
+// @Param status query string false "Deployment status" Enums(success, failed)
+// @Param proxy_address query string false "Proxy address"
+// @Param project_name query string false "Project name"
+// @Param from query string false "Created at or after, RFC3339 or YYYY-MM-DD"
+// @Param to query string false "Created before, RFC3339 or YYYY-MM-DD"
+// @Param sort query string false "Sort field" default(-created_at)
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size, at most 100" default(20)
+// @Success 200 {object} StandardResponse{data=ListDeploymentsResponse}
+// @Failure 400 {object} StandardResponse
+// @Router /deployments [get]
+func handleListDeployments(c *gin.Context) {
+	var query ListDeploymentsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid query parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+	filter, err := query.filter()
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid query parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	deployments, total, err := service.ListDeployments(filter)
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid query parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data: ListDeploymentsResponse{
+			Items:    deployments,
+			Total:    total,
+			Page:     filter.Page,
+			PageSize: filter.PageSize,
+		},
+	})
+}
+
+// @Summary Get deployment
+// @Description Get a deployment with its parameters, toolchain and the actions run against its proxy
+// @Tags deployments
+// @Produce json
+// @Param id path string true "Deployment ID"
+// @Success 200 {object} StandardResponse
+// @Failure 404 {object} StandardResponse
+// @Router /deployments/{id} [get]
+func handleGetDeployment(c *gin.Context) {
+	deployment, err := service.GetDeployment(c.Param("id"))
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    404,
+			Message: "Deployment not found",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data: gin.H{
+			"deployment": deployment,
+			"actions":    service.DeploymentActions(deployment),
+		},
+	})
+}
+
+// @Summary Export deployment manifest
+// @Description Export the latest successful deployment of each contract type as an address book keyed by network, project and contract type. The devops format mirrors a forge broadcast run-latest.json for foundry-devops DevOpsTools.get_most_recent_deployment and requires a network
+// @Tags deployments
+// @Produce json,application/x-yaml
+// @Param format query string false "Manifest format" Enums(json, yaml, devops) default(json)
+// @Param network query string false "Network"
+// @Param project_name query string false "Project name"
+// @Param contract_type query string false "Contract type"
+// @Success 200 {object} service.Manifest
+// @Failure 400 {object} StandardResponse
+// @Router /deployments/export [get]
+func handleExportDeployments(c *gin.Context) {
+	filter := service.DeploymentFilter{
+		Network:      c.Query("network"),
+		ProjectName:  c.Query("project_name"),
+		ContractType: c.Query("contract_type"),
+	}
+
+	switch format := c.DefaultQuery("format", "json"); format {
+	case "json":
+		c.JSON(200, service.BuildManifest(filter))
+	case "yaml":
+		c.YAML(200, service.BuildManifest(filter))
+	case "devops":
+		run, err := service.BuildDevOpsRun(filter)
+		if err != nil {
+			respond(c, StandardResponse{
+				Code:    400,
+				Message: "Invalid query parameters",
+				Data:    gin.H{"error": err.Error()},
+			})
+			return
+		}
+		c.JSON(200, run)
+	default:
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid query parameters",
+			Data:    gin.H{"error": "unsupported format: " + format},
+		})
+	}
+}
+
+// @Summary Verify deployment
+// @Description Submit the implementation of a deployment to the network's verifier again, e.g. after "Deployment successful, verification failed"
+// @Tags deployments
+// @Produce json
+// @Param id path string true "Deployment ID"
+// @Success 200 {object} StandardResponse
+// @Failure 404 {object} StandardResponse
+// @Failure 409 {object} StandardResponse
+// @Failure 429 {object} StandardResponse
+// @Failure 500 {object} StandardResponse
+// @Failure 503 {object} StandardResponse
+// @Router /deployments/{id}/verify [post]
+func handleVerifyDeployment(c *gin.Context) {
+	runDeploymentJob(c, service.JobVerify, "Verification successful", "Verification failed")
+}
+
+// @Summary Upgrade deployment
+// @Description Deploy the current source of the deployment's contract as a new implementation and upgrade the proxy to it with the upgrade script of its contract type. The signer must own the proxy
+// @Tags deployments
+// @Produce json
+// @Param id path string true "Deployment ID"
+// @Success 200 {object} StandardResponse
+// @Failure 404 {object} StandardResponse
+// @Failure 409 {object} StandardResponse
+// @Failure 429 {object} StandardResponse
+// @Failure 500 {object} StandardResponse
+// @Failure 503 {object} StandardResponse
+// @Router /deployments/{id}/upgrade [post]
+func handleUpgradeDeployment(c *gin.Context) {
+	runDeploymentJob(c, service.JobUpgrade, "Upgrade successful", "Upgrade failed")
+}
+
+// runDeploymentJob runs a job of kind against the deployment in the path
+func runDeploymentJob(c *gin.Context, kind, success, failure string) {
+	deployment, err := service.GetDeployment(c.Param("id"))
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    404,
+			Message: "Deployment not found",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+	if deployment.ProxyAddress == "" {
+		respond(c, StandardResponse{
+			Code:    409,
+			Message: "Deployment has no proxy",
+			Data:    gin.H{"error": "deployment " + deployment.ID + " did not deploy a proxy"},
+		})
+		return
+	}
+
+	job, ok := runJob(c, &service.Job{
+		Kind:         kind,
+		ContractType: deployment.ContractType,
+		Network:      deployment.Network,
+		DeploymentID: deployment.ID,
+	})
+	if !ok {
+		return
+	}
+	if err := job.Err(); err != nil {
+		respond(c, StandardResponse{
+			Code:    500,
+			Message: failure,
+			Data:    gin.H{"job_id": job.ID, "implementation_address": job.ImplementationAddress, "error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: success,
+		Data:    gin.H{"job_id": job.ID, "proxy_address": deployment.ProxyAddress, "implementation_address": job.ImplementationAddress},
+	})
+}
+
+func RegisterDeploymentRoutes(router gin.IRouter) {
+	router.GET("/deployments", handleListDeployments)
+	router.GET("/deployments/export", handleExportDeployments)
+	router.GET("/deployments/:id", handleGetDeployment)
+	router.POST("/deployments/:id/verify", handleVerifyDeployment)
+	router.POST("/deployments/:id/upgrade", handleUpgradeDeployment)
+}
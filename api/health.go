@@ -0,0 +1,56 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Health endpoints answer with real HTTP statuses, as load balancers and
+// deploy_to_server.sh only look at the status code
+
+// @Summary Liveness probe
+// @Description Report that the process is alive. Does not require authentication
+// @Tags system
+// @Produce json
+// @Success 200 {object} StandardResponse
+// @Router /healthz [get]
+func handleHealthz(c *gin.Context) {
+	c.JSON(http.StatusOK, StandardResponse{
+		Code:    200,
+		Message: "OK",
+	})
+}
+
+// @Summary Readiness probe
+// @Description Check the deploy path: forge and make are present, the contracts compile, each network's RPC answers eth_chainId with the expected ID, the deployer key is loaded with a balance above MIN_DEPLOYER_BALANCE and the verifier API is reachable. Does not require authentication, so only the check names and outcomes are returned, the details are served by GET /admin/readiness
+// @Tags system
+// @Produce json
+// @Success 200 {object} StandardResponse{data=service.Readiness}
+// @Failure 503 {object} StandardResponse{data=service.Readiness}
+// @Router /readyz [get]
+func handleReadyz(c *gin.Context) {
+	readiness := service.CheckReadiness(c.Request.Context()).Redacted()
+	if !readiness.Ready {
+		c.JSON(http.StatusServiceUnavailable, StandardResponse{
+			Code:    503,
+			Message: "Not ready",
+			Data:    readiness,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, StandardResponse{
+		Code:    200,
+		Message: "Ready",
+		Data:    readiness,
+	})
+}
+
+// RegisterHealthRoutes registers the probes. Call it before installing
+// BasicAuth so the probes stay unauthenticated.
+func RegisterHealthRoutes(router *gin.Engine) {
+	router.GET("/healthz", handleHealthz)
+	router.GET("/readyz", handleReadyz)
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IAOQuery represents the query parameters of the IAO summaries
+// @IAOQuery
+type IAOQuery struct {
+	// Number of top depositors listed, 10 by default
+	Top int `form:"top" binding:"omitempty,min=1,max=100"`
+}
+
+func (q *IAOQuery) top() int {
+	if q.Top == 0 {
+		return service.DefaultTopDepositors
+	}
+	return q.Top
+}
+
+// @Summary List IAOs
+// @Description Summarize each deployed IAO from its on-chain state: deposit period and remaining time, total deposited and number of depositors, result once ended, reward token funding and the top depositors ranked from the indexed DepositTokenIn events. IAOs whose state could not be read carry the error
+// @Tags iao
+// @Produce json
+// @Param top query int false "Number of top depositors, at most 100" default(10)
+// @Success 200 {object} StandardResponse{data=[]service.IAOSummary}
+// @Failure 400 {object} StandardResponse
+// @Router /iaos [get]
+func handleListIAOs(c *gin.Context) {
+	var query IAOQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid query parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    service.ListIAOs(c.Request.Context(), query.top()),
+	})
+}
+
+// @Summary Get IAO
+// @Description Summarize a deployed IAO from its on-chain state, read at the latest block
+// @Tags iao
+// @Produce json
+// @Param address path string true "IAO contract address"
+// @Param top query int false "Number of top depositors, at most 100" default(10)
+// @Success 200 {object} StandardResponse{data=service.IAOSummary}
+// @Failure 400 {object} StandardResponse
+// @Failure 404 {object} StandardResponse
+// @Failure 500 {object} StandardResponse
+// @Failure 503 {object} StandardResponse
+// @Router /iaos/{address} [get]
+func handleGetIAO(c *gin.Context) {
+	address := c.Param("address")
+	if !addressPattern.MatchString(address) {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid contract address",
+			Data:    gin.H{"error": "invalid contract address: " + address},
+		})
+		return
+	}
+	var query IAOQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		respond(c, StandardResponse{
+			Code:    400,
+			Message: "Invalid query parameters",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	summary, err := service.GetIAO(c.Request.Context(), address, query.top())
+	switch {
+	case errors.Is(err, service.ErrIAONotFound):
+		respond(c, StandardResponse{
+			Code:    404,
+			Message: "IAO not found",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	case errors.Is(err, service.ErrBuildNotReady):
+		respond(c, StandardResponse{
+			Code:    503,
+			Message: "Contracts build not ready",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	case err != nil:
+		respond(c, StandardResponse{
+			Code:    500,
+			Message: "Failed to read IAO",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    summary,
+	})
+}
+
+func RegisterIAORoutes(router gin.IRouter) {
+	router.GET("/iaos", handleListIAOs)
+	router.GET("/iaos/:address", handleGetIAO)
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// runJob queues job on behalf of the authenticated user and waits for it to finish. It writes the error response
+// and returns false when the job is rejected, when the service starts
+// shutting down before the job ran, or when the client goes away; the job
+// keeps running in the background in the latter two cases.
+func runJob(c *gin.Context, job *service.Job) (*service.Job, bool) {
+	ctx := service.WithCaller(c.Request.Context(), c.GetString(gin.AuthUserKey))
+	job, err := service.SubmitJob(ctx, job)
+	if errors.Is(err, service.ErrQueueFull) {
+		respond(c, StandardResponse{
+			Code:    429,
+			Message: "Too many pending jobs",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return nil, false
+	}
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    503,
+			Message: "Service unavailable",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return nil, false
+	}
+
+	if err := service.WaitJob(c.Request.Context(), job); err != nil {
+		respond(c, StandardResponse{
+			Code:    503,
+			Message: "Job queued, not finished",
+			Data:    gin.H{"job_id": job.ID, "error": err.Error()},
+		})
+		return nil, false
+	}
+	return job, true
+}
+
+// @Summary List jobs
+// @Description List deploy and actions jobs, newest first. Jobs interrupted by a crash are reconciled on startup; those whose outcome could not be established have status needs_review
+// @Tags jobs
+// @Produce json
+// @Param status query string false "Job status" Enums(queued, running, succeeded, failed, needs_review)
+// @Success 200 {object} StandardResponse{data=[]service.Job}
+// @Router /jobs [get]
+func handleListJobs(c *gin.Context) {
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    service.ListJobs(c.Query("status")),
+	})
+}
+
+// @Summary Get job
+// @Description Get the status and result of a deploy or actions job. Jobs still queued at shutdown resume after the restart
+// @Tags jobs
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} StandardResponse{data=service.Job}
+// @Failure 404 {object} StandardResponse
+// @Router /jobs/{id} [get]
+func handleGetJob(c *gin.Context) {
+	job, err := service.GetJob(c.Param("id"))
+	if err != nil {
+		respond(c, StandardResponse{
+			Code:    404,
+			Message: "Job not found",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    job,
+	})
+}
+
+// @Summary Get job log
+// @Description Get the log of a job as plain text. With follow=true the response streams new lines until the job finishes
+// @Tags jobs
+// @Produce plain
+// @Param id path string true "Job ID"
+// @Param follow query bool false "Stream until the job finishes"
+// @Success 200 {string} string
+// @Failure 404 {object} StandardResponse
+// @Router /jobs/{id}/logs [get]
+func handleGetJobLogs(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := service.GetJob(id); err != nil {
+		respond(c, StandardResponse{
+			Code:    404,
+			Message: "Job not found",
+			Data:    gin.H{"error": err.Error()},
+		})
+		return
+	}
+
+	c.Header("Content-Type", "text/plain; charset=utf-8")
+	c.Status(200)
+	if err := service.FollowJobLog(c.Request.Context(), id, c.Writer, c.Query("follow") == "true"); err != nil {
+		service.Logger(c.Request.Context()).Error("failed to stream job log", "job_id", id, "error", err)
+	}
+}
+
+func RegisterJobRoutes(router gin.IRouter) {
+	router.GET("/jobs", handleListJobs)
+	router.GET("/jobs/:id", handleGetJob)
+	router.GET("/jobs/:id/logs", handleGetJobLogs)
+}
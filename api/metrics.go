@@ -0,0 +1,22 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// @Summary Prometheus metrics
+// @Description Expose deployment, job, gas, deployer balance, auth and HTTP metrics in the Prometheus text format. Does not require authentication
+// @Tags system
+// @Produce plain
+// @Success 200 {string} string
+// @Router /metrics [get]
+func handleMetrics(c *gin.Context) {
+	promhttp.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// RegisterMetricsRoutes registers the scrape endpoint. Call it before
+// installing BasicAuth so Prometheus can scrape without credentials.
+func RegisterMetricsRoutes(router *gin.Engine) {
+	router.GET("/metrics", handleMetrics)
+}
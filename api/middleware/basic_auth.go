@@ -2,29 +2,19 @@ package middleware
 
 import (
 	"encoding/base64"
-	"os"
 	"strings"
 
-	"github.com/gin-gonic/gin"
-)
+	"auto-deploy-contract/service"
 
-const (
-	Username        = "admin"
-	DefaultPassword = "admin123"
+	"github.com/gin-gonic/gin"
 )
 
-func getAdminPassword() string {
-	if password := os.Getenv("ADMIN_PASSWORD"); password != "" {
-		return password
-	}
-	return DefaultPassword
-}
-
-// BasicAuth 中间件用于验证Basic Auth认证
+// BasicAuth 中间件用于验证Basic Auth认证, 账号取自当前配置, 配置重载后立即生效
 func BasicAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		auth := c.GetHeader("Authorization")
 		if auth == "" {
+			authFailures.WithLabelValues("missing").Inc()
 			c.Header("WWW-Authenticate", "Basic realm=\"Authorization Required\"")
 			c.AbortWithStatusJSON(401, gin.H{
 				"code":    401,
@@ -35,6 +25,7 @@ func BasicAuth() gin.HandlerFunc {
 		}
 
 		if !strings.HasPrefix(auth, "Basic ") {
+			authFailures.WithLabelValues("format").Inc()
 			c.AbortWithStatusJSON(401, gin.H{
 				"code":    401,
 				"message": "Invalid authorization format",
@@ -45,8 +36,10 @@ func BasicAuth() gin.HandlerFunc {
 
 		payload, _ := base64.StdEncoding.DecodeString(auth[6:])
 		pair := strings.SplitN(string(payload), ":", 2)
+		credentials := service.CurrentConfig().Auth
 
-		if len(pair) != 2 || pair[0] != Username || pair[1] != getAdminPassword() {
+		if len(pair) != 2 || pair[0] != credentials.Username || pair[1] != credentials.Password {
+			authFailures.WithLabelValues("credentials").Inc()
 			c.AbortWithStatusJSON(401, gin.H{
 				"code":    401,
 				"message": "Invalid credentials",
@@ -55,6 +48,7 @@ func BasicAuth() gin.HandlerFunc {
 			return
 		}
 
+		c.Set(gin.AuthUserKey, pair[0])
 		c.Next()
 	}
 }
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"auto-deploy-contract/service"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logger logs every request with the request's logger once it completes.
+// Install it after RequestID.
+func Logger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		level := slog.LevelInfo
+		if c.Writer.Status() >= 500 {
+			level = slog.LevelError
+		}
+		service.Logger(c.Request.Context()).Log(c.Request.Context(), level, "request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", c.Writer.Status(),
+			"duration", time.Since(start).Round(time.Millisecond).String(),
+			"client_ip", c.ClientIP(),
+			"user", c.GetString(gin.AuthUserKey),
+		)
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "adc_http_request_duration_seconds",
+		Help:    "HTTP request latency by route and status.",
+		Buckets: []float64{.01, .05, .1, .5, 1, 5, 30, 60, 300, 900},
+	}, []string{"method", "route", "status"})
+
+	authFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "adc_auth_failures_total",
+		Help: "Rejected Basic Auth attempts by reason.",
+	}, []string{"reason"})
+)
+
+// Metrics records the latency of every request by route. Install it before
+// BasicAuth so rejected requests are counted too.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequestDuration.
+			WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).
+			Observe(time.Since(start).Seconds())
+	}
+}
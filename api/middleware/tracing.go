@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"auto-deploy-contract/service"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing starts a server span per request, continuing the trace of an
+// incoming traceparent header, and tags the request's logger with the trace
+// ID. Install it after RequestID.
+func Tracing() gin.HandlerFunc {
+	tracer := otel.Tracer("auto-deploy-contract/api")
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := tracer.Start(ctx, c.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+			),
+		)
+		defer span.End()
+		if id, ok := c.Get(RequestIDKey); ok {
+			span.SetAttributes(attribute.String("request.id", id.(string)))
+		}
+		if sc := span.SpanContext(); sc.IsValid() {
+			ctx = service.WithLogger(ctx, service.Logger(ctx).With("trace_id", sc.TraceID().String()))
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= 500 {
+			span.SetStatus(codes.Error, "")
+		}
+	}
+}
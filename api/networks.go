@@ -0,0 +1,25 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary List networks
+// @Description List the networks contracts can be deployed to, with the dependency addresses injected on each
+// @Tags system
+// @Produce json
+// @Success 200 {object} StandardResponse
+// @Router /networks [get]
+func handleListNetworks(c *gin.Context) {
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    service.Networks(),
+	})
+}
+
+func RegisterNetworkRoutes(router gin.IRouter) {
+	router.GET("/networks", handleListNetworks)
+}
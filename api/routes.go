@@ -0,0 +1,100 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// legacyResponsesKey marks the requests served on the unversioned routes
+const legacyResponsesKey = "legacy_responses"
+
+// RegisterRoutes registers the authenticated API routes under /api/v1, and
+// on the unversioned paths with the legacy responses when legacy is set
+func RegisterRoutes(router gin.IRouter, legacy bool) {
+	RegisterAPIRoutes(router.Group("/api/v1"))
+	if legacy {
+		RegisterAPIRoutes(router.Group("/", LegacyResponses()))
+	}
+}
+
+// RegisterAPIRoutes registers the authenticated API routes on router
+func RegisterAPIRoutes(router gin.IRouter) {
+	RegisterDeployIAORoutes(router)
+	RegisterDeployStakingRoutes(router)
+	RegisterDeployTokenRoutes(router)
+	RegisterDeployPaymentRoutes(router)
+	RegisterDeployRoutes(router)
+	RegisterContractTypeRoutes(router)
+	RegisterDeploymentRoutes(router)
+	RegisterContractActionRoutes(router)
+	RegisterContractCallRoutes(router)
+	RegisterContractTransactRoutes(router)
+	RegisterContractEventRoutes(router)
+	RegisterIAORoutes(router)
+	RegisterToolchainRoutes(router)
+	RegisterNetworkRoutes(router)
+	RegisterAdminRoutes(router)
+	RegisterJobRoutes(router)
+}
+
+// LegacyResponses keeps the responses of the routes it is installed on as
+// before /api/v1: HTTP 200 with the status in StandardResponse.Code, and
+// 409 and 422 reported as 400. An unknown contract type stays a 400, see
+// unknownTypeStatus.
+func LegacyResponses() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(legacyResponsesKey, true)
+		c.Next()
+	}
+}
+
+// respond writes resp with resp.Code as the HTTP status, or with 200 on the
+// legacy routes
+func respond(c *gin.Context, resp StandardResponse) {
+	if !c.GetBool(legacyResponsesKey) {
+		c.JSON(resp.Code, resp)
+		return
+	}
+	if resp.Code == http.StatusConflict || resp.Code == http.StatusUnprocessableEntity {
+		resp.Code = http.StatusBadRequest
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// unknownTypeStatus returns the status of a deploy of an unregistered
+// contract type: 404, or 400 on the legacy routes as before /api/v1
+func unknownTypeStatus(c *gin.Context) int {
+	if c.GetBool(legacyResponsesKey) {
+		return http.StatusBadRequest
+	}
+	return http.StatusNotFound
+}
+
+// internalError marks a failure of the server while validating a request,
+// as opposed to an invalid request
+type internalError struct {
+	error
+}
+
+func (e internalError) Unwrap() error {
+	return e.error
+}
+
+// requestStatus returns the status of a rejected request body: 400 when it
+// is not JSON, 422 when its content is invalid, and 500 when the server
+// failed to validate it
+func requestStatus(err error) int {
+	var syntaxErr *json.SyntaxError
+	var internalErr internalError
+	switch {
+	case errors.As(err, &internalErr):
+		return http.StatusInternalServerError
+	case errors.As(err, &syntaxErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		return http.StatusBadRequest
+	}
+	return http.StatusUnprocessableEntity
+}
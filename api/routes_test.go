@@ -0,0 +1,122 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore holds a deployment that did not deploy a proxy
+const testStore = `{"deployments": [{
+	"id": "noproxy",
+	"contract_type": "token",
+	"network": "dbc-mainnet",
+	"chain_id": 19880818,
+	"params": {},
+	"status": "failed",
+	"created_at": "2024-05-01T00:00:00Z",
+	"finished_at": "2024-05-01T00:01:00Z"
+}]}`
+
+// setupRouter serves the API routes over the store above, with the legacy
+// routes when legacy is set
+func setupRouter(t *testing.T, legacy bool) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	originalPath := service.StorePath
+	storePath := filepath.Join(t.TempDir(), "store.json")
+	require.NoError(t, os.WriteFile(storePath, []byte(testStore), 0644))
+	cfg := service.DefaultConfig()
+	cfg.Paths.Store = storePath
+	service.ApplyConfig(cfg)
+	require.NoError(t, service.OpenDefaultStore())
+	t.Cleanup(func() {
+		service.StorePath = originalPath
+		service.ApplyConfig(service.DefaultConfig())
+	})
+
+	router := gin.New()
+	RegisterRoutes(router, legacy)
+	return router
+}
+
+// serve sends a POST of body to path and returns the HTTP status and the
+// decoded response
+func serve(t *testing.T, router *gin.Engine, path, body string) (int, StandardResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var resp StandardResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp), w.Body.String())
+	return w.Code, resp
+}
+
+var statusCases = []struct {
+	name   string
+	path   string
+	body   string
+	status int
+	legacy int
+}{
+	{"unknown contract type", "/deploy/unknown", `{}`, http.StatusNotFound, http.StatusBadRequest},
+	{"malformed body", "/deploy/token", `{"owner":`, http.StatusBadRequest, http.StatusBadRequest},
+	{"invalid body", "/deploy/token", `{"owner":"0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"}`, http.StatusUnprocessableEntity, http.StatusBadRequest},
+	{"unknown deployment", "/deployments/missing/upgrade", ``, http.StatusNotFound, http.StatusNotFound},
+	{"deployment without proxy", "/deployments/noproxy/upgrade", ``, http.StatusConflict, http.StatusBadRequest},
+}
+
+// TestRoutes_StatusCodes checks the /api/v1 routes answer with the status of
+// the response as the HTTP status
+func TestRoutes_StatusCodes(t *testing.T) {
+	router := setupRouter(t, false)
+
+	for _, tc := range statusCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, resp := serve(t, router, "/api/v1"+tc.path, tc.body)
+			assert.Equal(t, tc.status, status)
+			assert.Equal(t, tc.status, resp.Code)
+		})
+	}
+}
+
+// TestRoutes_Legacy checks the unversioned routes always answer HTTP 200,
+// with the status as before /api/v1 in the response
+func TestRoutes_Legacy(t *testing.T) {
+	router := setupRouter(t, true)
+
+	for _, tc := range statusCases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, resp := serve(t, router, tc.path, tc.body)
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, tc.legacy, resp.Code)
+
+			// The versioned routes are served alongside
+			status, _ = serve(t, router, "/api/v1"+tc.path, tc.body)
+			assert.Equal(t, tc.status, status)
+		})
+	}
+}
+
+// TestRoutes_LegacyDisabled checks the unversioned routes are only
+// registered with features.legacy_routes
+func TestRoutes_LegacyDisabled(t *testing.T) {
+	router := setupRouter(t, false)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/deploy/unknown", strings.NewReader(`{}`)))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "404 page not found", w.Body.String())
+}
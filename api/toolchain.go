@@ -0,0 +1,25 @@
+package api
+
+import (
+	"auto-deploy-contract/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get toolchain
+// @Description Get the forge, cast, make, node and solc binaries and versions used for deployments
+// @Tags system
+// @Produce json
+// @Success 200 {object} StandardResponse
+// @Router /toolchain [get]
+func handleGetToolchain(c *gin.Context) {
+	respond(c, StandardResponse{
+		Code:    200,
+		Message: "Success",
+		Data:    service.CurrentToolchain(),
+	})
+}
+
+func RegisterToolchainRoutes(router gin.IRouter) {
+	router.GET("/toolchain", handleGetToolchain)
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"io"
+	"net"
+	"os"
+
+	"auto-deploy-contract/service"
+)
+
+// backend runs the commands, against a server or in-process
+type backend interface {
+	Deploy(ctx context.Context, contractType string, body []byte) (interface{}, error)
+	// RunDeploymentJob runs a verify or upgrade job against a deployment
+	RunDeploymentJob(ctx context.Context, kind, deploymentID string) (interface{}, error)
+	ListJobs(ctx context.Context, status string) (interface{}, error)
+	GetJob(ctx context.Context, id string) (interface{}, error)
+	JobLogs(ctx context.Context, id string, follow bool, out io.Writer) error
+	ListDeployments(ctx context.Context, filter service.DeploymentFilter) (interface{}, error)
+	GetDeployment(ctx context.Context, id string) (interface{}, error)
+	ExportDeployments(ctx context.Context, format string, filter service.DeploymentFilter, out io.Writer) error
+	Close()
+}
+
+// clientFlags select and configure the backend
+type clientFlags struct {
+	config   string
+	server   string
+	user     string
+	password string
+	local    bool
+	env      string
+}
+
+func addClientFlags(fs *flag.FlagSet) *clientFlags {
+	opts := &clientFlags{}
+	fs.StringVar(&opts.config, "config", "", "config file (default $CONFIG_PATH, then ./config.yaml)")
+	fs.StringVar(&opts.server, "server", os.Getenv("ADC_SERVER"), "server URL (default the listen address of the config)")
+	fs.StringVar(&opts.user, "user", os.Getenv("ADC_USER"), "basic auth user (default the auth username of the config)")
+	fs.StringVar(&opts.password, "password", "", "basic auth password (default $ADC_PASSWORD, then the auth password of the config)")
+	fs.BoolVar(&opts.local, "local", false, "run the service in-process instead of calling a server")
+	fs.StringVar(&opts.env, "env", "", "environment of the in-process service (dev/prod)")
+	return opts
+}
+
+// backend returns the backend for command. Values the flags leave unset
+// come from the config. In local mode, commands that only read records open
+// the store without starting the service.
+func (o *clientFlags) backend(command string) (backend, error) {
+	cfg, err := loadConfig(o.config, o.env)
+	if err != nil {
+		return nil, err
+	}
+	if !o.local {
+		server, user, password := o.server, o.user, o.password
+		if server == "" {
+			server = serverURL(cfg.Listen)
+		}
+		if user == "" {
+			user = cfg.Auth.Username
+		}
+		if password == "" {
+			password = envOr("ADC_PASSWORD", cfg.Auth.Password)
+		}
+		return newRemote(server, user, password), nil
+	}
+	readOnly := command != "deploy" && command != "verify" && command != "upgrade"
+	return newLocal(cfg, readOnly)
+}
+
+// serverURL returns the URL of a server listening on listen, reached over
+// loopback when it listens on every interface
+func serverURL(listen string) string {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "http://" + listen
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,353 @@
+// Package cli implements the subcommands of the auto-deploy-contract binary.
+// Every command but serve talks to a running server, or with --local runs the
+// service layer in-process.
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"auto-deploy-contract/service"
+
+	"gopkg.in/yaml.v3"
+)
+
+const usage = `Usage: auto-deploy-contract <command> [flags]
+
+Commands:
+  serve [--config <file>]            run the HTTP server (the default without a command)
+  config print [--config <file>]     print the effective config, secrets redacted
+  deploy <type> --params <file>      deploy a contract, params as the JSON body of POST /deploy/{type}
+  jobs list [--status <status>]      list jobs
+  jobs get <id>                      show a job
+  jobs logs <id> [--follow]          print the log of a job
+  deployments list [filters]         search deployments
+  deployments get <id>               show a deployment and its actions
+  deployments export [--format f]    export the address book (json, yaml or devops)
+  verify <deployment-id>             verify the implementation of a deployment again
+  upgrade <deployment-id>            deploy a new implementation and upgrade the proxy
+
+Client flags, accepted by every command but serve and config:
+  --config     config file (CONFIG_PATH, default ./config.yaml when present)
+  --server     server URL (ADC_SERVER, default the listen address of the config)
+  --user       basic auth user (ADC_USER, default the auth username of the config)
+  --password   basic auth password (ADC_PASSWORD, default the auth password of the config)
+  --local      run in-process instead; refused while a server holds the data dir
+  --env        environment of the in-process service (dev/prod)
+
+Run "auto-deploy-contract <command> -h" for the flags of a command.
+`
+
+// usageError is a command line mistake, reported with exit code 2
+type usageError struct{ msg string }
+
+func (e usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...interface{}) error {
+	return usageError{fmt.Sprintf(format, args...)}
+}
+
+// Run executes the command in args and returns the exit code. serve starts
+// the HTTP server with cfg.
+func Run(args []string, serve func(cfg *service.Config) error) int {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	err := run(ctx, args, serve, os.Stdout)
+	var usageErr usageError
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, flag.ErrHelp):
+		return 0
+	case errors.As(err, &usageErr):
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	default:
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+}
+
+func run(ctx context.Context, args []string, serve func(cfg *service.Config) error, out io.Writer) error {
+	// Without a command, e.g. "auto-deploy-contract --env prod", serve
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runServe(args, serve)
+	}
+
+	command, args := args[0], args[1:]
+	switch command {
+	case "serve":
+		return runServe(args, serve)
+	case "help":
+		fmt.Fprint(out, usage)
+		return nil
+	case "config":
+		if len(args) == 0 || args[0] != "print" {
+			return usagef("config: expected print")
+		}
+		return runConfigPrint(args[1:], out)
+	case "deploy", "verify", "upgrade":
+	case "jobs", "deployments":
+		if len(args) == 0 {
+			return usagef("%s: missing subcommand", command)
+		}
+		command, args = command+" "+args[0], args[1:]
+	default:
+		return usagef("unknown command: %s", command)
+	}
+
+	handler, ok := commands[command]
+	if !ok {
+		return usagef("unknown command: %s", command)
+	}
+	fs := flag.NewFlagSet(command, flag.ContinueOnError)
+	opts := addClientFlags(fs)
+	parse := handler(fs)
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	b, err := opts.backend(command)
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+	return parse(ctx, b, positional, out)
+}
+
+func runServe(args []string, serve func(cfg *service.Config) error) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	path := fs.String("config", "", "config file")
+	env := fs.String("env", "", "(dev/prod), overrides the config")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := loadConfig(*path, *env)
+	if err != nil {
+		return err
+	}
+	return serve(cfg)
+}
+
+// runConfigPrint prints the effective config as YAML, then fails when it
+// is invalid
+func runConfigPrint(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	path := fs.String("config", "", "config file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cfg, err := service.LoadConfig(*path)
+	if err != nil {
+		return err
+	}
+	encoder := yaml.NewEncoder(out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(cfg.Redacted()); err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config:\n%v", err)
+	}
+	return nil
+}
+
+// loadConfig loads the config at path and validates it, env overriding its
+// environment when set
+func loadConfig(path, env string) (*service.Config, error) {
+	cfg, err := service.LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	if env != "" {
+		cfg.Env = env
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%v", err)
+	}
+	return cfg, nil
+}
+
+// parseFlags parses args allowing flags after the positional arguments, as
+// in "deploy token --params token.json", and returns the positional ones
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// command registers its flags on the flag set and returns the func running it
+type command func(fs *flag.FlagSet) func(ctx context.Context, b backend, args []string, out io.Writer) error
+
+var commands = map[string]command{
+	"deploy":             deployCommand,
+	"verify":             deploymentJobCommand(service.JobVerify),
+	"upgrade":            deploymentJobCommand(service.JobUpgrade),
+	"jobs list":          listJobsCommand,
+	"jobs get":           getJobCommand,
+	"jobs logs":          jobLogsCommand,
+	"deployments list":   listDeploymentsCommand,
+	"deployments get":    getDeploymentCommand,
+	"deployments export": exportDeploymentsCommand,
+}
+
+func deployCommand(fs *flag.FlagSet) func(context.Context, backend, []string, io.Writer) error {
+	params := fs.String("params", "", "JSON file with the deploy parameters, - for stdin")
+	network := fs.String("network", "", "network, overrides the network in the params")
+	return func(ctx context.Context, b backend, args []string, out io.Writer) error {
+		if len(args) != 1 {
+			return usagef("deploy: expected a contract type")
+		}
+		if *params == "" {
+			return usagef("deploy: --params is required")
+		}
+		body, err := readParams(*params, *network)
+		if err != nil {
+			return err
+		}
+		result, err := b.Deploy(ctx, args[0], body)
+		return printResult(out, result, err)
+	}
+}
+
+func deploymentJobCommand(kind string) command {
+	return func(fs *flag.FlagSet) func(context.Context, backend, []string, io.Writer) error {
+		return func(ctx context.Context, b backend, args []string, out io.Writer) error {
+			if len(args) != 1 {
+				return usagef("%s: expected a deployment ID", kind)
+			}
+			result, err := b.RunDeploymentJob(ctx, kind, args[0])
+			return printResult(out, result, err)
+		}
+	}
+}
+
+func listJobsCommand(fs *flag.FlagSet) func(context.Context, backend, []string, io.Writer) error {
+	status := fs.String("status", "", "job status: queued, running, succeeded, failed or needs_review")
+	return func(ctx context.Context, b backend, args []string, out io.Writer) error {
+		result, err := b.ListJobs(ctx, *status)
+		return printResult(out, result, err)
+	}
+}
+
+func getJobCommand(fs *flag.FlagSet) func(context.Context, backend, []string, io.Writer) error {
+	return func(ctx context.Context, b backend, args []string, out io.Writer) error {
+		if len(args) != 1 {
+			return usagef("jobs get: expected a job ID")
+		}
+		result, err := b.GetJob(ctx, args[0])
+		return printResult(out, result, err)
+	}
+}
+
+func jobLogsCommand(fs *flag.FlagSet) func(context.Context, backend, []string, io.Writer) error {
+	follow := fs.Bool("follow", false, "stream new lines until the job finishes")
+	return func(ctx context.Context, b backend, args []string, out io.Writer) error {
+		if len(args) != 1 {
+			return usagef("jobs logs: expected a job ID")
+		}
+		return b.JobLogs(ctx, args[0], *follow, out)
+	}
+}
+
+func listDeploymentsCommand(fs *flag.FlagSet) func(context.Context, backend, []string, io.Writer) error {
+	var filter service.DeploymentFilter
+	fs.StringVar(&filter.ContractType, "type", "", "contract type")
+	fs.StringVar(&filter.Network, "network", "", "network")
+	fs.StringVar(&filter.Owner, "owner", "", "owner address")
+	fs.StringVar(&filter.Caller, "caller", "", "user who requested the deployment")
+	fs.StringVar(&filter.Status, "status", "", "deployment status: success or failed")
+	fs.StringVar(&filter.ProxyAddress, "proxy", "", "proxy address")
+	fs.StringVar(&filter.ProjectName, "project", "", "project name")
+	fs.StringVar(&filter.Sort, "sort", "", "sort field, prefixed with - for descending (default -created_at)")
+	fs.IntVar(&filter.Page, "page", 1, "page number")
+	fs.IntVar(&filter.PageSize, "page-size", service.DefaultPageSize, "page size")
+	return func(ctx context.Context, b backend, args []string, out io.Writer) error {
+		result, err := b.ListDeployments(ctx, filter)
+		return printResult(out, result, err)
+	}
+}
+
+func getDeploymentCommand(fs *flag.FlagSet) func(context.Context, backend, []string, io.Writer) error {
+	return func(ctx context.Context, b backend, args []string, out io.Writer) error {
+		if len(args) != 1 {
+			return usagef("deployments get: expected a deployment ID")
+		}
+		result, err := b.GetDeployment(ctx, args[0])
+		return printResult(out, result, err)
+	}
+}
+
+func exportDeploymentsCommand(fs *flag.FlagSet) func(context.Context, backend, []string, io.Writer) error {
+	format := fs.String("format", "json", "json, yaml or devops")
+	output := fs.String("o", "", "write to file instead of stdout")
+	var filter service.DeploymentFilter
+	fs.StringVar(&filter.Network, "network", "", "network, required for devops")
+	fs.StringVar(&filter.ProjectName, "project", "", "project name")
+	fs.StringVar(&filter.ContractType, "type", "", "contract type")
+	return func(ctx context.Context, b backend, args []string, out io.Writer) error {
+		if *output != "" {
+			file, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			out = file
+		}
+		return b.ExportDeployments(ctx, *format, filter, out)
+	}
+}
+
+// readParams reads the deploy body from path, setting network when given
+func readParams(path, network string) ([]byte, error) {
+	var body []byte
+	var err error
+	if path == "-" {
+		body, err = io.ReadAll(os.Stdin)
+	} else {
+		body, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read params: %v", err)
+	}
+	if network == "" {
+		return body, nil
+	}
+	var params map[string]json.RawMessage
+	if err := json.Unmarshal(body, &params); err != nil {
+		return nil, fmt.Errorf("invalid params: %v", err)
+	}
+	params["network"], _ = json.Marshal(network)
+	return json.Marshal(params)
+}
+
+// printResult prints result as indented JSON. A failed job still prints its
+// result, which carries the job ID.
+func printResult(out io.Writer, result interface{}, err error) error {
+	if result != nil {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if encodeErr := encoder.Encode(result); encodeErr != nil && err == nil {
+			err = encodeErr
+		}
+	}
+	return err
+}
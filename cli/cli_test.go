@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"auto-deploy-contract/api"
+	"auto-deploy-contract/api/middleware"
+	"auto-deploy-contract/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testStore = `{"deployments": [{
+	"id": "dep",
+	"contract_type": "token",
+	"network": "dbc-mainnet",
+	"chain_id": 19880818,
+	"proxy_address": "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707",
+	"params": {},
+	"status": "success",
+	"created_at": "2024-05-01T00:00:00Z",
+	"finished_at": "2024-05-01T00:01:00Z"
+}]}`
+
+// setupServer serves the deployment and job routes over the store above
+func setupServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	originalPath := service.StorePath
+	storePath := filepath.Join(t.TempDir(), "store.json")
+	require.NoError(t, os.WriteFile(storePath, []byte(testStore), 0644))
+	// Read by the config of the local mode
+	t.Setenv("STORE_PATH", storePath)
+	cfg := service.DefaultConfig()
+	cfg.Auth.Password = "secret"
+	cfg.Paths.Store = storePath
+	service.ApplyConfig(cfg)
+	require.NoError(t, service.OpenDefaultStore())
+	t.Cleanup(func() {
+		service.StorePath = originalPath
+		service.ApplyConfig(service.DefaultConfig())
+	})
+
+	router := gin.New()
+	router.Use(middleware.BasicAuth())
+	api.RegisterAPIRoutes(router.Group("/api/v1"))
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRun_Remote(t *testing.T) {
+	server := setupServer(t)
+	exec := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		err := run(context.Background(), append(args, "--server", server.URL, "--password", "secret"), nil, &out)
+		return out.String(), err
+	}
+
+	out, err := exec("deployments", "list", "--type", "token")
+	require.NoError(t, err)
+	assert.Contains(t, out, `"total": 1`)
+	assert.Contains(t, out, "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707")
+
+	out, err = exec("deployments", "export", "--format", "yaml")
+	require.NoError(t, err)
+	assert.Contains(t, out, "proxy: 0x5FC8d32690cc91D4c39d9d3abcBD16989F875707")
+
+	_, err = exec("jobs", "get", "missing")
+	var apiErr *apiError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 404, apiErr.Code)
+
+	var out2 bytes.Buffer
+	err = run(context.Background(), []string{"jobs", "list", "--server", server.URL, "--password", "wrong"}, nil, &out2)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 401, apiErr.Code)
+}
+
+func TestRun_Local(t *testing.T) {
+	setupServer(t)
+
+	var out bytes.Buffer
+	require.NoError(t, run(context.Background(), []string{"deployments", "get", "dep", "--local"}, nil, &out))
+	assert.Contains(t, out.String(), `"deployment"`)
+	assert.Contains(t, out.String(), "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707")
+}
+
+func TestRun_Usage(t *testing.T) {
+	var env string
+	serve := func(cfg *service.Config) error {
+		env = cfg.Env
+		return nil
+	}
+	require.NoError(t, run(context.Background(), []string{"--env", "prod"}, serve, &bytes.Buffer{}))
+	assert.Equal(t, "prod", env)
+	assert.Error(t, run(context.Background(), []string{"--env", "staging"}, serve, &bytes.Buffer{}))
+
+	var usageErr usageError
+	assert.ErrorAs(t, run(context.Background(), []string{"bogus"}, serve, &bytes.Buffer{}), &usageErr)
+	assert.ErrorAs(t, run(context.Background(), []string{"jobs"}, serve, &bytes.Buffer{}), &usageErr)
+	assert.ErrorAs(t, run(context.Background(), []string{"deploy", "token"}, serve, &bytes.Buffer{}), &usageErr)
+}
+
+func TestParseFlags(t *testing.T) {
+	fs := flag.NewFlagSet("deploy", flag.ContinueOnError)
+	params := fs.String("params", "", "")
+	args, err := parseFlags(fs, []string{"token", "--params", "token.json", "extra"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"token", "extra"}, args)
+	assert.Equal(t, "token.json", *params)
+}
+
+func TestRun_ConfigPrint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("listen: 127.0.0.1:9000\nsigner:\n  private_key: 0x01\n"), 0600))
+	t.Setenv("ADMIN_PASSWORD", "secret")
+
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"config", "print", "--config", path}, nil, &out)
+	assert.ErrorContains(t, err, "signer.private_key")
+	assert.Contains(t, out.String(), "listen: 127.0.0.1:9000")
+	assert.Contains(t, out.String(), "password: '***'")
+	assert.Contains(t, out.String(), "shutdown_timeout: 10m0s")
+	assert.NotContains(t, out.String(), "secret")
+	assert.NotContains(t, out.String(), "0x01")
+
+	assert.Equal(t, "http://127.0.0.1:8070", serverURL("0.0.0.0:8070"))
+	assert.Equal(t, "http://[::1]:8070", serverURL("[::1]:8070"))
+}
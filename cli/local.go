@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"auto-deploy-contract/api"
+	"auto-deploy-contract/service"
+
+	"gopkg.in/yaml.v3"
+)
+
+// local runs the commands against the service layer in-process
+type local struct {
+	// started is set once Init started the job worker
+	started bool
+}
+
+// newLocal opens the store of cfg, and unless readOnly initializes the
+// service with its job worker, as the server does
+func newLocal(cfg *service.Config, readOnly bool) (*local, error) {
+	if err := service.SetupLogging(cfg.Logging); err != nil {
+		return nil, err
+	}
+	if readOnly {
+		service.ApplyConfig(cfg)
+		if err := service.LockDataDir(); err != nil {
+			return nil, localError(err)
+		}
+		return &local{}, service.OpenDefaultStore()
+	}
+	if err := service.Init(cfg); err != nil {
+		return nil, localError(err)
+	}
+	return &local{started: true}, nil
+}
+
+// localError refuses the local mode while a server or another CLI holds the
+// data dir, pointing at the server
+func localError(err error) error {
+	if errors.Is(err, service.ErrDataDirLocked) {
+		return fmt.Errorf("%w, run without --local to use the server", err)
+	}
+	return err
+}
+
+func (l *local) Close() {
+	defer service.UnlockDataDir()
+	if !l.started {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(service.CurrentConfig().Limits.ShutdownTimeout))
+	defer cancel()
+	_ = service.ShutdownJobs(ctx)
+	service.StopLocalNetwork()
+}
+
+// runJob submits job and waits for it, leaving it queued for the next start
+// when interrupted before it ran
+func (l *local) runJob(ctx context.Context, job *service.Job) (*service.Job, error) {
+	job, err := service.SubmitJob(service.WithCaller(ctx, "cli"), job)
+	if err != nil {
+		return nil, err
+	}
+	if err := service.WaitJob(ctx, job); err != nil {
+		return nil, err
+	}
+	snapshot, err := service.GetJob(job.ID)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot, job.Err()
+}
+
+func (l *local) Deploy(ctx context.Context, contractType string, body []byte) (interface{}, error) {
+	job, err := api.NewDeployJob(ctx, contractType, body)
+	if err != nil {
+		return nil, err
+	}
+	return l.runJob(ctx, job)
+}
+
+func (l *local) RunDeploymentJob(ctx context.Context, kind, deploymentID string) (interface{}, error) {
+	deployment, err := service.GetDeployment(deploymentID)
+	if err != nil {
+		return nil, err
+	}
+	return l.runJob(ctx, &service.Job{
+		Kind:         kind,
+		ContractType: deployment.ContractType,
+		Network:      deployment.Network,
+		DeploymentID: deployment.ID,
+	})
+}
+
+func (l *local) ListJobs(ctx context.Context, status string) (interface{}, error) {
+	return service.ListJobs(status), nil
+}
+
+func (l *local) GetJob(ctx context.Context, id string) (interface{}, error) {
+	return service.GetJob(id)
+}
+
+func (l *local) JobLogs(ctx context.Context, id string, follow bool, out io.Writer) error {
+	return service.FollowJobLog(ctx, id, out, follow)
+}
+
+func (l *local) ListDeployments(ctx context.Context, filter service.DeploymentFilter) (interface{}, error) {
+	if err := filter.Normalize(); err != nil {
+		return nil, err
+	}
+	deployments, total, err := service.ListDeployments(filter)
+	if err != nil {
+		return nil, err
+	}
+	return api.ListDeploymentsResponse{Items: deployments, Total: total, Page: filter.Page, PageSize: filter.PageSize}, nil
+}
+
+func (l *local) GetDeployment(ctx context.Context, id string) (interface{}, error) {
+	deployment, err := service.GetDeployment(id)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"deployment": deployment,
+		"actions":    service.DeploymentActions(deployment),
+	}, nil
+}
+
+func (l *local) ExportDeployments(ctx context.Context, format string, filter service.DeploymentFilter, out io.Writer) error {
+	switch format {
+	case "json":
+		return printResult(out, service.BuildManifest(filter), nil)
+	case "yaml":
+		return yaml.NewEncoder(out).Encode(service.BuildManifest(filter))
+	case "devops":
+		run, err := service.BuildDevOpsRun(filter)
+		if err != nil {
+			return err
+		}
+		return printResult(out, run, nil)
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
@@ -0,0 +1,33 @@
+//go:build unix
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"auto-deploy-contract/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRun_LocalLocked checks the local mode is refused while a server holds
+// the data dir
+func TestRun_LocalLocked(t *testing.T) {
+	setupServer(t)
+	lock, err := os.OpenFile(filepath.Join(filepath.Dir(service.StorePath), "adc.lock"), os.O_RDWR|os.O_CREATE, 0600)
+	require.NoError(t, err)
+	t.Cleanup(func() { lock.Close() })
+	require.NoError(t, syscall.Flock(int(lock.Fd()), syscall.LOCK_EX))
+
+	var out bytes.Buffer
+	err = run(context.Background(), []string{"deployments", "get", "dep", "--local"}, nil, &out)
+	require.ErrorIs(t, err, service.ErrDataDirLocked)
+	assert.Contains(t, err.Error(), "run without --local")
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,212 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"auto-deploy-contract/service"
+)
+
+// apiPrefix is the base path of the versioned API
+const apiPrefix = "/api/v1"
+
+// remote calls the HTTP API of a running server
+type remote struct {
+	server   string
+	user     string
+	password string
+	// No timeout: deployments are answered once they finish
+	client *http.Client
+}
+
+func newRemote(server, user, password string) *remote {
+	return &remote{
+		server:   strings.TrimSuffix(server, "/"),
+		user:     user,
+		password: password,
+		client:   &http.Client{},
+	}
+}
+
+func (r *remote) Close() {}
+
+// apiResponse is the envelope of every JSON response of the API
+type apiResponse struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// apiError is a response whose code is not 200
+type apiError struct {
+	Code    int
+	Message string
+	Detail  string
+}
+
+func (e *apiError) Error() string {
+	if e.Detail == "" {
+		return fmt.Sprintf("%s (%d)", e.Message, e.Code)
+	}
+	return fmt.Sprintf("%s (%d): %s", e.Message, e.Code, e.Detail)
+}
+
+func (r *remote) do(ctx context.Context, method, path string, query url.Values, body []byte) (*http.Response, error) {
+	target := r.server + apiPrefix + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(r.user, r.password)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %v", r.server, err)
+	}
+	return resp, nil
+}
+
+// call performs a request answered with the API envelope and returns its
+// data. The data of an error response is returned with the error, as it
+// carries the job ID of a failed job.
+func (r *remote) call(ctx context.Context, method, path string, query url.Values, body []byte) (interface{}, error) {
+	resp, err := r.do(ctx, method, path, query, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return decodeResponse(resp)
+}
+
+func decodeResponse(resp *http.Response) (interface{}, error) {
+	var envelope apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil || envelope.Code == 0 {
+		return nil, fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	if envelope.Code == http.StatusOK {
+		if len(envelope.Data) == 0 {
+			return nil, nil
+		}
+		return envelope.Data, nil
+	}
+	apiErr := &apiError{Code: envelope.Code, Message: envelope.Message}
+	var detail struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(envelope.Data, &detail) == nil {
+		apiErr.Detail = detail.Error
+	}
+	if len(envelope.Data) == 0 || string(envelope.Data) == "null" {
+		return nil, apiErr
+	}
+	return envelope.Data, apiErr
+}
+
+func (r *remote) Deploy(ctx context.Context, contractType string, body []byte) (interface{}, error) {
+	return r.call(ctx, http.MethodPost, "/deploy/"+url.PathEscape(contractType), nil, body)
+}
+
+func (r *remote) RunDeploymentJob(ctx context.Context, kind, deploymentID string) (interface{}, error) {
+	return r.call(ctx, http.MethodPost, "/deployments/"+url.PathEscape(deploymentID)+"/"+kind, nil, nil)
+}
+
+func (r *remote) ListJobs(ctx context.Context, status string) (interface{}, error) {
+	query := url.Values{}
+	if status != "" {
+		query.Set("status", status)
+	}
+	return r.call(ctx, http.MethodGet, "/jobs", query, nil)
+}
+
+func (r *remote) GetJob(ctx context.Context, id string) (interface{}, error) {
+	return r.call(ctx, http.MethodGet, "/jobs/"+url.PathEscape(id), nil, nil)
+}
+
+func (r *remote) JobLogs(ctx context.Context, id string, follow bool, out io.Writer) error {
+	query := url.Values{}
+	if follow {
+		query.Set("follow", "true")
+	}
+	return r.stream(ctx, "/jobs/"+url.PathEscape(id)+"/logs", query, out)
+}
+
+func (r *remote) ListDeployments(ctx context.Context, filter service.DeploymentFilter) (interface{}, error) {
+	query := url.Values{}
+	for key, value := range map[string]string{
+		"contract_type": filter.ContractType,
+		"network":       filter.Network,
+		"owner":         filter.Owner,
+		"caller":        filter.Caller,
+		"status":        filter.Status,
+		"proxy_address": filter.ProxyAddress,
+		"project_name":  filter.ProjectName,
+		"sort":          filter.Sort,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	query.Set("page", strconv.Itoa(filter.Page))
+	query.Set("page_size", strconv.Itoa(filter.PageSize))
+	return r.call(ctx, http.MethodGet, "/deployments", query, nil)
+}
+
+func (r *remote) GetDeployment(ctx context.Context, id string) (interface{}, error) {
+	return r.call(ctx, http.MethodGet, "/deployments/"+url.PathEscape(id), nil, nil)
+}
+
+func (r *remote) ExportDeployments(ctx context.Context, format string, filter service.DeploymentFilter, out io.Writer) error {
+	query := url.Values{"format": {format}}
+	for key, value := range map[string]string{
+		"network":       filter.Network,
+		"project_name":  filter.ProjectName,
+		"contract_type": filter.ContractType,
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	return r.stream(ctx, "/deployments/export", query, out)
+}
+
+// stream copies the body of a GET answered with a document rather than the
+// API envelope to out. Errors still come back in the envelope.
+func (r *remote) stream(ctx context.Context, path string, query url.Values, out io.Writer) error {
+	resp, err := r.do(ctx, http.MethodGet, path, query, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK && !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		_, err := io.Copy(out, resp.Body)
+		return err
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	var envelope apiResponse
+	if json.Unmarshal(body, &envelope) == nil && envelope.Code != 0 && envelope.Code != http.StatusOK {
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		_, err := decodeResponse(resp)
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	_, err = out.Write(body)
+	return err
+}
@@ -14,14 +14,22 @@ rm -rf ./build
 mkdir -p ./build
 GOOS=linux GOARCH=amd64 go build -o ./build/auto-deploy-contract ./main.go
 cp .env ./build/
+if [ -f config.yaml ]; then cp config.yaml ./build/; fi
 
 # 先停止服务器上运行的程序
 echo "检查并停止服务器上运行的程序..."
 ssh $SERVER_USER@$SERVER_IP "pid=\$(ps aux | grep '[a]uto-deploy-contract' | awk '{print \$2}'); \
     if [ ! -z \"\$pid\" ]; then \
         echo \"终止已运行的程序 (PID: \$pid)...\"; \
-        kill \$pid; \
-        sleep 2; \
+        kill -TERM \$pid; \
+        for i in \$(seq 1 660); do \
+            kill -0 \$pid 2>/dev/null || break; \
+            sleep 1; \
+        done; \
+        if kill -0 \$pid 2>/dev/null; then \
+            echo \"程序未在规定时间内退出, 强制终止\"; \
+            kill -9 \$pid; \
+        fi; \
     fi"
 
 # 上传到服务器
@@ -29,6 +37,7 @@ echo "上传文件到服务器..."
 ssh $SERVER_USER@$SERVER_IP "rm  $SERVER_DIR/auto-deploy-contract"
 scp ./build/auto-deploy-contract $SERVER_USER@$SERVER_IP:$SERVER_DIR/
 scp ./build/.env $SERVER_USER@$SERVER_IP:$SERVER_DIR/ && ls
+if [ -f ./build/config.yaml ]; then scp ./build/config.yaml $SERVER_USER@$SERVER_IP:$SERVER_DIR/; fi
 
 echo "拉去git代码"
 ssh $SERVER_USER@$SERVER_IP "cd $SERVER_DIR  && git pull"
@@ -43,4 +52,22 @@ echo "服务已启动，PID: \$!"
 ps aux | grep '[a]uto-deploy-contract'
 EOF
 
+# 等待服务健康检查通过
+echo "等待服务启动..."
+for i in $(seq 1 30); do
+    if ssh $SERVER_USER@$SERVER_IP "curl -sf http://127.0.0.1:8070/healthz > /dev/null"; then
+        echo "服务已启动"
+        break
+    fi
+    if [ $i -eq 30 ]; then
+        echo "服务启动失败，请检查 output.log"
+        exit 1
+    fi
+    sleep 2
+done
+
+# 就绪检查失败不影响部署, 仅输出失败项
+ssh $SERVER_USER@$SERVER_IP "curl -s http://127.0.0.1:8070/readyz" || true
+echo
+
 echo "部署完成！"
\ No newline at end of file
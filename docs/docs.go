@@ -16,27 +16,254 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/deploy/IAO": {
+        "/admin/build": {
+            "get": {
+                "description": "Get the status of the latest build of the cached contract artifacts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get build status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Deploy a new contract with the given parameters",
-                "consumes": [
+                "description": "Force a rebuild of the cached contract artifacts used by deployments. The build runs in the background",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Rebuild contracts",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/readiness": {
+            "get": {
+                "description": "Run the checks of GET /readyz and return their details and errors, such as the RPC URLs, the deployer address and balance and the build error",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Get readiness details",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.Readiness"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.Readiness"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reload": {
+            "post": {
+                "description": "Read the .env and config files again and apply the networks, signer, auth, limits and webhooks sections, as SIGHUP does. An invalid config is rejected and the current one kept. Changes to other sections are reported and take effect on restart",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Reload config",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.ConfigReload"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/contract-types": {
+            "get": {
+                "description": "List the deployable contract types with the networks they can be deployed to, their post-deploy actions and the build and toolchain versions deployments use",
+                "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "deployment"
+                ],
+                "summary": "List contract types",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/service.ContractTypeInfo"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/contract-types/{type}/schema": {
+            "get": {
+                "description": "Get the JSON Schema of the body of POST /deploy/{type}, generated from the type's request struct and its binding and example tags, or from the schema declared with the type",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "deployment"
                 ],
-                "summary": "Deploy contract",
+                "summary": "Get contract type schema",
                 "parameters": [
                     {
-                        "description": "Deployment parameters",
+                        "type": "string",
+                        "description": "Contract type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.Schema"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/contracts/{address}/actions": {
+            "post": {
+                "description": "Execute admin actions against a deployed contract with the deployer signer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contracts"
+                ],
+                "summary": "Run contract actions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contract address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Actions to execute",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.DeployIAORequest"
+                            "$ref": "#/definitions/api.ContractActionsRequest"
                         }
                     }
                 ],
@@ -53,18 +280,36 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.StandardResponse"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.StandardResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
                     }
                 }
             }
         },
-        "/deploy/payment": {
+        "/contracts/{address}/call": {
             "post": {
-                "description": "Deploy a new contract with the given parameters",
+                "description": "Call a view function of a deployed contract with eth_call, using the ABI of the compiled artifacts, and return the decoded results",
                 "consumes": [
                     "application/json"
                 ],
@@ -72,17 +317,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "deployment"
+                    "contracts"
                 ],
-                "summary": "Deploy contract",
+                "summary": "Call a contract",
                 "parameters": [
                     {
-                        "description": "Deployment parameters",
+                        "type": "string",
+                        "description": "Contract address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Function to call",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.DeployPaymentRequest"
+                            "$ref": "#/definitions/api.ContractCallRequest"
                         }
                     }
                 ],
@@ -90,7 +342,19 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/api.StandardResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.CallResult"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -99,18 +363,115 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.StandardResponse"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.StandardResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
                     }
                 }
             }
         },
-        "/deploy/staking": {
+        "/contracts/{address}/events": {
+            "get": {
+                "description": "List the events of a contract deployed by the service, decoded with the ABI of the compiled artifacts and indexed in chain order. Events of blocks replaced by a reorg are dropped and indexed again",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "contracts"
+                ],
+                "summary": "List contract events",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contract address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Event name or signature",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Network",
+                        "name": "network",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "First block",
+                        "name": "from_block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Last block",
+                        "name": "to_block",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.ListEventsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/contracts/{address}/transact": {
             "post": {
-                "description": "Deploy a new contract with the given parameters",
+                "description": "Send a transaction calling a function of a contract deployed by the service, using the ABI of the compiled artifacts. The call is simulated with eth_call first, must be within the scope of the signer, and is recorded in the audit log with the contract's actions. The response carries the receipt and the decoded events",
                 "consumes": [
                     "application/json"
                 ],
@@ -118,17 +479,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "deployment"
+                    "contracts"
                 ],
-                "summary": "Deploy contract",
+                "summary": "Send a contract transaction",
                 "parameters": [
                     {
-                        "description": "Deployment parameters",
+                        "type": "string",
+                        "description": "Contract address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Function to call",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.DeployStakingRequest"
+                            "$ref": "#/definitions/api.ContractTransactRequest"
                         }
                     }
                 ],
@@ -145,16 +513,40 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.StandardResponse"
                         }
                     },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.StandardResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
                     }
                 }
             }
         },
-        "/deploy/token": {
+        "/deploy/IAO": {
             "post": {
                 "description": "Deploy a new contract with the given parameters",
                 "consumes": [
@@ -174,7 +566,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.DeployTokenRequest"
+                            "$ref": "#/definitions/api.DeployIAORequest"
                         }
                     }
                 ],
@@ -191,200 +583,2121 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.StandardResponse"
                         }
                     },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.StandardResponse"
                         }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.DeployIAORequest": {
-            "type": "object",
-            "required": [
-                "duration_hours",
-                "owner",
-                "reward_amount",
-                "reward_token",
-                "start_timestamp",
-                "token_in_address"
-            ],
-            "properties": {
-                "duration_hours": {
-                    "type": "integer",
-                    "example": 72
-                },
-                "owner": {
-                    "type": "string",
-                    "example": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"
-                },
-                "reward_amount": {
-                    "type": "string",
-                    "example": "2000000000000000000000000000"
-                },
-                "reward_token": {
-                    "type": "string",
-                    "example": "0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"
-                },
+        },
+        "/deploy/payment": {
+            "post": {
+                "description": "Deploy a new contract with the given parameters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deployment"
+                ],
+                "summary": "Deploy contract",
+                "parameters": [
+                    {
+                        "description": "Deployment parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.DeployPaymentRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/deploy/staking": {
+            "post": {
+                "description": "Deploy a new contract with the given parameters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deployment"
+                ],
+                "summary": "Deploy contract",
+                "parameters": [
+                    {
+                        "description": "Deployment parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.DeployStakingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/deploy/token": {
+            "post": {
+                "description": "Deploy a new contract with the given parameters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deployment"
+                ],
+                "summary": "Deploy contract",
+                "parameters": [
+                    {
+                        "description": "Deployment parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.DeployTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/deploy/{type}": {
+            "post": {
+                "description": "Deploy a contract of a registered type. The body is validated against the type's request struct or JSON schema",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deployment"
+                ],
+                "summary": "Deploy contract of any type",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contract type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Deployment parameters",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/deployments": {
+            "get": {
+                "description": "Search recorded deployments. String filters match case-insensitively; sort by created_at, finished_at, contract_type, network, status or project_name, prefixed with \"-\" for descending",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deployments"
+                ],
+                "summary": "List deployments",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Contract type",
+                        "name": "contract_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Network",
+                        "name": "network",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Owner address",
+                        "name": "owner",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "User who requested the deployment",
+                        "name": "caller",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "success",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Deployment status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Proxy address",
+                        "name": "proxy_address",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project name",
+                        "name": "project_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC3339 or YYYY-MM-DD",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, RFC3339 or YYYY-MM-DD",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "-created_at",
+                        "description": "Sort field",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 20,
+                        "description": "Page size, at most 100",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.ListDeploymentsResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/deployments/export": {
+            "get": {
+                "description": "Export the latest successful deployment of each contract type as an address book keyed by network, project and contract type. The devops format mirrors a forge broadcast run-latest.json for foundry-devops DevOpsTools.get_most_recent_deployment and requires a network",
+                "produces": [
+                    "application/json",
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "deployments"
+                ],
+                "summary": "Export deployment manifest",
+                "parameters": [
+                    {
+                        "enum": [
+                            "json",
+                            "yaml",
+                            "devops"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Manifest format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Network",
+                        "name": "network",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Project name",
+                        "name": "project_name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Contract type",
+                        "name": "contract_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/service.Manifest"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/deployments/{id}": {
+            "get": {
+                "description": "Get a deployment with its parameters, toolchain and the actions run against its proxy",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deployments"
+                ],
+                "summary": "Get deployment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Deployment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/deployments/{id}/upgrade": {
+            "post": {
+                "description": "Deploy the current source of the deployment's contract as a new implementation and upgrade the proxy to it with the upgrade script of its contract type. The signer must own the proxy",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deployments"
+                ],
+                "summary": "Upgrade deployment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Deployment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/deployments/{id}/verify": {
+            "post": {
+                "description": "Submit the implementation of a deployment to the network's verifier again, e.g. after \"Deployment successful, verification failed\"",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "deployments"
+                ],
+                "summary": "Verify deployment",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Deployment ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Report that the process is alive. Does not require authentication",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/iaos": {
+            "get": {
+                "description": "Summarize each deployed IAO from its on-chain state: deposit period and remaining time, total deposited and number of depositors, result once ended, reward token funding and the top depositors ranked from the indexed DepositTokenIn events. IAOs whose state could not be read carry the error",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "iao"
+                ],
+                "summary": "List IAOs",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of top depositors, at most 100",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/service.IAOSummary"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/iaos/{address}": {
+            "get": {
+                "description": "Summarize a deployed IAO from its on-chain state, read at the latest block",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "iao"
+                ],
+                "summary": "Get IAO",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "IAO contract address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "Number of top depositors, at most 100",
+                        "name": "top",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.IAOSummary"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs": {
+            "get": {
+                "description": "List deploy and actions jobs, newest first. Jobs interrupted by a crash are reconciled on startup; those whose outcome could not be established have status needs_review",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "List jobs",
+                "parameters": [
+                    {
+                        "enum": [
+                            "queued",
+                            "running",
+                            "succeeded",
+                            "failed",
+                            "needs_review"
+                        ],
+                        "type": "string",
+                        "description": "Job status",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/service.Job"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}": {
+            "get": {
+                "description": "Get the status and result of a deploy or actions job. Jobs still queued at shutdown resume after the restart",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.Job"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/jobs/{id}/logs": {
+            "get": {
+                "description": "Get the log of a job as plain text. With follow=true the response streams new lines until the job finishes",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "jobs"
+                ],
+                "summary": "Get job log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Stream until the job finishes",
+                        "name": "follow",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/metrics": {
+            "get": {
+                "description": "Expose deployment, job, gas, deployer balance, auth and HTTP metrics in the Prometheus text format. Does not require authentication",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Prometheus metrics",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/networks": {
+            "get": {
+                "description": "List the networks contracts can be deployed to, with the dependency addresses injected on each",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "List networks",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Check the deploy path: forge and make are present, the contracts compile, each network's RPC answers eth_chainId with the expected ID, the deployer key is loaded with a balance above MIN_DEPLOYER_BALANCE and the verifier API is reachable. Does not require authentication, so only the check names and outcomes are returned, the details are served by GET /admin/readiness",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.Readiness"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/api.StandardResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/service.Readiness"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    }
+                }
+            }
+        },
+        "/toolchain": {
+            "get": {
+                "description": "Get the forge, cast, make, node and solc binaries and versions used for deployments",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Get toolchain",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/api.StandardResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.ContractActionsRequest": {
+            "type": "object",
+            "required": [
+                "actions",
+                "contract_type"
+            ],
+            "properties": {
+                "actions": {
+                    "description": "Actions executed in order with the deployer signer",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/service.Action"
+                    }
+                },
+                "contract_type": {
+                    "description": "Registered contract type of the target contract, e.g. IAO/staking/token/payment",
+                    "type": "string",
+                    "example": "payment"
+                },
+                "network": {
+                    "description": "Network the contract is deployed to, defaults to dbc-mainnet",
+                    "type": "string",
+                    "example": "dbc-mainnet"
+                }
+            }
+        },
+        "api.ContractCallRequest": {
+            "type": "object",
+            "required": [
+                "function"
+            ],
+            "properties": {
+                "args": {
+                    "description": "Function arguments: numbers as JSON numbers or decimal or 0x strings, tuples as arrays or objects",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "artifact": {
+                    "description": "Compiled contract providing the ABI, e.g. XAAIAO.sol:XAAIAO. Overrides the contract of the type.",
+                    "type": "string",
+                    "example": "XAAIAO.sol:XAAIAO"
+                },
+                "block": {
+                    "description": "Block number, or latest (default), pending or earliest",
+                    "type": "string",
+                    "example": "latest"
+                },
+                "contract_type": {
+                    "description": "Registered contract type of the target contract, e.g. IAO/staking/token/payment.\nDefaults to the type of the deployment recorded at the address.",
+                    "type": "string",
+                    "example": "IAO"
+                },
+                "function": {
+                    "description": "View function name, or signature when overloaded",
+                    "type": "string",
+                    "example": "isStarted"
+                },
+                "network": {
+                    "description": "Network the contract is deployed to, defaults to the network of the recorded deployment, then dbc-mainnet",
+                    "type": "string",
+                    "example": "dbc-mainnet"
+                }
+            }
+        },
+        "api.ContractTransactRequest": {
+            "type": "object",
+            "required": [
+                "function"
+            ],
+            "properties": {
+                "args": {
+                    "description": "Function arguments: numbers as JSON numbers or decimal or 0x strings, tuples as arrays or objects",
+                    "type": "array",
+                    "items": {
+                        "type": "object"
+                    }
+                },
+                "artifact": {
+                    "description": "Compiled contract providing the ABI, defaults to the artifact recorded with the deployment",
+                    "type": "string",
+                    "example": "NFTStaking.sol:NFTStaking"
+                },
+                "contract_type": {
+                    "description": "Registered contract type of the target contract, checked against the recorded deployment",
+                    "type": "string",
+                    "example": "staking"
+                },
+                "function": {
+                    "description": "Function name, or signature when overloaded",
+                    "type": "string",
+                    "example": "setRewardToken"
+                },
+                "network": {
+                    "description": "Network the contract is deployed to, defaults to the network of the recorded deployment",
+                    "type": "string",
+                    "example": "dbc-mainnet"
+                },
+                "signer": {
+                    "description": "Signer key from the config, defaults to the deployer",
+                    "type": "string",
+                    "example": "deployer"
+                }
+            }
+        },
+        "api.DeployIAORequest": {
+            "type": "object",
+            "required": [
+                "duration_hours",
+                "owner",
+                "reward_amount",
+                "reward_token",
+                "start_timestamp"
+            ],
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Action"
+                    }
+                },
+                "duration_hours": {
+                    "type": "integer",
+                    "example": 72
+                },
+                "network": {
+                    "type": "string",
+                    "example": "dbc-mainnet"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"
+                },
+                "reward_amount": {
+                    "type": "string",
+                    "example": "2000000000000000000000000000"
+                },
+                "reward_token": {
+                    "type": "string",
+                    "example": "0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"
+                },
                 "start_timestamp": {
                     "type": "integer",
                     "example": 1743663600
                 },
-                "token_in_address": {
-                    "type": "string",
-                    "example": "0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"
+                "token_in_address": {
+                    "description": "Defaults to the token of the network, a mock on the local network",
+                    "type": "string",
+                    "example": "0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"
+                }
+            }
+        },
+        "api.DeployPaymentRequest": {
+            "type": "object",
+            "required": [
+                "address_free_request_count",
+                "free_request_count",
+                "min_usd_balance_for_using_free_request",
+                "owner",
+                "payment_token",
+                "vip_monthly_quotas",
+                "vip_price_fixed_count",
+                "vip_price_monthly"
+            ],
+            "properties": {
+                "actions": {
+                    "description": "Optional post-deploy actions executed with the deployer signer",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Action"
+                    }
+                },
+                "address_free_request_count": {
+                    "description": "Number of free requests available for each address",
+                    "type": "integer",
+                    "example": 10
+                },
+                "free_request_count": {
+                    "description": "Number of free requests available for the contract",
+                    "type": "integer",
+                    "example": 100
+                },
+                "min_usd_balance_for_using_free_request": {
+                    "description": "Minimum USD balance required for using free requests for the address",
+                    "type": "integer",
+                    "example": 100000
+                },
+                "network": {
+                    "description": "Network to deploy to, defaults to dbc-mainnet",
+                    "type": "string",
+                    "example": "dbc-mainnet"
+                },
+                "owner": {
+                    "description": "Owner address of the contract",
+                    "type": "string",
+                    "example": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"
+                },
+                "payment_token": {
+                    "description": "Payment token address",
+                    "type": "string",
+                    "example": "0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"
+                },
+                "vip_monthly_quotas": {
+                    "description": "Number of VIP requests available for each month",
+                    "type": "integer",
+                    "example": 10
+                },
+                "vip_price_fixed_count": {
+                    "description": "Fixed price for VIP requests",
+                    "type": "integer",
+                    "example": 100000
+                },
+                "vip_price_monthly": {
+                    "description": "Monthly price for VIP requests",
+                    "type": "integer",
+                    "example": 100000
+                }
+            }
+        },
+        "api.DeployStakingRequest": {
+            "type": "object",
+            "required": [
+                "nft",
+                "owner",
+                "project_name",
+                "reward_amount_per_year",
+                "reward_token"
+            ],
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Action"
+                    }
+                },
+                "network": {
+                    "type": "string",
+                    "example": "dbc-mainnet"
+                },
+                "nft": {
+                    "type": "string",
+                    "example": "0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"
+                },
+                "project_name": {
+                    "type": "string",
+                    "example": "Project"
+                },
+                "reward_amount_per_year": {
+                    "type": "string",
+                    "example": "2000000000000000000000000000"
+                },
+                "reward_token": {
+                    "type": "string",
+                    "example": "0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"
+                }
+            }
+        },
+        "api.DeployTokenRequest": {
+            "type": "object",
+            "required": [
+                "amount_to_iao",
+                "iao_contract_address",
+                "owner",
+                "token_amount_can_mint_per_year",
+                "token_init_supply",
+                "token_name",
+                "token_supply_fixed_years",
+                "token_symbol"
+            ],
+            "properties": {
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Action"
+                    }
+                },
+                "amount_to_iao": {
+                    "type": "string",
+                    "example": "100000000000000000000000000"
+                },
+                "iao_contract_address": {
+                    "type": "string",
+                    "example": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"
+                },
+                "network": {
+                    "type": "string",
+                    "example": "dbc-mainnet"
+                },
+                "owner": {
+                    "type": "string",
+                    "example": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"
+                },
+                "token_amount_can_mint_per_year": {
+                    "type": "string",
+                    "example": "6000000000000000000000000000"
+                },
+                "token_init_supply": {
+                    "type": "string",
+                    "example": "2000000000000000000000000000"
+                },
+                "token_name": {
+                    "type": "string",
+                    "example": "TokenName"
+                },
+                "token_supply_fixed_years": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "token_symbol": {
+                    "type": "string",
+                    "example": "TN"
+                }
+            }
+        },
+        "api.ListDeploymentsResponse": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Deployment"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ListEventsResponse": {
+            "type": "object",
+            "properties": {
+                "indexed_block": {
+                    "description": "Last block indexed for the contract, null before the first poll",
+                    "type": "integer"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.IndexedEvent"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.StandardResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Status code",
+                    "type": "integer"
+                },
+                "data": {
+                    "description": "Data"
+                },
+                "message": {
+                    "description": "Message",
+                    "type": "string"
+                }
+            }
+        },
+        "service.ABIValue": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "value": {}
+            }
+        },
+        "service.Action": {
+            "type": "object",
+            "required": [
+                "function"
+            ],
+            "properties": {
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"
+                    ]
+                },
+                "function": {
+                    "type": "string",
+                    "example": "setOracle"
+                }
+            }
+        },
+        "service.ActionResult": {
+            "type": "object",
+            "properties": {
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "block_number": {
+                    "description": "BlockNumber, GasUsed and Events are read from the receipt of a\ntransact request",
+                    "type": "integer"
+                },
+                "caller": {
+                    "description": "Caller and Signer are recorded for transact requests",
+                    "type": "string"
+                },
+                "contract": {
+                    "type": "string"
+                },
+                "contract_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ContractEvent"
+                    }
+                },
+                "function": {
+                    "type": "string"
+                },
+                "gas_used": {
+                    "type": "integer"
+                },
+                "network": {
+                    "type": "string"
+                },
+                "signer": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "tx_hash": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.CallResult": {
+            "type": "object",
+            "properties": {
+                "artifact": {
+                    "type": "string"
+                },
+                "block": {
+                    "type": "string"
+                },
+                "contract": {
+                    "type": "string"
+                },
+                "function": {
+                    "type": "string"
+                },
+                "network": {
+                    "type": "string"
+                },
+                "outputs": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ABIValue"
+                    }
+                }
+            }
+        },
+        "service.CheckResult": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "ok": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.ConfigReload": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "description": "Applied lists the changed sections now in effect",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "restart_required": {
+                    "description": "RestartRequired lists the changed sections ignored until the next\nstart",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "service.ContractEvent": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ABIValue"
+                    }
+                },
+                "data": {
+                    "type": "string"
+                },
+                "event": {
+                    "description": "Event is the signature of the decoded event",
+                    "type": "string"
+                },
+                "log_index": {
+                    "type": "integer"
+                },
+                "topics": {
+                    "description": "Topics and Data are kept for the logs that could not be decoded",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "service.ContractTypeInfo": {
+            "type": "object",
+            "properties": {
+                "actions": {
+                    "description": "Post-deploy actions accepted by the type",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "networks": {
+                    "description": "Networks the type can be deployed to",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "script": {
+                    "type": "string"
+                },
+                "versions": {
+                    "$ref": "#/definitions/service.ContractTypeVersions"
+                }
+            }
+        },
+        "service.ContractTypeVersions": {
+            "type": "object",
+            "properties": {
+                "build": {
+                    "description": "Build is the key of the compiled artifacts, changing with the sources",
+                    "type": "string"
+                },
+                "forge": {
+                    "type": "string"
+                },
+                "image": {
+                    "description": "Image is the toolchain image of the containerized runner",
+                    "type": "string"
+                },
+                "solc": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Deployment": {
+            "type": "object",
+            "properties": {
+                "abi": {
+                    "type": "string"
+                },
+                "block_number": {
+                    "type": "integer"
+                },
+                "caller": {
+                    "type": "string"
+                },
+                "chain_id": {
+                    "type": "integer"
+                },
+                "contract_name": {
+                    "type": "string"
+                },
+                "contract_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "fee": {
+                    "description": "Fee paid by the deployment transactions, in wei",
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "gas_used": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "implementation_address": {
+                    "description": "Implementation, contract name, ABI artifact path relative to the\ncontracts dir, tx hash and block of the proxy, read from the broadcast",
+                    "type": "string"
+                },
+                "network": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "params": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "project_name": {
+                    "type": "string"
+                },
+                "proxy_address": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "toolchain": {
+                    "$ref": "#/definitions/service.Toolchain"
+                },
+                "tx_hash": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.IAODepositor": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "amount": {
+                    "description": "Amount is the deposit recorded by the contract",
+                    "type": "string"
+                },
+                "deposits": {
+                    "description": "Deposits is the number of deposit transactions",
+                    "type": "integer"
+                }
+            }
+        },
+        "service.IAOSummary": {
+            "type": "object",
+            "properties": {
+                "block": {
+                    "description": "Block is the block the state was read at",
+                    "type": "integer"
+                },
+                "block_time": {
+                    "type": "string"
+                },
+                "contract": {
+                    "type": "string"
+                },
+                "deployment_id": {
+                    "type": "string"
+                },
+                "depositors": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "string"
+                },
+                "error": {
+                    "description": "Error is set in IAO lists for IAOs whose state could not be read",
+                    "type": "string"
+                },
+                "indexed_block": {
+                    "description": "IndexedBlock is the last block of the indexed deposits",
+                    "type": "integer"
+                },
+                "network": {
+                    "type": "string"
+                },
+                "project_name": {
+                    "type": "string"
+                },
+                "remaining_seconds": {
+                    "type": "integer"
+                },
+                "result": {
+                    "description": "Result is success or failed once the IAO ended. ResultError is set\ninstead when the contract could not tell, e.g. when its price oracle\nfails.",
+                    "type": "string"
+                },
+                "result_error": {
+                    "type": "string"
+                },
+                "reward_balance": {
+                    "description": "RewardBalance is the reward token balance of the IAO, RewardStatus\nwhether it covers TotalReward",
+                    "type": "string"
+                },
+                "reward_status": {
+                    "type": "string"
+                },
+                "reward_token": {
+                    "type": "string"
+                },
+                "start_time": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Status is pending, active or ended",
+                    "type": "string"
+                },
+                "token_in": {
+                    "type": "string"
+                },
+                "top_depositors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.IAODepositor"
+                    }
+                },
+                "total_deposited": {
+                    "description": "TotalDeposited is in the smallest unit of TokenIn",
+                    "type": "string"
+                },
+                "total_reward": {
+                    "type": "string"
                 }
             }
         },
-        "api.DeployPaymentRequest": {
+        "service.IndexedEvent": {
             "type": "object",
-            "required": [
-                "address_free_request_count",
-                "free_request_count",
-                "min_usd_balance_for_using_free_request",
-                "owner",
-                "payment_token",
-                "vip_monthly_quotas",
-                "vip_price_fixed_count",
-                "vip_price_monthly"
-            ],
             "properties": {
-                "address_free_request_count": {
-                    "description": "Number of free requests available for each address",
-                    "type": "integer",
-                    "example": 10
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ABIValue"
+                    }
                 },
-                "free_request_count": {
-                    "description": "Number of free requests available for the contract",
-                    "type": "integer",
-                    "example": 100
+                "block_hash": {
+                    "type": "string"
                 },
-                "min_usd_balance_for_using_free_request": {
-                    "description": "Minimum USD balance required for using free requests for the address",
-                    "type": "integer",
-                    "example": 100000
+                "block_number": {
+                    "type": "integer"
                 },
-                "owner": {
-                    "description": "Owner address of the contract",
-                    "type": "string",
-                    "example": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"
+                "contract": {
+                    "type": "string"
                 },
-                "payment_token": {
-                    "description": "Payment token address",
-                    "type": "string",
-                    "example": "0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"
+                "event": {
+                    "description": "Event is the event name, Signature its signature",
+                    "type": "string"
                 },
-                "vip_monthly_quotas": {
-                    "description": "Number of VIP requests available for each month",
-                    "type": "integer",
-                    "example": 10
+                "log_index": {
+                    "type": "integer"
                 },
-                "vip_price_fixed_count": {
-                    "description": "Fixed price for VIP requests",
-                    "type": "integer",
-                    "example": 100000
+                "network": {
+                    "type": "string"
                 },
-                "vip_price_monthly": {
-                    "description": "Monthly price for VIP requests",
-                    "type": "integer",
-                    "example": 100000
+                "signature": {
+                    "type": "string"
+                },
+                "tx_hash": {
+                    "type": "string"
                 }
             }
         },
-        "api.DeployStakingRequest": {
+        "service.Job": {
             "type": "object",
-            "required": [
-                "nft",
-                "owner",
-                "project_name",
-                "reward_amount_per_year",
-                "reward_token"
-            ],
             "properties": {
-                "nft": {
-                    "type": "string",
-                    "example": "0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"
+                "action_results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.ActionResult"
+                    }
                 },
-                "owner": {
-                    "type": "string",
-                    "example": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"
+                "actions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.Action"
+                    }
                 },
-                "project_name": {
-                    "type": "string",
-                    "example": "Project"
+                "caller": {
+                    "type": "string"
                 },
-                "reward_amount_per_year": {
-                    "type": "string",
-                    "example": "2000000000000000000000000000"
+                "contract": {
+                    "description": "Contract is the target of an actions job",
+                    "type": "string"
                 },
-                "reward_token": {
-                    "type": "string",
-                    "example": "0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"
+                "contract_type": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "deployer_nonce": {
+                    "description": "DeployerNonce is the deployer's pending nonce before the job sent\nanything, compared by RecoverJobs after a crash",
+                    "type": "integer"
+                },
+                "deployment_id": {
+                    "description": "DeploymentID and Workspace identify the deployment a running deploy\njob started, or the target of a verify or upgrade job. RecoverJobs\nuses them after a crash.",
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "implementation_address": {
+                    "description": "ImplementationAddress is the new implementation of an upgrade job",
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string"
+                },
+                "network": {
+                    "type": "string"
+                },
+                "params": {
+                    "description": "Params are the script env vars of a deploy job, without the private key",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "proxy_address": {
+                    "type": "string"
+                },
+                "recovered": {
+                    "type": "boolean"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "transaction": {
+                    "description": "Transaction is sent by a transact job",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/service.Transaction"
+                        }
+                    ]
+                },
+                "workspace": {
+                    "type": "string"
                 }
             }
         },
-        "api.DeployTokenRequest": {
+        "service.Manifest": {
             "type": "object",
-            "required": [
-                "amount_to_iao",
-                "iao_contract_address",
-                "owner",
-                "token_amount_can_mint_per_year",
-                "token_init_supply",
-                "token_name",
-                "token_supply_fixed_years",
-                "token_symbol"
-            ],
             "properties": {
-                "amount_to_iao": {
-                    "type": "string",
-                    "example": "100000000000000000000000000"
+                "generated_at": {
+                    "type": "string"
                 },
-                "iao_contract_address": {
-                    "type": "string",
-                    "example": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"
+                "networks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/service.ManifestNetwork"
+                    }
                 },
-                "owner": {
-                    "type": "string",
-                    "example": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "service.ManifestContract": {
+            "type": "object",
+            "properties": {
+                "abi": {
+                    "type": "string"
                 },
-                "token_amount_can_mint_per_year": {
-                    "type": "string",
-                    "example": "6000000000000000000000000000"
+                "block_number": {
+                    "type": "integer"
                 },
-                "token_init_supply": {
-                    "type": "string",
-                    "example": "2000000000000000000000000000"
+                "contract_name": {
+                    "type": "string"
                 },
-                "token_name": {
-                    "type": "string",
-                    "example": "TokenName"
+                "deployed_at": {
+                    "type": "string"
                 },
-                "token_supply_fixed_years": {
-                    "type": "integer",
-                    "example": 8
+                "deployment_id": {
+                    "type": "string"
                 },
-                "token_symbol": {
-                    "type": "string",
-                    "example": "TN"
+                "implementation": {
+                    "type": "string"
+                },
+                "proxy": {
+                    "type": "string"
+                },
+                "tx_hash": {
+                    "type": "string"
                 }
             }
         },
-        "api.StandardResponse": {
+        "service.ManifestNetwork": {
             "type": "object",
             "properties": {
-                "code": {
-                    "description": "Status code",
+                "chain_id": {
+                    "type": "integer"
+                },
+                "projects": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "object",
+                        "additionalProperties": {
+                            "$ref": "#/definitions/service.ManifestContract"
+                        }
+                    }
+                }
+            }
+        },
+        "service.Readiness": {
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "checks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/service.CheckResult"
+                    }
+                },
+                "ready": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "service.Schema": {
+            "type": "object",
+            "properties": {
+                "additionalProperties": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "enum": {
+                    "type": "array",
+                    "items": {}
+                },
+                "example": {},
+                "items": {
+                    "$ref": "#/definitions/service.Schema"
+                },
+                "maxLength": {
+                    "type": "integer"
+                },
+                "maximum": {
+                    "type": "number"
+                },
+                "minLength": {
                     "type": "integer"
                 },
+                "minimum": {
+                    "type": "number"
+                },
+                "pattern": {
+                    "type": "string"
+                },
+                "properties": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/service.Schema"
+                    }
+                },
+                "required": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Toolchain": {
+            "type": "object",
+            "properties": {
+                "cast": {
+                    "type": "string"
+                },
+                "cast_version": {
+                    "type": "string"
+                },
+                "forge": {
+                    "type": "string"
+                },
+                "forge_version": {
+                    "type": "string"
+                },
+                "image": {
+                    "type": "string"
+                },
+                "make": {
+                    "type": "string"
+                },
+                "make_version": {
+                    "type": "string"
+                },
+                "node": {
+                    "type": "string"
+                },
+                "node_version": {
+                    "type": "string"
+                },
+                "runner": {
+                    "description": "Runner is host, or the container runtime running the toolchain image",
+                    "type": "string"
+                },
+                "solc": {
+                    "type": "string"
+                },
+                "solc_version": {
+                    "type": "string"
+                }
+            }
+        },
+        "service.Transaction": {
+            "type": "object",
+            "properties": {
+                "args": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "artifact": {
+                    "type": "string"
+                },
                 "data": {
-                    "description": "Data"
+                    "description": "Data is the encoded call",
+                    "type": "string"
                 },
-                "message": {
-                    "description": "Message",
+                "function": {
+                    "description": "Function is the signature of the called function",
+                    "type": "string"
+                },
+                "signer": {
                     "type": "string"
                 }
             }
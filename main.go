@@ -3,8 +3,16 @@ package main
 import (
 	"auto-deploy-contract/api"
 	"auto-deploy-contract/api/middleware"
-	"flag"
-	"log"
+	"auto-deploy-contract/cli"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	_ "auto-deploy-contract/docs"
 
@@ -17,34 +25,108 @@ import (
 
 func init() {
 	// 初始化日志
-	service.LoadEnv("./.env")
+	service.LoadEnv(service.EnvFile)
 }
 
 func main() {
-	// 添加环境参数
-	env := flag.String("env", "dev", "(dev/prod)")
-	flag.Parse()
+	// 无子命令时启动服务, 其余子命令见 cli 包
+	os.Exit(cli.Run(os.Args[1:], serve))
+}
 
-	service.Init(*env)
-	if *env == "prod" {
+// serve 启动HTTP服务, 收到SIGINT/SIGTERM后退出
+func serve(cfg *service.Config) error {
+	if err := service.SetupLogging(cfg.Logging); err != nil {
+		return err
+	}
+	shutdownTracing, err := service.SetupTracing(context.Background())
+	if err != nil {
+		return err
+	}
+	defer shutdownTracing(context.Background())
+	if err := service.Init(cfg); err != nil {
+		return fmt.Errorf("failed to initialize service: %v", err)
+	}
+	// 索引已部署合约的事件
+	if cfg.Indexer.Enabled {
+		service.StartIndexer()
+	}
+	if cfg.Env == "prod" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := gin.Default()
+	router := gin.New()
+	// 请求ID和结构化请求日志, 替代gin默认日志
+	router.Use(middleware.RequestID(), middleware.Tracing(), middleware.Logger(), gin.Recovery())
+
+	// 请求指标需在Basic Auth之前注册, 以统计认证失败的请求
+	if cfg.Features.Metrics {
+		router.Use(middleware.Metrics())
+	}
+
+	// 健康检查和指标路由需在Basic Auth之前注册, 无需认证
+	api.RegisterHealthRoutes(router)
+	if cfg.Features.Metrics {
+		api.RegisterMetricsRoutes(router)
+	}
 
 	// 添加全局Basic Auth中间件
 	router.Use(middleware.BasicAuth())
 
 	// 添加 Swagger 路由
-	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	if cfg.Features.Swagger {
+		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+
+	// 注册路由, 与Swagger文档的BasePath一致
+	// 兼容旧客户端: 开启legacy_routes时无版本前缀的路径始终返回HTTP 200, 状态码仅在响应体中
+	api.RegisterRoutes(router, cfg.Features.LegacyRoutes)
+
+	srv := &http.Server{Addr: cfg.Listen, Handler: router}
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("server starting", "addr", cfg.Listen, "env", cfg.Env)
+		serveErr <- srv.ListenAndServe()
+	}()
 
-	// 注册路由
-	api.RegisterDeployIAORoutes(router)
-	api.RegisterDeployStakingRoutes(router)
-	api.RegisterDeployTokenRoutes(router)
-	api.RegisterDeployPaymentRoutes(router)
-	log.Printf("Server starting on :8070 in %s mode", *env)
-	if err := router.Run("0.0.0.0:8070"); err != nil {
-		log.Fatal(err)
+	// 收到SIGHUP时重新加载配置, 配置无效时保留当前配置
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	// 收到SIGINT/SIGTERM后停止接收新任务, 等待正在执行的部署完成后再退出
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	for ctx.Err() == nil {
+		select {
+		case err := <-serveErr:
+			return fmt.Errorf("server stopped: %v", err)
+		case <-hup:
+			if _, err := service.ReloadConfig(); err != nil {
+				slog.Error("failed to reload config", "error", err)
+			}
+		case <-ctx.Done():
+		}
 	}
+	stop()
+	shutdownTimeout := time.Duration(service.CurrentConfig().Limits.ShutdownTimeout)
+	slog.Info("shutting down", "timeout", shutdownTimeout)
+
+	// 排队中的任务已持久化, 重启后继续执行
+	drainCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	if err := service.ShutdownJobs(drainCtx); err != nil {
+		slog.Error("failed to drain jobs", "error", err)
+	}
+	cancel()
+	service.StopIndexer()
+
+	// 等待进行中的请求返回结果
+	httpCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := srv.Shutdown(httpCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("failed to shut down server", "error", err)
+	}
+	cancel()
+
+	service.StopLocalNetwork()
+	slog.Info("server stopped")
+	return nil
 }
@@ -0,0 +1,618 @@
+package service
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// ABI is the JSON ABI of a compiled contract
+type ABI []ABIEntry
+
+// ABIEntry is a function, event, error or constructor of an ABI
+type ABIEntry struct {
+	Type            string     `json:"type"`
+	Name            string     `json:"name,omitempty"`
+	Inputs          []ABIParam `json:"inputs,omitempty"`
+	Outputs         []ABIParam `json:"outputs,omitempty"`
+	StateMutability string     `json:"stateMutability,omitempty"`
+	Anonymous       bool       `json:"anonymous,omitempty"`
+}
+
+// ABIParam is a parameter of an ABI entry
+type ABIParam struct {
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Indexed    bool       `json:"indexed,omitempty"`
+	Components []ABIParam `json:"components,omitempty"`
+}
+
+// ABIValue is a decoded value with the parameter it was decoded for
+type ABIValue struct {
+	Name  string      `json:"name,omitempty"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+var ErrABIFunctionNotFound = errors.New("function not found in ABI")
+
+var addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// Signature returns the canonical signature, e.g. "transfer(address,uint256)"
+func (e *ABIEntry) Signature() string {
+	return e.Name + "(" + canonicalTypes(e.Inputs) + ")"
+}
+
+// Selector returns the 4-byte function selector
+func (e *ABIEntry) Selector() []byte {
+	return keccak256([]byte(e.Signature()))[:4]
+}
+
+// Topic returns the topic of an event, the hash of its signature
+func (e *ABIEntry) Topic() string {
+	return "0x" + hex.EncodeToString(keccak256([]byte(e.Signature())))
+}
+
+// ReadOnly reports whether the function does not modify state
+func (e *ABIEntry) ReadOnly() bool {
+	return e.StateMutability == "view" || e.StateMutability == "pure"
+}
+
+// Function returns the function named name, or with the signature name when
+// it is overloaded
+func (a ABI) Function(name string) (*ABIEntry, error) {
+	var matches []*ABIEntry
+	for i := range a {
+		entry := &a[i]
+		if entry.Type != "function" {
+			continue
+		}
+		if entry.Signature() == name {
+			return entry, nil
+		}
+		if entry.Name == name {
+			matches = append(matches, entry)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s", ErrABIFunctionNotFound, name)
+	case 1:
+		return matches[0], nil
+	}
+	signatures := make([]string, len(matches))
+	for i, entry := range matches {
+		signatures[i] = entry.Signature()
+	}
+	return nil, fmt.Errorf("function %s is overloaded, use one of %s", name, strings.Join(signatures, ", "))
+}
+
+// Event returns the non-anonymous event whose topic is topic, nil when the
+// ABI declares none
+func (a ABI) Event(topic string) *ABIEntry {
+	for i := range a {
+		entry := &a[i]
+		if entry.Type == "event" && !entry.Anonymous && strings.EqualFold(entry.Topic(), topic) {
+			return entry
+		}
+	}
+	return nil
+}
+
+// DecodeLog decodes the topics and data of a log emitted by the event. An
+// indexed parameter of a dynamic type is given by its hash.
+func (e *ABIEntry) DecodeLog(topics []string, data []byte) ([]ABIValue, error) {
+	var indexed, unindexed []ABIParam
+	for _, param := range e.Inputs {
+		if param.Indexed {
+			indexed = append(indexed, param)
+		} else {
+			unindexed = append(unindexed, param)
+		}
+	}
+	if !e.Anonymous {
+		topics = topics[min(1, len(topics)):]
+	}
+	if len(topics) != len(indexed) {
+		return nil, fmt.Errorf("%s expects %d indexed topics, got %d", e.Signature(), len(indexed), len(topics))
+	}
+
+	topicValues := make([]ABIValue, len(indexed))
+	for i, param := range indexed {
+		t, err := parseABIType(param.Type, param.Components)
+		if err != nil {
+			return nil, err
+		}
+		topic, err := hex.DecodeString(strings.TrimPrefix(topics[i], "0x"))
+		if err != nil || len(topic) != 32 {
+			return nil, fmt.Errorf("invalid topic %s", topics[i])
+		}
+		topicValues[i] = ABIValue{Name: param.Name, Type: t.String(), Value: topics[i]}
+		if !t.dynamic() && t.headSize() == 32 {
+			if topicValues[i].Value, err = decodeValue(t, topic); err != nil {
+				return nil, err
+			}
+		}
+	}
+	dataValues, err := decodeParams(unindexed, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restore the declaration order
+	result := make([]ABIValue, 0, len(e.Inputs))
+	for _, param := range e.Inputs {
+		if param.Indexed {
+			result, topicValues = append(result, topicValues[0]), topicValues[1:]
+		} else {
+			result, dataValues = append(result, dataValues[0]), dataValues[1:]
+		}
+	}
+	return result, nil
+}
+
+// EncodeCall returns the calldata calling the function with args, values as
+// decoded by encoding/json with UseNumber
+func (e *ABIEntry) EncodeCall(args []interface{}) ([]byte, error) {
+	if len(args) != len(e.Inputs) {
+		return nil, fmt.Errorf("%s expects %d args, got %d", e.Signature(), len(e.Inputs), len(args))
+	}
+	types, err := parseABIParams(e.Inputs)
+	if err != nil {
+		return nil, err
+	}
+	data, err := encodeTuple(types, args)
+	if err != nil {
+		return nil, err
+	}
+	return append(e.Selector(), data...), nil
+}
+
+// DecodeOutputs decodes the return data of the function
+func (e *ABIEntry) DecodeOutputs(data []byte) ([]ABIValue, error) {
+	return decodeParams(e.Outputs, data)
+}
+
+// decodeParams decodes data, the ABI encoding of params
+func decodeParams(params []ABIParam, data []byte) ([]ABIValue, error) {
+	types, err := parseABIParams(params)
+	if err != nil {
+		return nil, err
+	}
+	values, err := decodeTuple(types, data)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]ABIValue, len(params))
+	for i, param := range params {
+		result[i] = ABIValue{Name: param.Name, Type: types[i].String(), Value: values[i]}
+	}
+	return result, nil
+}
+
+func canonicalTypes(params []ABIParam) string {
+	types := make([]string, len(params))
+	for i, param := range params {
+		types[i] = canonicalType(param)
+	}
+	return strings.Join(types, ",")
+}
+
+// canonicalType expands tuples to their components, e.g. "(address,uint256)[]"
+func canonicalType(param ABIParam) string {
+	if strings.HasPrefix(param.Type, "tuple") {
+		return "(" + canonicalTypes(param.Components) + ")" + strings.TrimPrefix(param.Type, "tuple")
+	}
+	return param.Type
+}
+
+func keccak256(data []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// abiType is a parsed ABI type
+type abiType struct {
+	kind string // uint, int, address, bool, fixedbytes, bytes, string, array or tuple
+	size int    // bits of uint and int, bytes of fixedbytes
+	// elem and length describe arrays, length -1 for dynamic arrays
+	elem   *abiType
+	length int
+	// fields and names describe tuples
+	fields []*abiType
+	names  []string
+	raw    string
+}
+
+func (t *abiType) String() string {
+	return t.raw
+}
+
+func parseABIParams(params []ABIParam) ([]*abiType, error) {
+	types := make([]*abiType, len(params))
+	for i, param := range params {
+		t, err := parseABIType(param.Type, param.Components)
+		if err != nil {
+			return nil, err
+		}
+		types[i] = t
+	}
+	return types, nil
+}
+
+func parseABIType(raw string, components []ABIParam) (*abiType, error) {
+	if strings.HasSuffix(raw, "]") {
+		open := strings.LastIndex(raw, "[")
+		if open < 0 {
+			return nil, fmt.Errorf("invalid ABI type %s", raw)
+		}
+		elem, err := parseABIType(raw[:open], components)
+		if err != nil {
+			return nil, err
+		}
+		t := &abiType{kind: "array", elem: elem, length: -1, raw: raw}
+		if n := raw[open+1 : len(raw)-1]; n != "" {
+			if t.length, err = strconv.Atoi(n); err != nil || t.length <= 0 {
+				return nil, fmt.Errorf("invalid ABI type %s", raw)
+			}
+		}
+		return t, nil
+	}
+
+	t := &abiType{kind: raw, raw: raw}
+	switch {
+	case raw == "address" || raw == "bool" || raw == "string" || raw == "bytes":
+	case raw == "tuple":
+		for _, component := range components {
+			field, err := parseABIType(component.Type, component.Components)
+			if err != nil {
+				return nil, err
+			}
+			t.fields = append(t.fields, field)
+			t.names = append(t.names, component.Name)
+		}
+	case strings.HasPrefix(raw, "uint"), strings.HasPrefix(raw, "int"):
+		t.kind = strings.TrimRight(raw, "0123456789")
+		t.size = 256
+		if bits := strings.TrimPrefix(raw, t.kind); bits != "" {
+			size, err := strconv.Atoi(bits)
+			if err != nil || size%8 != 0 || size < 8 || size > 256 {
+				return nil, fmt.Errorf("invalid ABI type %s", raw)
+			}
+			t.size = size
+		}
+	case strings.HasPrefix(raw, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(raw, "bytes"))
+		if err != nil || size < 1 || size > 32 {
+			return nil, fmt.Errorf("invalid ABI type %s", raw)
+		}
+		t.kind, t.size = "fixedbytes", size
+	default:
+		return nil, fmt.Errorf("unsupported ABI type %s", raw)
+	}
+	return t, nil
+}
+
+// dynamic reports whether the encoding of t is referenced by an offset
+func (t *abiType) dynamic() bool {
+	switch t.kind {
+	case "bytes", "string":
+		return true
+	case "array":
+		return t.length < 0 || t.elem.dynamic()
+	case "tuple":
+		for _, field := range t.fields {
+			if field.dynamic() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// headSize is the size of t in the head of an enclosing tuple
+func (t *abiType) headSize() int {
+	if t.dynamic() {
+		return 32
+	}
+	switch t.kind {
+	case "array":
+		return t.length * t.elem.headSize()
+	case "tuple":
+		size := 0
+		for _, field := range t.fields {
+			size += field.headSize()
+		}
+		return size
+	}
+	return 32
+}
+
+func encodeTuple(types []*abiType, values []interface{}) ([]byte, error) {
+	headSize := 0
+	for _, t := range types {
+		headSize += t.headSize()
+	}
+	var head, tail []byte
+	for i, t := range types {
+		encoded, err := encodeValue(t, values[i])
+		if err != nil {
+			return nil, err
+		}
+		if t.dynamic() {
+			head = append(head, word(big.NewInt(int64(headSize+len(tail))))...)
+			tail = append(tail, encoded...)
+		} else {
+			head = append(head, encoded...)
+		}
+	}
+	return append(head, tail...), nil
+}
+
+func encodeValue(t *abiType, value interface{}) ([]byte, error) {
+	switch t.kind {
+	case "uint", "int":
+		n, err := abiInt(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", t, err)
+		}
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(t.size))
+		if t.kind == "int" {
+			limit.Rsh(limit, 1)
+			if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+				return nil, fmt.Errorf("invalid %s: %s out of range", t, n)
+			}
+			if n.Sign() < 0 {
+				n = new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+			}
+		} else if n.Sign() < 0 || n.Cmp(limit) >= 0 {
+			return nil, fmt.Errorf("invalid %s: %s out of range", t, n)
+		}
+		return word(n), nil
+	case "address":
+		s, ok := value.(string)
+		if !ok || !addressPattern.MatchString(s) {
+			return nil, fmt.Errorf("invalid address: %v", value)
+		}
+		b, _ := hex.DecodeString(s[2:])
+		return leftPad(b), nil
+	case "bool":
+		switch v := value.(type) {
+		case bool:
+			return word(big.NewInt(boolInt(v))), nil
+		case string:
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid bool: %v", value)
+			}
+			return word(big.NewInt(boolInt(b))), nil
+		}
+		return nil, fmt.Errorf("invalid bool: %v", value)
+	case "fixedbytes":
+		b, err := abiBytes(value)
+		if err != nil || len(b) != t.size {
+			return nil, fmt.Errorf("invalid %s: %v", t, value)
+		}
+		return rightPad(b), nil
+	case "bytes", "string":
+		var b []byte
+		if t.kind == "string" {
+			s, ok := value.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid string: %v", value)
+			}
+			b = []byte(s)
+		} else {
+			var err error
+			if b, err = abiBytes(value); err != nil {
+				return nil, fmt.Errorf("invalid bytes: %v", value)
+			}
+		}
+		return append(word(big.NewInt(int64(len(b)))), rightPad(b)...), nil
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid %s: expected an array", t)
+		}
+		if t.length >= 0 && len(items) != t.length {
+			return nil, fmt.Errorf("invalid %s: expected %d items, got %d", t, t.length, len(items))
+		}
+		types := make([]*abiType, len(items))
+		for i := range items {
+			types[i] = t.elem
+		}
+		encoded, err := encodeTuple(types, items)
+		if err != nil {
+			return nil, err
+		}
+		if t.length < 0 {
+			encoded = append(word(big.NewInt(int64(len(items)))), encoded...)
+		}
+		return encoded, nil
+	case "tuple":
+		var fields []interface{}
+		switch v := value.(type) {
+		case []interface{}:
+			fields = v
+		case map[string]interface{}:
+			for _, name := range t.names {
+				fields = append(fields, v[name])
+			}
+		default:
+			return nil, fmt.Errorf("invalid tuple: expected an array or object")
+		}
+		if len(fields) != len(t.fields) {
+			return nil, fmt.Errorf("invalid tuple: expected %d fields, got %d", len(t.fields), len(fields))
+		}
+		return encodeTuple(t.fields, fields)
+	}
+	return nil, fmt.Errorf("unsupported ABI type %s", t)
+}
+
+// abiInt parses an integer given as a JSON number or a decimal or 0x string
+func abiInt(value interface{}) (*big.Int, error) {
+	var s string
+	switch v := value.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	case float64:
+		if v != float64(int64(v)) {
+			return nil, fmt.Errorf("%v is not an integer", v)
+		}
+		return big.NewInt(int64(v)), nil
+	default:
+		return nil, fmt.Errorf("%v is not an integer", value)
+	}
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("%s is not an integer", s)
+	}
+	return n, nil
+}
+
+func abiBytes(value interface{}) ([]byte, error) {
+	s, ok := value.(string)
+	if !ok || !strings.HasPrefix(s, "0x") {
+		return nil, fmt.Errorf("expected a 0x hex string")
+	}
+	return hex.DecodeString(s[2:])
+}
+
+func boolInt(b bool) int64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// word returns n as a 32-byte big-endian word
+func word(n *big.Int) []byte {
+	return n.FillBytes(make([]byte, 32))
+}
+
+func leftPad(b []byte) []byte {
+	return append(make([]byte, 32-len(b)), b...)
+}
+
+func rightPad(b []byte) []byte {
+	if len(b)%32 == 0 {
+		return b
+	}
+	return append(b, make([]byte, 32-len(b)%32)...)
+}
+
+func decodeTuple(types []*abiType, data []byte) ([]interface{}, error) {
+	values := make([]interface{}, len(types))
+	pos := 0
+	for i, t := range types {
+		if pos+32 > len(data) {
+			return nil, fmt.Errorf("ABI data too short for %s", t)
+		}
+		var err error
+		if t.dynamic() {
+			offset := new(big.Int).SetBytes(data[pos : pos+32])
+			if !offset.IsInt64() || offset.Int64() > int64(len(data)) {
+				return nil, fmt.Errorf("invalid ABI offset for %s", t)
+			}
+			values[i], err = decodeValue(t, data[offset.Int64():])
+		} else {
+			values[i], err = decodeValue(t, data[pos:])
+		}
+		if err != nil {
+			return nil, err
+		}
+		pos += t.headSize()
+	}
+	return values, nil
+}
+
+func decodeValue(t *abiType, data []byte) (interface{}, error) {
+	if len(data) < 32 && t.headSize() > 0 {
+		return nil, fmt.Errorf("ABI data too short for %s", t)
+	}
+	switch t.kind {
+	case "uint":
+		return new(big.Int).SetBytes(data[:32]).String(), nil
+	case "int":
+		n := new(big.Int).SetBytes(data[:32])
+		if n.Bit(255) == 1 {
+			n.Sub(n, new(big.Int).Lsh(big.NewInt(1), 256))
+		}
+		return n.String(), nil
+	case "address":
+		return checksumAddress(data[12:32]), nil
+	case "bool":
+		return data[31] != 0, nil
+	case "fixedbytes":
+		return "0x" + hex.EncodeToString(data[:t.size]), nil
+	case "bytes", "string":
+		length := new(big.Int).SetBytes(data[:32])
+		if !length.IsInt64() || length.Int64() > int64(len(data)-32) {
+			return nil, fmt.Errorf("invalid ABI length for %s", t)
+		}
+		content := data[32 : 32+length.Int64()]
+		if t.kind == "string" {
+			return string(content), nil
+		}
+		return "0x" + hex.EncodeToString(content), nil
+	case "array":
+		length := t.length
+		if length < 0 {
+			// Each item takes at least a word after the length, compared
+			// before the conversion so a huge length cannot overflow
+			n := new(big.Int).SetBytes(data[:32])
+			if n.Cmp(big.NewInt(int64(len(data)/32-1))) > 0 {
+				return nil, fmt.Errorf("invalid ABI length for %s", t)
+			}
+			length = int(n.Int64())
+			data = data[32:]
+		}
+		types := make([]*abiType, length)
+		for i := range types {
+			types[i] = t.elem
+		}
+		items, err := decodeTuple(types, data)
+		if err != nil {
+			return nil, err
+		}
+		return items, nil
+	case "tuple":
+		fields, err := decodeTuple(t.fields, data)
+		if err != nil {
+			return nil, err
+		}
+		value := make(map[string]interface{}, len(fields))
+		for i, field := range fields {
+			name := t.names[i]
+			if name == "" {
+				name = strconv.Itoa(i)
+			}
+			value[name] = field
+		}
+		return value, nil
+	}
+	return nil, fmt.Errorf("unsupported ABI type %s", t)
+}
+
+// checksumAddress formats a 20-byte address with the EIP-55 checksum
+func checksumAddress(address []byte) string {
+	lower := hex.EncodeToString(address)
+	hash := hex.EncodeToString(keccak256([]byte(lower)))
+	result := []byte(lower)
+	for i, c := range result {
+		if c >= 'a' && hash[i] >= '8' {
+			result[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(result)
+}
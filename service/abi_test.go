@@ -0,0 +1,248 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testABI = `[
+	{"type": "function", "name": "transfer", "stateMutability": "nonpayable",
+	 "inputs": [{"name": "to", "type": "address"}, {"name": "amount", "type": "uint256"}],
+	 "outputs": [{"name": "", "type": "bool"}]},
+	{"type": "function", "name": "getLockInfos", "stateMutability": "view",
+	 "inputs": [{"name": "holder", "type": "address"}],
+	 "outputs": [{"name": "", "type": "tuple[]", "components": [
+		{"name": "amount", "type": "uint256"},
+		{"name": "unlockAt", "type": "uint256"},
+		{"name": "note", "type": "string"}]}]},
+	{"type": "function", "name": "getAvailableAmount", "stateMutability": "view",
+	 "inputs": [{"name": "holder", "type": "address"}],
+	 "outputs": [{"name": "", "type": "uint256"}, {"name": "", "type": "int256"}]},
+	{"type": "event", "name": "Transfer", "inputs": [
+		{"name": "from", "type": "address", "indexed": true},
+		{"name": "to", "type": "address", "indexed": true},
+		{"name": "value", "type": "uint256"}]}
+]`
+
+func decodeJSON(t *testing.T, content string) interface{} {
+	t.Helper()
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+	var value interface{}
+	require.NoError(t, decoder.Decode(&value))
+	return value
+}
+
+func TestABI(t *testing.T) {
+	var abi ABI
+	require.NoError(t, json.Unmarshal([]byte(testABI), &abi))
+
+	transfer, err := abi.Function("transfer")
+	require.NoError(t, err)
+	assert.Equal(t, "a9059cbb", hex.EncodeToString(transfer.Selector()))
+	assert.False(t, transfer.ReadOnly())
+	assert.Equal(t, "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef", abi[3].Topic())
+
+	data, err := transfer.EncodeCall([]interface{}{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", json.Number("1000")})
+	require.NoError(t, err)
+	assert.Equal(t, "a9059cbb"+
+		"0000000000000000000000005aaeb6053f3e94c9b9a09f33669435e7ef1beaed"+
+		"00000000000000000000000000000000000000000000000000000000000003e8", hex.EncodeToString(data))
+
+	_, err = transfer.EncodeCall([]interface{}{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "-1"})
+	assert.ErrorContains(t, err, "out of range")
+	_, err = transfer.EncodeCall([]interface{}{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"})
+	assert.ErrorContains(t, err, "expects 2 args")
+	_, err = abi.Function("mint")
+	assert.ErrorIs(t, err, ErrABIFunctionNotFound)
+
+	// Round trip a dynamic tuple array through the outputs of getLockInfos
+	lockInfos, err := abi.Function("getLockInfos")
+	require.NoError(t, err)
+	encoder := ABIEntry{Type: "function", Name: "encode", Inputs: lockInfos.Outputs}
+	data, err = encoder.EncodeCall([]interface{}{decodeJSON(t, `[
+		{"amount": 100, "unlockAt": "0x10", "note": "team"},
+		[200, 32, "investors"]
+	]`)})
+	require.NoError(t, err)
+	outputs, err := lockInfos.DecodeOutputs(data[4:])
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+	assert.Equal(t, "tuple[]", outputs[0].Type)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"amount": "100", "unlockAt": "16", "note": "team"},
+		map[string]interface{}{"amount": "200", "unlockAt": "32", "note": "investors"},
+	}, outputs[0].Value)
+
+	available, err := abi.Function("getAvailableAmount")
+	require.NoError(t, err)
+	encoder = ABIEntry{Type: "function", Name: "encode", Inputs: available.Outputs}
+	data, err = encoder.EncodeCall([]interface{}{json.Number("5"), json.Number("-5")})
+	require.NoError(t, err)
+	outputs, err = available.DecodeOutputs(data[4:])
+	require.NoError(t, err)
+	assert.Equal(t, "5", outputs[0].Value)
+	assert.Equal(t, "-5", outputs[1].Value)
+
+	_, err = available.DecodeOutputs(data[4:36])
+	assert.ErrorContains(t, err, "too short")
+
+	// A dynamic array length overflowing the item size is rejected
+	huge, _ := hex.DecodeString("0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000800000000000000")
+	_, err = lockInfos.DecodeOutputs(huge)
+	assert.ErrorContains(t, err, "invalid ABI length")
+
+	address, _ := hex.DecodeString("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+	assert.Equal(t, "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed", checksumAddress(address))
+}
+
+// FuzzDecodeOutputs checks decoding arbitrary return data fails without
+// panicking
+func FuzzDecodeOutputs(f *testing.F) {
+	var abi ABI
+	require.NoError(f, json.Unmarshal([]byte(testABI), &abi))
+	lockInfos, err := abi.Function("getLockInfos")
+	require.NoError(f, err)
+	available, err := abi.Function("getAvailableAmount")
+	require.NoError(f, err)
+
+	encoder := ABIEntry{Type: "function", Name: "encode", Inputs: lockInfos.Outputs}
+	data, err := encoder.EncodeCall([]interface{}{[]interface{}{[]interface{}{json.Number("1"), json.Number("2"), "note"}}})
+	require.NoError(f, err)
+	f.Add(data[4:])
+	f.Add(make([]byte, 64))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		lockInfos.DecodeOutputs(data)
+		available.DecodeOutputs(data)
+	})
+}
+
+// FuzzDecodeLog checks decoding arbitrary event topics and data fails
+// without panicking
+func FuzzDecodeLog(f *testing.F) {
+	var abi ABI
+	require.NoError(f, json.Unmarshal([]byte(testABI), &abi))
+	transfer := &abi[3]
+	event := &ABIEntry{Type: "event", Name: "Noted", Inputs: []ABIParam{
+		{Name: "note", Type: "string", Indexed: true},
+		{Name: "notes", Type: "string[]"},
+	}}
+
+	f.Add("0x0000000000000000000000005aaeb6053f3e94c9b9a09f33669435e7ef1beaed", make([]byte, 32))
+	f.Add("", make([]byte, 96))
+	f.Fuzz(func(t *testing.T, topic string, data []byte) {
+		for _, e := range []*ABIEntry{transfer, event} {
+			e.DecodeLog([]string{e.Topic(), topic, topic}, data)
+			e.DecodeLog([]string{e.Topic(), topic}, data)
+		}
+	})
+}
+
+// TestCallContract calls a view function of a fake node through the ABI of
+// the current build
+func TestCallContract(t *testing.T) {
+	setupTest(t, &FakeExecutor{})
+
+	buildDir := t.TempDir()
+	artifactDir := filepath.Join(buildDir, "out", "Token.sol")
+	require.NoError(t, os.MkdirAll(artifactDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(artifactDir, "Token.json"), []byte(`{"abi": `+testABI+`}`), 0644))
+	originalBuild := builds.current
+	t.Cleanup(func() { builds.current = originalBuild })
+
+	var calls []map[string]interface{}
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req rpcRequest
+		_ = json.Unmarshal(body, &req)
+		tx := req.Params[0].(map[string]interface{})
+		calls = append(calls, tx)
+		if bytes.Contains(body, []byte(`"0x1"`)) {
+			_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "error": {"code": 3, "message": "execution reverted: not started"}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "result": "0x` +
+			"0000000000000000000000000000000000000000000000000000000000000007" +
+			"0000000000000000000000000000000000000000000000000000000000000008" + `"}`))
+	}))
+	t.Cleanup(node.Close)
+	network, err := LookupNetwork("")
+	require.NoError(t, err)
+	originalURL := network.RPCURL
+	network.RPCURL = node.URL
+	t.Cleanup(func() { network.RPCURL = originalURL })
+
+	call := ContractCall{
+		Address:      "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707",
+		ContractType: "token",
+		Function:     "getAvailableAmount",
+		Args:         []interface{}{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+	}
+	_, err = CallContract(context.Background(), call)
+	assert.ErrorIs(t, err, ErrBuildNotReady)
+
+	builds.current = &BuildInfo{Key: "test", Status: BuildReady, dir: buildDir}
+	result, err := CallContract(context.Background(), call)
+	require.NoError(t, err)
+	assert.Equal(t, "Token.sol:Token", result.Artifact)
+	assert.Equal(t, "getAvailableAmount(address)", result.Function)
+	assert.Equal(t, "latest", result.Block)
+	assert.Equal(t, []ABIValue{{Type: "uint256", Value: "7"}, {Type: "int256", Value: "8"}}, result.Outputs)
+	require.Len(t, calls, 1)
+	assert.Equal(t, call.Address, calls[0]["to"])
+
+	call.Block = "1"
+	_, err = CallContract(context.Background(), call)
+	assert.ErrorIs(t, err, ErrCallReverted)
+
+	call.Function, call.Block = "transfer", ""
+	_, err = CallContract(context.Background(), call)
+	assert.ErrorIs(t, err, ErrInvalidCall)
+	assert.ErrorContains(t, err, "not a view function")
+
+	call.ContractType = ""
+	_, err = CallContract(context.Background(), call)
+	assert.ErrorContains(t, err, "contract_type or artifact is required")
+}
+
+// TestLoadABI checks artifacts are only read from the build output, by
+// contract name, file and contract, or recorded artifact path
+func TestLoadABI(t *testing.T) {
+	buildDir := t.TempDir()
+	artifactDir := filepath.Join(buildDir, "out", "Token.sol")
+	require.NoError(t, os.MkdirAll(artifactDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(artifactDir, "Token.json"), []byte(`{"abi": `+testABI+`}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(buildDir, "secret.json"), []byte(`{"abi": []}`), 0644))
+	require.NoError(t, os.Symlink(buildDir, filepath.Join(buildDir, "out", "Link.sol")))
+	originalBuild := builds.current
+	builds.current = &BuildInfo{Key: "test", Status: BuildReady, dir: buildDir}
+	t.Cleanup(func() { builds.current = originalBuild })
+
+	for _, name := range []string{"Token", "Token.sol", "Token.sol:Token", "Token:Token", "out/Token.sol/Token.json"} {
+		abi, err := LoadABI(name)
+		require.NoError(t, err, name)
+		assert.Len(t, abi, 4, name)
+	}
+
+	for _, name := range []string{"../secret", "out/../secret.json", "/etc/passwd", "*", "Tok?n", "[T]oken", "Token.sol:../../secret", ""} {
+		_, err := LoadABI(name)
+		assert.ErrorContains(t, err, "invalid artifact", name)
+	}
+	_, err := LoadABI("Link.sol:secret")
+	assert.ErrorContains(t, err, "outside the build output")
+	_, err = LoadABI("Missing")
+	assert.ErrorContains(t, err, "artifact not found")
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	ActionSuccess = "success"
+	ActionFailed  = "failed"
+	ActionSkipped = "skipped"
+	// ActionDenied records a transaction outside the scope of its signer
+	ActionDenied = "denied"
+)
+
+// Action is an admin call executed against a deployed contract
+type Action struct {
+	Function string   `json:"function" binding:"required" example:"setOracle"`
+	Args     []string `json:"args" example:"0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"`
+}
+
+// ActionResult records the outcome of an executed action or transaction.
+// The recorded results are the audit log of the transactions sent to
+// deployed contracts.
+type ActionResult struct {
+	Contract     string   `json:"contract"`
+	ContractType string   `json:"contract_type"`
+	Network      string   `json:"network"`
+	Function     string   `json:"function"`
+	Args         []string `json:"args"`
+	// Caller and Signer are recorded for transact requests
+	Caller string `json:"caller,omitempty"`
+	Signer string `json:"signer,omitempty"`
+	TxHash string `json:"tx_hash,omitempty"`
+	// BlockNumber, GasUsed and Events are read from the receipt of a
+	// transact request
+	BlockNumber uint64          `json:"block_number,omitempty"`
+	GasUsed     uint64          `json:"gas_used,omitempty"`
+	Events      []ContractEvent `json:"events,omitempty"`
+	Status      string          `json:"status"`
+	Error       string          `json:"error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// ValidateActions checks that every action is declared by the contract type
+// and carries the expected number of arguments.
+func ValidateActions(spec *ContractTypeSpec, actions []Action) error {
+	for _, action := range actions {
+		signature, ok := spec.Actions[action.Function]
+		if !ok {
+			return fmt.Errorf("unsupported action %q for contract type %s", action.Function, spec.Name)
+		}
+		if want := countParams(signature); len(action.Args) != want {
+			return fmt.Errorf("action %s expects %d args, got %d", signature, want, len(action.Args))
+		}
+	}
+	return nil
+}
+
+// ErrActionOwner is returned for post-deploy actions requested with another
+// owner than the deployer
+var ErrActionOwner = errors.New("post-deploy actions would revert")
+
+// ValidateActionOwner rejects post-deploy actions when envVars make another
+// address than the deployer the owner of the contract. The actions are
+// onlyOwner calls sent with the deployer key and would revert.
+func ValidateActionOwner(ctx context.Context, spec *ContractTypeSpec, network *Network, envVars map[string]string, actions []Action) error {
+	owner := envVars[spec.Env["owner"]]
+	if len(actions) == 0 || owner == "" {
+		return nil
+	}
+	deployer, err := cachedDeployerAddress(ctx, network.PrivateKey())
+	if err != nil {
+		return fmt.Errorf("failed to derive deployer address: %v", err)
+	}
+	if !strings.EqualFold(owner, deployer) {
+		return fmt.Errorf("%w for owner %s: they are sent by the deployer %s, deploy without actions or with the deployer as owner", ErrActionOwner, owner, deployer)
+	}
+	return nil
+}
+
+// RunActions executes the actions one by one against contract with the
+// deployer signer. Execution stops at the first failure and the remaining
+// actions are reported as skipped. Every result is recorded in the store.
+func RunActions(ctx context.Context, contract string, spec *ContractTypeSpec, network *Network, actions []Action) ([]ActionResult, error) {
+	if err := ValidateActions(spec, actions); err != nil {
+		return nil, err
+	}
+	defer trackInFlight("actions")()
+
+	var runErr error
+	results := make([]ActionResult, 0, len(actions))
+	for _, action := range actions {
+		result := ActionResult{
+			Contract:     contract,
+			ContractType: spec.Name,
+			Network:      network.Name,
+			Function:     action.Function,
+			Args:         action.Args,
+			CreatedAt:    time.Now(),
+		}
+		if runErr != nil {
+			result.Status = ActionSkipped
+			results = append(results, result)
+			continue
+		}
+
+		result.TxHash, runErr = sendTransaction(ctx, network, contract, spec.Actions[action.Function], action.Args)
+		if runErr != nil {
+			result.Status = ActionFailed
+			result.Error = runErr.Error()
+		} else {
+			result.Status = ActionSuccess
+		}
+		results = append(results, result)
+	}
+
+	recordActions(ctx, contract, results)
+	return results, runErr
+}
+
+// recordActions appends results to the actions recorded against contract
+func recordActions(ctx context.Context, contract string, results []ActionResult) {
+	if store == nil {
+		return
+	}
+	if err := store.AddActions(results); err != nil {
+		Logger(ctx).Error("failed to record actions", "contract", contract, "error", err)
+	}
+}
+
+// sendTransaction sends a transaction calling signature on contract and waits
+// for its receipt, returning the transaction hash.
+func sendTransaction(ctx context.Context, network *Network, contract, signature string, args []string) (string, error) {
+	receipt, err := castSend(ctx, network, network.PrivateKey(), contract, append([]string{signature}, args...))
+	if err != nil {
+		return "", err
+	}
+	return receipt.TransactionHash, receipt.err()
+}
+
+// castReceipt is the receipt printed by cast send --json
+type castReceipt struct {
+	TransactionHash string   `json:"transactionHash"`
+	Status          string   `json:"status"`
+	BlockNumber     string   `json:"blockNumber"`
+	GasUsed         string   `json:"gasUsed"`
+	Logs            []rpcLog `json:"logs"`
+}
+
+// err reports a reverted transaction
+func (r *castReceipt) err() error {
+	if r.Status != "0x1" && r.Status != "1" {
+		return fmt.Errorf("transaction %s reverted", r.TransactionHash)
+	}
+	return nil
+}
+
+// keyEnv is the env variable cast and forge read the signing key from,
+// keeping it out of the argv
+const keyEnv = "ETH_PRIVATE_KEY"
+
+// castSend sends a transaction to contract signed with key and waits for its
+// receipt. call is a function signature followed by its args, or calldata.
+func castSend(ctx context.Context, network *Network, key, contract string, call []string) (*castReceipt, error) {
+	cmdArgs := append([]string{"send", contract}, call...)
+	cmdArgs = append(cmdArgs,
+		"--rpc-url", network.RPCURL,
+		"--legacy",
+		"--json",
+	)
+	cmd := Command{Name: toolPath("cast"), Args: cmdArgs, Env: append(toolchainEnv(), keyEnv+"="+key)}
+
+	Logger(ctx).Info("executing command", "command", cmd.String())
+
+	output, err := executor.Run(ctx, cmd)
+	if err != nil {
+		return nil, fmt.Errorf("cast send error: %v: %s", err, string(output))
+	}
+
+	var receipt castReceipt
+	if err := json.Unmarshal(output, &receipt); err != nil {
+		return nil, fmt.Errorf("failed to parse cast output: %v: %s", err, string(output))
+	}
+	return &receipt, nil
+}
+
+// countParams returns the number of parameters in a flat function signature
+// such as "setAdmin(address,bool)".
+func countParams(signature string) int {
+	start := strings.Index(signature, "(")
+	params := strings.TrimSuffix(signature[start+1:], ")")
+	if params == "" {
+		return 0
+	}
+	return len(strings.Split(params, ","))
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// broadcastRun is the subset of a forge broadcast run-latest.json read after
+// a deployment
+type broadcastRun struct {
+	Transactions []broadcastTransaction `json:"transactions"`
+	Receipts     []struct {
+		TransactionHash   string `json:"transactionHash"`
+		Status            string `json:"status"`
+		BlockNumber       string `json:"blockNumber"`
+		GasUsed           string `json:"gasUsed"`
+		EffectiveGasPrice string `json:"effectiveGasPrice"`
+	} `json:"receipts"`
+	Timestamp int64 `json:"timestamp"`
+	Chain     int64 `json:"chain"`
+}
+
+type broadcastTransaction struct {
+	Hash            string   `json:"hash"`
+	TransactionType string   `json:"transactionType"`
+	ContractName    string   `json:"contractName"`
+	ContractAddress string   `json:"contractAddress"`
+	Arguments       []string `json:"arguments"`
+}
+
+// loadBroadcast reads the run-latest.json the forge script left in dir. It
+// returns an error wrapping os.ErrNotExist when the script never wrote one.
+func loadBroadcast(dir, script string) (*broadcastRun, error) {
+	script = filepath.Base(strings.SplitN(script, ":", 2)[0])
+	files, _ := filepath.Glob(filepath.Join(dir, "broadcast", script, "*", "run-latest.json"))
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no broadcast found for %s: %w", script, os.ErrNotExist)
+	}
+	content, err := os.ReadFile(files[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read broadcast: %v", err)
+	}
+	var run broadcastRun
+	if err := json.Unmarshal(content, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse broadcast: %v. path: %v", err, files[0])
+	}
+	return &run, nil
+}
+
+// readBroadcast fills in the implementation, contract name, ABI artifact,
+// transaction hash and block number of deployment from the broadcast and
+// build output the forge script left in dir
+func readBroadcast(dir string, spec *ContractTypeSpec, deployment *Deployment) error {
+	run, err := loadBroadcast(dir, spec.Script)
+	if err != nil {
+		return err
+	}
+
+	var proxyTx *broadcastTransaction
+	for i, tx := range run.Transactions {
+		if tx.TransactionType == "CREATE" && strings.EqualFold(tx.ContractAddress, deployment.ProxyAddress) {
+			proxyTx = &run.Transactions[i]
+		}
+	}
+	if proxyTx == nil {
+		return fmt.Errorf("proxy %s not found in broadcast", deployment.ProxyAddress)
+	}
+	deployment.TxHash = proxyTx.Hash
+	fee := new(big.Int)
+	for _, receipt := range run.Receipts {
+		gasUsed, _ := new(big.Int).SetString(strings.TrimPrefix(receipt.GasUsed, "0x"), 16)
+		gasPrice, _ := new(big.Int).SetString(strings.TrimPrefix(receipt.EffectiveGasPrice, "0x"), 16)
+		if gasUsed != nil {
+			deployment.GasUsed += gasUsed.Uint64()
+			if gasPrice != nil {
+				fee.Add(fee, gasUsed.Mul(gasUsed, gasPrice))
+			}
+		}
+		if receipt.TransactionHash == proxyTx.Hash {
+			block, err := parseHexInt(receipt.BlockNumber)
+			if err != nil {
+				return fmt.Errorf("invalid block number %q: %v", receipt.BlockNumber, err)
+			}
+			deployment.BlockNumber = uint64(block)
+		}
+	}
+
+	deployment.Fee = fee.String()
+	observeGas(deployment.Network, deployment.GasUsed, fee)
+
+	// The implementation is the first constructor argument of ERC1967Proxy
+	if len(proxyTx.Arguments) == 0 {
+		return nil
+	}
+	deployment.ImplementationAddress = proxyTx.Arguments[0]
+	for _, tx := range run.Transactions {
+		if tx.TransactionType == "CREATE" && strings.EqualFold(tx.ContractAddress, deployment.ImplementationAddress) {
+			deployment.ContractName = tx.ContractName
+		}
+	}
+	if deployment.ContractName == "" {
+		return nil
+	}
+	artifacts, _ := filepath.Glob(filepath.Join(dir, "out", "*", deployment.ContractName+".json"))
+	if len(artifacts) > 0 {
+		deployment.ABI, _ = filepath.Rel(dir, artifacts[0])
+	}
+	return nil
+}
@@ -0,0 +1,395 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	BuildBuilding = "building"
+	BuildReady    = "ready"
+	BuildFailed   = "failed"
+)
+
+// buildArtifactDirs are the forge outputs kept in the cache
+var buildArtifactDirs = []string{"out", "cache"}
+
+// ErrBuildNotReady is returned while no compiled artifacts are available
+var ErrBuildNotReady = errors.New("contracts build is not ready")
+
+// DefaultBuildCacheDir is the build cache unless paths.build_cache is set
+const DefaultBuildCacheDir = "./data/build-cache"
+
+var (
+	// BuildCacheDir holds the compiled artifacts keyed by source hash
+	BuildCacheDir = DefaultBuildCacheDir
+
+	builds = &buildCache{}
+)
+
+// BuildInfo describes a cached build of the contracts tree
+type BuildInfo struct {
+	Key        string    `json:"key"`
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+
+	dir  string
+	done chan struct{}
+}
+
+type buildCache struct {
+	mu      sync.Mutex
+	current *BuildInfo
+	// readers counts the readers of each build dir, which is only removed
+	// once it is neither current nor read
+	readers map[string]int
+}
+
+// acquireBuild marks the dir of build as read, reporting false when build is
+// no longer the ready current build. The caller must call releaseBuild.
+func acquireBuild(build *BuildInfo) bool {
+	builds.mu.Lock()
+	defer builds.mu.Unlock()
+	if build == nil || build != builds.current || build.Status != BuildReady {
+		return false
+	}
+	if builds.readers == nil {
+		builds.readers = map[string]int{}
+	}
+	builds.readers[build.dir]++
+	return true
+}
+
+// releaseBuild ends a read of the dir of build, removing the dir when a
+// newer build replaced it meanwhile
+func releaseBuild(build *BuildInfo) {
+	builds.mu.Lock()
+	defer builds.mu.Unlock()
+	builds.readers[build.dir]--
+	if builds.readers[build.dir] > 0 {
+		return
+	}
+	delete(builds.readers, build.dir)
+	if builds.current != build {
+		_ = os.RemoveAll(build.dir)
+	}
+}
+
+// CurrentBuild returns the latest build, nil before the first one
+func CurrentBuild() *BuildInfo {
+	builds.mu.Lock()
+	defer builds.mu.Unlock()
+	if builds.current == nil {
+		return nil
+	}
+	info := *builds.current
+	return &info
+}
+
+var (
+	// artifactNamePattern matches a contract name, optionally prefixed by
+	// its file as in "Token.sol:Token"
+	artifactNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+(\.sol)?(:[A-Za-z0-9_]+)?$`)
+	// artifactPathPattern matches an artifact path recorded with a deployment
+	artifactPathPattern = regexp.MustCompile(`^out/[A-Za-z0-9_]+\.sol/[A-Za-z0-9_]+\.json$`)
+)
+
+// LoadABI returns the ABI of the compiled contract name from the current
+// build. name is a contract name, "File.sol:Contract", or an artifact path
+// relative to the build such as out/Token.sol/Token.json.
+func LoadABI(name string) (ABI, error) {
+	builds.mu.Lock()
+	build := builds.current
+	builds.mu.Unlock()
+	if !acquireBuild(build) {
+		return nil, ErrBuildNotReady
+	}
+	defer releaseBuild(build)
+
+	out := filepath.Join(build.dir, "out")
+	var pattern string
+	switch {
+	case artifactPathPattern.MatchString(name):
+		pattern = filepath.Join(build.dir, filepath.FromSlash(name))
+	case artifactNamePattern.MatchString(name):
+		file, contract, ok := strings.Cut(name, ":")
+		if !strings.HasSuffix(file, ".sol") {
+			file += ".sol"
+		}
+		switch {
+		case ok:
+			pattern = filepath.Join(out, file, contract+".json")
+		case strings.HasSuffix(name, ".sol"):
+			pattern = filepath.Join(out, file, strings.TrimSuffix(file, ".sol")+".json")
+		default:
+			pattern = filepath.Join(out, "*", name+".json")
+		}
+	default:
+		return nil, fmt.Errorf("invalid artifact %q, expected Contract, File.sol:Contract or out/File.sol/Contract.json", name)
+	}
+	artifacts, _ := filepath.Glob(pattern)
+	switch len(artifacts) {
+	case 0:
+		return nil, fmt.Errorf("artifact not found: %s", name)
+	case 1:
+	default:
+		return nil, fmt.Errorf("artifact %s is ambiguous, use File.sol:%s", name, name)
+	}
+	if !withinDir(out, artifacts[0]) {
+		return nil, fmt.Errorf("artifact %s is outside the build output", name)
+	}
+
+	content, err := os.ReadFile(artifacts[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to read artifact %s: %v", name, err)
+	}
+	var artifact struct {
+		ABI ABI `json:"abi"`
+	}
+	if err := json.Unmarshal(content, &artifact); err != nil {
+		return nil, fmt.Errorf("invalid artifact %s: %v", name, err)
+	}
+	return artifact.ABI, nil
+}
+
+// withinDir reports whether path, with its symlinks resolved, is inside dir
+func withinDir(dir, path string) bool {
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return false
+	}
+	path, err = filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// StartBuild compiles the contracts tree into the cache in the background
+// unless the cache is already up to date or a build is running. force
+// rebuilds even when the cache is up to date.
+func StartBuild(contractPath string, force bool) (*BuildInfo, error) {
+	key, err := buildKey(contractPath)
+	if err != nil {
+		return nil, err
+	}
+
+	builds.mu.Lock()
+	defer builds.mu.Unlock()
+	if current := builds.current; current != nil {
+		if current.Status == BuildBuilding {
+			info := *current
+			return &info, nil
+		}
+		if current.Key == key && current.Status == BuildReady && !force {
+			info := *current
+			return &info, nil
+		}
+	}
+
+	// Each build compiles into its own dir, so the artifacts of the previous
+	// one stay in place for their readers
+	now := time.Now()
+	build := &BuildInfo{
+		Key:       key,
+		Status:    BuildBuilding,
+		StartedAt: now,
+		dir:       filepath.Join(BuildCacheDir, fmt.Sprintf("%s-%d", key, now.UnixNano())),
+		done:      make(chan struct{}),
+	}
+	builds.current = build
+	go runBuild(contractPath, build)
+
+	info := *build
+	return &info, nil
+}
+
+func runBuild(contractPath string, build *BuildInfo) {
+	ctx := WithLogger(context.Background(), slog.With("build", build.Key))
+	err := compile(ctx, contractPath, build.dir)
+
+	builds.mu.Lock()
+	build.FinishedAt = time.Now()
+	if err != nil {
+		build.Status = BuildFailed
+		build.Error = err.Error()
+		slog.Error("build failed", "build", build.Key, "error", err)
+	} else {
+		build.Status = BuildReady
+		slog.Info("build ready", "build", build.Key, "duration", build.FinishedAt.Sub(build.StartedAt).Round(time.Second).String())
+	}
+	close(build.done)
+	builds.mu.Unlock()
+
+	if err == nil {
+		pruneBuildCache()
+	}
+}
+
+// compile runs forge build in a fresh workspace and moves the artifacts to
+// dir, which must not exist yet
+func compile(ctx context.Context, contractPath, dir string) (err error) {
+	ws, err := NewWorkspace(ctx, contractPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ws.Cleanup(err != nil)
+	}()
+
+	forgeLog := newLineLogger(ctx, "forge")
+	cmd := Command{Name: toolPath("forge"), Args: []string{"build"}, Dir: ws.Dir, Env: toolchainEnv(), Output: forgeLog}
+	Logger(ctx).Info("executing command", "command", cmd.String())
+	output, err := executor.Run(ctx, cmd)
+	forgeLog.Flush()
+	if err != nil {
+		return fmt.Errorf("forge build error: %v: %s", err, lastLines(string(output), 20))
+	}
+
+	tmp := dir + ".tmp"
+	_ = os.RemoveAll(tmp)
+	if err := os.MkdirAll(tmp, 0755); err != nil {
+		return fmt.Errorf("failed to create build cache dir: %v", err)
+	}
+	for _, name := range buildArtifactDirs {
+		if err := os.Rename(filepath.Join(ws.Dir, name), filepath.Join(tmp, name)); err != nil {
+			return fmt.Errorf("failed to move %s to build cache: %v", name, err)
+		}
+	}
+	return os.Rename(tmp, dir)
+}
+
+// restoreArtifacts copies the cached artifacts into the workspace when they
+// match the current sources, waiting for a running build first. A stale
+// cache triggers a rebuild in the background and forge compiles in the
+// workspace instead.
+func restoreArtifacts(ctx context.Context, contractPath string, ws *Workspace) bool {
+	builds.mu.Lock()
+	build := builds.current
+	builds.mu.Unlock()
+	if build == nil {
+		return false
+	}
+
+	select {
+	case <-build.done:
+	case <-ctx.Done():
+		return false
+	}
+
+	key, err := buildKey(contractPath)
+	if err != nil {
+		Logger(ctx).Error("failed to compute build key", "error", err)
+		return false
+	}
+	if build.Status != BuildReady || build.Key != key {
+		if _, err := StartBuild(contractPath, false); err != nil {
+			Logger(ctx).Error("failed to start build", "error", err)
+		}
+		return false
+	}
+	if !acquireBuild(build) {
+		return false
+	}
+	defer releaseBuild(build)
+
+	for _, name := range buildArtifactDirs {
+		if err := copyPath(filepath.Join(build.dir, name), filepath.Join(ws.Dir, name)); err != nil {
+			Logger(ctx).Error("failed to restore cached artifacts", "dir", name, "error", err)
+			for _, name := range buildArtifactDirs {
+				_ = os.RemoveAll(filepath.Join(ws.Dir, name))
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// buildKey hashes the compiler inputs of the contracts tree: the sources,
+// foundry.toml and remappings, the toolchain versions, and the size and
+// modification time of the shared library files.
+func buildKey(contractPath string) (string, error) {
+	root, err := filepath.Abs(contractPath)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	if toolchain != nil {
+		fmt.Fprintf(h, "forge=%s\nsolc=%s\nimage=%s\n", toolchain.ForgeVersion, toolchain.SolcVersion, toolchain.Image)
+	}
+
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		top := strings.SplitN(rel, string(filepath.Separator), 2)[0]
+		if workspaceSkip[top] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+
+		if workspaceLinks[top] {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(h, "%s %d %d\n", rel, info.Size(), info.ModTime().UnixNano())
+			return nil
+		}
+		fmt.Fprintf(h, "%s\n", rel)
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(h, f)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to hash contracts tree: %v", err)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:16], nil
+}
+
+// pruneBuildCache removes the cached builds other than the current one,
+// leaving those still read to releaseBuild
+func pruneBuildCache() {
+	builds.mu.Lock()
+	defer builds.mu.Unlock()
+	entries, err := os.ReadDir(BuildCacheDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		dir := filepath.Join(BuildCacheDir, entry.Name())
+		if builds.current != nil && (dir == builds.current.dir || dir == builds.current.dir+".tmp") {
+			continue
+		}
+		if builds.readers[dir] > 0 {
+			continue
+		}
+		_ = os.RemoveAll(dir)
+	}
+}
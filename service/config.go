@@ -0,0 +1,669 @@
+package service
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultConfigPath is read when present and no path is given
+	DefaultConfigPath = "./config.yaml"
+
+	// DeployerSigner names the deployer key in transact requests
+	DeployerSigner = "deployer"
+
+	DefaultAdminUsername = "admin"
+	DefaultAdminPassword = "admin123"
+
+	// redactedValue replaces the secrets of a printed config
+	redactedValue = "***"
+)
+
+// Config is the configuration of the service. It is read from a YAML file
+// and every field tagged env is then overridden by that environment
+// variable when set, so a .env written for earlier versions keeps working.
+// The OpenTelemetry exporter stays configured by the standard OTEL_*
+// variables.
+type Config struct {
+	// Env is dev or prod
+	Env string `yaml:"env" env:"APP_ENV"`
+	// Listen is the address of the HTTP server
+	Listen       string             `yaml:"listen" env:"LISTEN_ADDR"`
+	Paths        PathsConfig        `yaml:"paths"`
+	Networks     []NetworkConfig    `yaml:"networks"`
+	Signer       SignerConfig       `yaml:"signer"`
+	Auth         AuthConfig         `yaml:"auth"`
+	Limits       LimitsConfig       `yaml:"limits"`
+	Runner       RunnerConfig       `yaml:"runner"`
+	Toolchain    ToolchainConfig    `yaml:"toolchain"`
+	LocalNetwork LocalNetworkConfig `yaml:"local_network"`
+	Indexer      IndexerConfig      `yaml:"indexer"`
+	Webhooks     WebhooksConfig     `yaml:"webhooks"`
+	Logging      LoggingConfig      `yaml:"logging"`
+	Features     FeaturesConfig     `yaml:"features"`
+
+	// path is the config file read by LoadConfig, read again on reload
+	path string
+}
+
+type PathsConfig struct {
+	Contracts     string `yaml:"contracts" env:"CONTRACT_PATH"`
+	ContractTypes string `yaml:"contract_types" env:"CONTRACT_TYPES_PATH"`
+	Store         string `yaml:"store" env:"STORE_PATH"`
+	BuildCache    string `yaml:"build_cache" env:"BUILD_CACHE_DIR"`
+	Workspaces    string `yaml:"workspaces" env:"WORKSPACE_ROOT"`
+	JobLogs       string `yaml:"job_logs" env:"JOB_LOG_DIR"`
+}
+
+// NetworkConfig is a chain contracts can be deployed to, see Network
+type NetworkConfig struct {
+	Name        string            `yaml:"name"`
+	RPCURL      string            `yaml:"rpc_url"`
+	ChainID     int64             `yaml:"chain_id"`
+	VerifierURL string            `yaml:"verifier_url,omitempty"`
+	Constants   map[string]string `yaml:"constants,omitempty"`
+	// PrivateKey overrides the signer key on this network
+	PrivateKey string `yaml:"private_key,omitempty" secret:"true"`
+}
+
+// SignerConfig is the deployer key used on networks without their own, and
+// the further keys the transact API can sign with
+type SignerConfig struct {
+	PrivateKey string `yaml:"private_key" env:"PRIVATE_KEY" secret:"true"`
+	// Scope restricts the transactions sent with the deployer key through
+	// the transact API
+	Scope SignerScope `yaml:"scope,omitempty"`
+	// Keys are selected by name in transact requests
+	Keys []SignerKeyConfig `yaml:"keys,omitempty"`
+}
+
+// SignerKeyConfig is a named key of the transact API
+type SignerKeyConfig struct {
+	Name       string      `yaml:"name"`
+	PrivateKey string      `yaml:"private_key" secret:"true"`
+	Scope      SignerScope `yaml:"scope,omitempty"`
+}
+
+// SignerScope restricts the transactions a signer sends through the
+// transact API. A signer may only call the listed functions, on any network
+// and contract type unless those are listed too.
+type SignerScope struct {
+	Networks      []string `yaml:"networks,omitempty"`
+	ContractTypes []string `yaml:"contract_types,omitempty"`
+	// Functions are names, or signatures of overloaded functions
+	Functions []string `yaml:"functions,omitempty"`
+}
+
+// AuthConfig is the basic auth account of the API
+type AuthConfig struct {
+	Username string `yaml:"username" env:"ADMIN_USERNAME"`
+	Password string `yaml:"password" env:"ADMIN_PASSWORD" secret:"true"`
+}
+
+type LimitsConfig struct {
+	// MaxQueueDepth is the number of jobs waiting behind the running one
+	MaxQueueDepth int `yaml:"max_queue_depth" env:"MAX_QUEUE_DEPTH"`
+	// ShutdownTimeout is how long a shutdown waits for the running job
+	ShutdownTimeout Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT"`
+	// MinDeployerBalance is the deployer balance in wei below which the
+	// service reports not ready
+	MinDeployerBalance string `yaml:"min_deployer_balance" env:"MIN_DEPLOYER_BALANCE"`
+}
+
+// RunnerConfig selects the executor of forge and make, see ContainerExecutor
+type RunnerConfig struct {
+	// Kind is host, docker or podman
+	Kind    string   `yaml:"kind" env:"RUNNER"`
+	Image   string   `yaml:"image" env:"RUNNER_IMAGE"`
+	CPUs    string   `yaml:"cpus" env:"RUNNER_CPUS"`
+	Memory  string   `yaml:"memory" env:"RUNNER_MEMORY"`
+	Timeout Duration `yaml:"timeout" env:"RUNNER_TIMEOUT"`
+	Network string   `yaml:"network" env:"RUNNER_NETWORK"`
+}
+
+// ToolchainConfig overrides the discovered binaries and pins their versions.
+// Empty binaries are looked up in PATH and the default install dirs.
+type ToolchainConfig struct {
+	Forge        string `yaml:"forge" env:"FORGE_BIN"`
+	Cast         string `yaml:"cast" env:"CAST_BIN"`
+	Make         string `yaml:"make" env:"MAKE_BIN"`
+	Node         string `yaml:"node" env:"NODE_BIN"`
+	Solc         string `yaml:"solc" env:"SOLC_BIN"`
+	Anvil        string `yaml:"anvil" env:"ANVIL_BIN"`
+	ForgeVersion string `yaml:"forge_version" env:"FORGE_VERSION"`
+	NodeVersion  string `yaml:"node_version" env:"NODE_VERSION"`
+}
+
+// LocalNetworkConfig configures the anvil network started at startup
+type LocalNetworkConfig struct {
+	Enabled bool   `yaml:"enabled" env:"LOCAL_NETWORK"`
+	RPCURL  string `yaml:"rpc_url" env:"LOCAL_RPC_URL"`
+	// PrivateKey overrides the first anvil account
+	PrivateKey string `yaml:"private_key" env:"LOCAL_PRIVATE_KEY" secret:"true"`
+}
+
+// IndexerConfig configures the indexer of the events of deployed contracts
+type IndexerConfig struct {
+	Enabled bool `yaml:"enabled" env:"INDEXER_ENABLED"`
+	// PollInterval is how often the networks are checked for new blocks
+	PollInterval Duration `yaml:"poll_interval" env:"INDEXER_POLL_INTERVAL"`
+	// BatchBlocks bounds the block range of one eth_getLogs request
+	BatchBlocks int `yaml:"batch_blocks" env:"INDEXER_BATCH_BLOCKS"`
+	// ReorgDepth is the number of indexed heads kept to find where a reorg
+	// forked; a deeper reorg reindexes the network
+	ReorgDepth int `yaml:"reorg_depth" env:"INDEXER_REORG_DEPTH"`
+}
+
+// WebhooksConfig configures the notifications of contract lifecycle events,
+// checked on each indexer poll
+type WebhooksConfig struct {
+	// URL receives each event as a JSON POST, none are sent when empty
+	URL string `yaml:"url" env:"WEBHOOK_URL"`
+	// SigningKey signs the body with HMAC-SHA256 in the X-Signature-256
+	// header
+	SigningKey string   `yaml:"signing_key" env:"WEBHOOK_SIGNING_KEY" secret:"true"`
+	Timeout    Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT"`
+}
+
+type LoggingConfig struct {
+	// Level is debug, info, warn or error
+	Level string `yaml:"level" env:"LOG_LEVEL"`
+	// Format is json or text
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+	// Output is stderr, stdout or a file path
+	Output string `yaml:"output" env:"LOG_OUTPUT"`
+}
+
+type FeaturesConfig struct {
+	Swagger bool `yaml:"swagger" env:"SWAGGER_ENABLED"`
+	Metrics bool `yaml:"metrics" env:"METRICS_ENABLED"`
+	// LegacyRoutes serves the API on its unversioned paths as well, with
+	// the real status only in the response body
+	LegacyRoutes bool `yaml:"legacy_routes" env:"LEGACY_ROUTES"`
+	// KeepFailedWorkspaces keeps the workspace of a failed deployment for
+	// inspection
+	KeepFailedWorkspaces bool `yaml:"keep_failed_workspaces" env:"KEEP_FAILED_WORKSPACES"`
+}
+
+// Duration is a time.Duration written as "10m" in the config
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	value, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(value)
+	return nil
+}
+
+var (
+	// configMu guards activeConfig and the values applied from it
+	configMu sync.RWMutex
+	// activeConfig is the configuration the service runs with
+	activeConfig = DefaultConfig()
+	// reloadMu serializes reloads
+	reloadMu sync.Mutex
+)
+
+// reloadableSections are the config sections a reload applies; changes to
+// the others take effect on the next start
+var reloadableSections = map[string]bool{
+	"networks": true,
+	"signer":   true,
+	"auth":     true,
+	"limits":   true,
+	"webhooks": true,
+}
+
+// CurrentConfig returns the active configuration, which must not be
+// modified
+func CurrentConfig() *Config {
+	configMu.RLock()
+	defer configMu.RUnlock()
+	return activeConfig
+}
+
+// DefaultConfig returns the configuration used when neither the config file
+// nor the environment set a value
+func DefaultConfig() *Config {
+	return &Config{
+		Env:    "dev",
+		Listen: "0.0.0.0:8070",
+		Paths: PathsConfig{
+			Contracts:     DefaultContractPath,
+			ContractTypes: DefaultContractTypesPath,
+			Store:         DefaultStorePath,
+			BuildCache:    DefaultBuildCacheDir,
+			Workspaces:    defaultWorkspaceRoot,
+			JobLogs:       DefaultJobLogDir,
+		},
+		Networks: []NetworkConfig{{
+			Name:        DefaultNetwork,
+			RPCURL:      DBC_MAINNET,
+			ChainID:     DBC_MAINNET_CHAIN_ID,
+			VerifierURL: MAIN_NET_VERIFIER_URL,
+		}},
+		Auth: AuthConfig{
+			Username: DefaultAdminUsername,
+			Password: DefaultAdminPassword,
+		},
+		Limits: LimitsConfig{
+			MaxQueueDepth:      DefaultMaxQueueDepth,
+			ShutdownTimeout:    Duration(DefaultShutdownTimeout),
+			MinDeployerBalance: DefaultMinDeployerBalance,
+		},
+		Runner: RunnerConfig{
+			Kind:    "host",
+			CPUs:    "2",
+			Memory:  "4g",
+			Timeout: Duration(30 * time.Minute),
+		},
+		LocalNetwork: LocalNetworkConfig{
+			RPCURL: DefaultLocalRPCURL,
+		},
+		Indexer: IndexerConfig{
+			Enabled:      true,
+			PollInterval: Duration(15 * time.Second),
+			BatchBlocks:  2000,
+			ReorgDepth:   64,
+		},
+		Webhooks: WebhooksConfig{
+			Timeout: Duration(10 * time.Second),
+		},
+		Logging: LoggingConfig{
+			Level:  "info",
+			Format: "json",
+			Output: "stderr",
+		},
+		Features: FeaturesConfig{
+			Swagger:      true,
+			Metrics:      true,
+			LegacyRoutes: true,
+		},
+	}
+}
+
+// LoadConfig reads the config file at path over the defaults and applies
+// the environment overrides. Without a path, CONFIG_PATH or else
+// DefaultConfigPath is read, the latter only when it exists. The result is
+// not validated.
+func LoadConfig(path string) (*Config, error) {
+	cfg := DefaultConfig()
+
+	if path == "" {
+		path = os.Getenv("CONFIG_PATH")
+	}
+	cfg.path = path
+	optional := path == ""
+	if optional {
+		path = DefaultConfigPath
+	}
+	file, err := os.Open(path)
+	switch {
+	case err == nil:
+		defer file.Close()
+		decoder := yaml.NewDecoder(file)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(cfg); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("failed to parse config %s: %v", path, err)
+		}
+	case optional && errors.Is(err, os.ErrNotExist):
+	default:
+		return nil, fmt.Errorf("failed to read config: %v", err)
+	}
+
+	if err := applyEnv(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv sets the fields of v tagged env whose variable is not empty
+func applyEnv(v reflect.Value) error {
+	for i := 0; i < v.NumField(); i++ {
+		field, value := v.Type().Field(i), v.Field(i)
+		key := field.Tag.Get("env")
+		if key == "" {
+			if value.Kind() == reflect.Struct {
+				if err := applyEnv(value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		raw := os.Getenv(key)
+		if raw == "" {
+			continue
+		}
+		if err := setField(value, raw); err != nil {
+			return fmt.Errorf("invalid %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func setField(value reflect.Value, raw string) error {
+	if unmarshaler, ok := value.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText([]byte(raw))
+	}
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		value.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(n))
+	default:
+		return fmt.Errorf("unsupported type %s", value.Type())
+	}
+	return nil
+}
+
+var privateKeyPattern = regexp.MustCompile(`^(0x)?[0-9a-fA-F]{64}$`)
+
+// Validate reports every invalid value of the config
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Env != "dev" && c.Env != "prod" {
+		fail("env: must be dev or prod, got %q", c.Env)
+	}
+	if _, _, err := net.SplitHostPort(c.Listen); err != nil {
+		fail("listen: %v", err)
+	}
+
+	for key, path := range map[string]string{
+		"contracts":      c.Paths.Contracts,
+		"contract_types": c.Paths.ContractTypes,
+		"store":          c.Paths.Store,
+		"build_cache":    c.Paths.BuildCache,
+		"workspaces":     c.Paths.Workspaces,
+		"job_logs":       c.Paths.JobLogs,
+	} {
+		if path == "" {
+			fail("paths.%s: is required", key)
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, network := range c.Networks {
+		prefix := fmt.Sprintf("networks[%d]", i)
+		name := strings.ToLower(network.Name)
+		switch {
+		case name == "":
+			fail("%s.name: is required", prefix)
+		case seen[name]:
+			fail("%s.name: duplicate network %s", prefix, network.Name)
+		case name == LocalNetwork:
+			fail("%s.name: %s is reserved for the local network", prefix, LocalNetwork)
+		}
+		seen[name] = true
+		if err := validateURL(network.RPCURL); err != nil {
+			fail("%s.rpc_url: %v", prefix, err)
+		}
+		if network.ChainID <= 0 {
+			fail("%s.chain_id: must be positive", prefix)
+		}
+		if network.VerifierURL != "" {
+			if err := validateURL(network.VerifierURL); err != nil {
+				fail("%s.verifier_url: %v", prefix, err)
+			}
+		}
+		if network.PrivateKey != "" && !privateKeyPattern.MatchString(network.PrivateKey) {
+			fail("%s.private_key: not a hex private key", prefix)
+		}
+	}
+	if !seen[DefaultNetwork] {
+		fail("networks: the default network %s is required", DefaultNetwork)
+	}
+	if c.Signer.PrivateKey != "" && !privateKeyPattern.MatchString(c.Signer.PrivateKey) {
+		fail("signer.private_key: not a hex private key")
+	}
+	signers := map[string]bool{}
+	for i, key := range c.Signer.Keys {
+		prefix := fmt.Sprintf("signer.keys[%d]", i)
+		name := strings.ToLower(key.Name)
+		switch {
+		case name == "":
+			fail("%s.name: is required", prefix)
+		case signers[name]:
+			fail("%s.name: duplicate signer %s", prefix, key.Name)
+		case name == DeployerSigner:
+			fail("%s.name: %s is reserved for the deployer key", prefix, DeployerSigner)
+		}
+		signers[name] = true
+		if !privateKeyPattern.MatchString(key.PrivateKey) {
+			fail("%s.private_key: not a hex private key", prefix)
+		}
+	}
+
+	if c.Auth.Username == "" {
+		fail("auth.username: is required")
+	}
+	if c.Auth.Password == "" {
+		fail("auth.password: is required")
+	}
+
+	if c.Limits.MaxQueueDepth < 1 {
+		fail("limits.max_queue_depth: must be at least 1")
+	}
+	if c.Limits.ShutdownTimeout <= 0 {
+		fail("limits.shutdown_timeout: must be positive")
+	}
+	if balance, ok := new(big.Int).SetString(c.Limits.MinDeployerBalance, 10); !ok || balance.Sign() < 0 {
+		fail("limits.min_deployer_balance: must be an amount in wei, got %q", c.Limits.MinDeployerBalance)
+	}
+
+	switch c.Runner.Kind {
+	case "host":
+	case "docker", "podman":
+		if c.Runner.Image == "" {
+			fail("runner.image: is required for the %s runner", c.Runner.Kind)
+		}
+	default:
+		fail("runner.kind: must be host, docker or podman, got %q", c.Runner.Kind)
+	}
+	if c.Runner.Timeout < 0 {
+		fail("runner.timeout: must not be negative")
+	}
+
+	if c.LocalNetwork.Enabled {
+		if err := validateURL(c.LocalNetwork.RPCURL); err != nil {
+			fail("local_network.rpc_url: %v", err)
+		}
+	}
+	if c.LocalNetwork.PrivateKey != "" && !privateKeyPattern.MatchString(c.LocalNetwork.PrivateKey) {
+		fail("local_network.private_key: not a hex private key")
+	}
+
+	if c.Indexer.PollInterval <= 0 {
+		fail("indexer.poll_interval: must be positive")
+	}
+	if c.Indexer.BatchBlocks < 1 {
+		fail("indexer.batch_blocks: must be at least 1")
+	}
+	if c.Indexer.ReorgDepth < 1 {
+		fail("indexer.reorg_depth: must be at least 1")
+	}
+
+	if c.Webhooks.URL != "" {
+		if err := validateURL(c.Webhooks.URL); err != nil {
+			fail("webhooks.url: %v", err)
+		}
+		if !c.Indexer.Enabled {
+			fail("webhooks.url: requires indexer.enabled, the events are checked on each indexer poll")
+		}
+	}
+	if c.Webhooks.Timeout <= 0 {
+		fail("webhooks.timeout: must be positive")
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Logging.Level)); err != nil {
+		fail("logging.level: must be debug, info, warn or error, got %q", c.Logging.Level)
+	}
+	if format := strings.ToLower(c.Logging.Format); format != "json" && format != "text" {
+		fail("logging.format: must be json or text, got %q", c.Logging.Format)
+	}
+	if c.Logging.Output == "" {
+		fail("logging.output: is required")
+	}
+
+	return errors.Join(errs...)
+}
+
+func validateURL(value string) error {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("must be an http(s) URL, got %q", value)
+	}
+	return nil
+}
+
+// Redacted returns a copy of the config with its secrets masked
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.Networks = append([]NetworkConfig(nil), c.Networks...)
+	redacted.Signer.Keys = append([]SignerKeyConfig(nil), c.Signer.Keys...)
+	redact(reflect.ValueOf(&redacted).Elem())
+	return &redacted
+}
+
+// redact masks the non-empty string fields of v tagged secret
+func redact(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			field, value := v.Type().Field(i), v.Field(i)
+			if field.Tag.Get("secret") == "true" && value.Kind() == reflect.String {
+				if value.String() != "" {
+					value.SetString(redactedValue)
+				}
+				continue
+			}
+			redact(value)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			redact(v.Index(i))
+		}
+	}
+}
+
+// ApplyConfig installs cfg as the active configuration without starting
+// the service. Init applies its config itself.
+func ApplyConfig(cfg *Config) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	activeConfig = cfg
+
+	ContractTypesPath = cfg.Paths.ContractTypes
+	StorePath = cfg.Paths.Store
+	BuildCacheDir = cfg.Paths.BuildCache
+	WorkspaceRoot = cfg.Paths.Workspaces
+	JobLogDir = cfg.Paths.JobLogs
+	KeepFailedWorkspaces = cfg.Features.KeepFailedWorkspaces
+
+	local := networks[LocalNetwork]
+	networks = make(map[string]*Network, len(cfg.Networks)+1)
+	if local != nil {
+		networks[LocalNetwork] = local
+	}
+	for _, network := range cfg.Networks {
+		name := strings.ToLower(network.Name)
+		networks[name] = &Network{
+			Name:        name,
+			RPCURL:      network.RPCURL,
+			ChainID:     network.ChainID,
+			VerifierURL: network.VerifierURL,
+			Constants:   network.Constants,
+			privateKey:  network.PrivateKey,
+		}
+	}
+}
+
+// ConfigReload reports what a reload changed
+type ConfigReload struct {
+	// Applied lists the changed sections now in effect
+	Applied []string `json:"applied"`
+	// RestartRequired lists the changed sections ignored until the next
+	// start
+	RestartRequired []string `json:"restart_required"`
+}
+
+// ReloadConfig reads the .env file and the config file of the active
+// config again and applies the sections that can change at runtime:
+// networks, signer, auth, limits and webhooks. An invalid config is rejected
+// and the active one kept.
+func ReloadConfig() (*ConfigReload, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	if err := LoadEnv(EnvFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	current := CurrentConfig()
+	next, err := LoadConfig(current.path)
+	if err != nil {
+		return nil, err
+	}
+	// The environment may have been given by the --env flag
+	next.Env = current.Env
+
+	reload := &ConfigReload{Applied: []string{}, RestartRequired: []string{}}
+	currentValue, nextValue := reflect.ValueOf(current).Elem(), reflect.ValueOf(next).Elem()
+	for i := 0; i < nextValue.NumField(); i++ {
+		field := nextValue.Type().Field(i)
+		section := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if section == "" || reflect.DeepEqual(currentValue.Field(i).Interface(), nextValue.Field(i).Interface()) {
+			continue
+		}
+		if reloadableSections[section] {
+			reload.Applied = append(reload.Applied, section)
+			continue
+		}
+		reload.RestartRequired = append(reload.RestartRequired, section)
+		nextValue.Field(i).Set(currentValue.Field(i))
+	}
+	if err := next.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %v", err)
+	}
+
+	ApplyConfig(next)
+	slog.Info("config reloaded", "applied", reload.Applied, "restart_required", reload.RestartRequired)
+	return reload, nil
+}
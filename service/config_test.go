@@ -0,0 +1,131 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+listen: 127.0.0.1:9000
+networks:
+  - name: dbc-mainnet
+    rpc_url: https://rpc.dbcwallet.io
+    chain_id: 19880818
+  - name: dbc-testnet
+    rpc_url: https://rpc-testnet.dbcwallet.io
+    chain_id: 19850818
+    private_key: 0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d
+limits:
+  shutdown_timeout: 5m
+runner:
+  kind: docker
+  image: foundry:pinned
+`
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testConfig), 0600))
+	t.Setenv("MAX_QUEUE_DEPTH", "5")
+	t.Setenv("RUNNER_TIMEOUT", "1h")
+	t.Setenv("PRIVATE_KEY", "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80")
+
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	require.NoError(t, cfg.Validate())
+	assert.Equal(t, "127.0.0.1:9000", cfg.Listen)
+	assert.Len(t, cfg.Networks, 2)
+	assert.Equal(t, Duration(5*time.Minute), cfg.Limits.ShutdownTimeout)
+	assert.Equal(t, 5, cfg.Limits.MaxQueueDepth)
+	assert.Equal(t, Duration(time.Hour), cfg.Runner.Timeout)
+	assert.Equal(t, "4g", cfg.Runner.Memory)
+	assert.Equal(t, "info", cfg.Logging.Level)
+
+	redacted := cfg.Redacted()
+	assert.Equal(t, redactedValue, redacted.Signer.PrivateKey)
+	assert.Equal(t, redactedValue, redacted.Networks[1].PrivateKey)
+	assert.Empty(t, redacted.Networks[0].PrivateKey)
+	assert.NotEqual(t, redactedValue, cfg.Networks[1].PrivateKey)
+
+	t.Setenv("MAX_QUEUE_DEPTH", "many")
+	_, err = LoadConfig(path)
+	assert.ErrorContains(t, err, "invalid MAX_QUEUE_DEPTH")
+
+	require.NoError(t, os.WriteFile(path, []byte("listen: :8070\nlisten_addr: :8080\n"), 0600))
+	_, err = LoadConfig(path)
+	assert.ErrorContains(t, err, "field listen_addr not found")
+
+	_, err = LoadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+}
+
+func TestConfig_Validate(t *testing.T) {
+	cfg := DefaultConfig()
+	require.NoError(t, cfg.Validate())
+
+	cfg.Env = "staging"
+	cfg.Networks = append(cfg.Networks, NetworkConfig{Name: "DBC-MAINNET", RPCURL: "rpc.dbcwallet.io"})
+	cfg.Limits.MinDeployerBalance = "0.1"
+	cfg.Runner.Kind = "podman"
+	cfg.Logging.Level = "verbose"
+	cfg.Indexer.Enabled = false
+	cfg.Webhooks.URL = "https://hooks.example.com/adc"
+	err := cfg.Validate()
+	require.Error(t, err)
+	for _, want := range []string{
+		"env: must be dev or prod",
+		"networks[1].name: duplicate network DBC-MAINNET",
+		"networks[1].rpc_url: must be an http(s) URL",
+		"networks[1].chain_id: must be positive",
+		"limits.min_deployer_balance",
+		"runner.image: is required for the podman runner",
+		"logging.level",
+		"webhooks.url: requires indexer.enabled",
+	} {
+		assert.ErrorContains(t, err, want)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	original := CurrentConfig()
+	t.Cleanup(func() { ApplyConfig(original) })
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("auth:\n  password: first\n"), 0600))
+	cfg, err := LoadConfig(path)
+	require.NoError(t, err)
+	ApplyConfig(cfg)
+
+	require.NoError(t, os.WriteFile(path, []byte(testConfig+"auth:\n  password: second\n"), 0600))
+	reload, err := ReloadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"networks", "auth", "limits"}, reload.Applied)
+	assert.Equal(t, []string{"listen", "runner"}, reload.RestartRequired)
+	assert.Equal(t, "second", CurrentConfig().Auth.Password)
+	assert.Equal(t, "0.0.0.0:8070", CurrentConfig().Listen)
+	assert.Equal(t, Duration(5*time.Minute), CurrentConfig().Limits.ShutdownTimeout)
+	_, err = LookupNetwork("dbc-testnet")
+	assert.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("auth:\n  password: \"\"\n"), 0600))
+	_, err = ReloadConfig()
+	assert.ErrorContains(t, err, "auth.password")
+	assert.Equal(t, "second", CurrentConfig().Auth.Password)
+	_, err = LookupNetwork("dbc-testnet")
+	assert.NoError(t, err)
+
+	// Removed keys revert to their defaults
+	require.NoError(t, os.WriteFile(path, []byte("auth:\n  password: second\n"), 0600))
+	reload, err = ReloadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"networks", "limits"}, reload.Applied)
+	assert.Empty(t, reload.RestartRequired)
+	assert.Equal(t, Duration(DefaultShutdownTimeout), CurrentConfig().Limits.ShutdownTimeout)
+	assert.Equal(t, "0.0.0.0:8070", CurrentConfig().Listen)
+	_, err = LookupNetwork("dbc-testnet")
+	assert.Error(t, err)
+}
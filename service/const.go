@@ -1,26 +1,88 @@
 package service
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
 )
 
 const DBC_MAINNET = "https://rpc.dbcwallet.io"
+const DBC_MAINNET_CHAIN_ID = 19880818
 const MAIN_NET_VERIFIER_URL = "https://www.dbcscan.io/api"
 const XAAIAO_TOKEN_IN_CONTRACT = "0x16d83F6B17914a4e88436251589194CA5AC0f452"
-const XAAIAO_NFT_HOLDER_CONTRACT = "0xc488736c09ab088e5203b48d973dca30581d6118"
-const DBC_AI_PROXY = "0xa7B9f404653841227AF204a561455113F36d8EC8"
 
-var (
-	ContractEnvPath = "./contracts/.env"
-	ContractPath    = "./contracts"
-)
+// DefaultContractPath is the contracts tree unless paths.contracts is set
+const DefaultContractPath = "./contracts"
+
+// Init validates cfg, applies it and starts the service: the contract
+// types, toolchain, store, build, local network and job worker
+func Init(cfg *Config) error {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+	ApplyConfig(cfg)
+	if err := LockDataDir(); err != nil {
+		return err
+	}
+	slog.Info("contract file path", "path", cfg.Paths.Contracts)
+	slog.Info("workspace root", "path", WorkspaceRoot)
+
+	if err := LoadContractTypes(ContractTypesPath); err != nil {
+		return err
+	}
+	for _, spec := range ContractTypes() {
+		slog.Info("contract type", "name", spec.Name, "script", spec.Script)
+	}
+
+	tc, err := initRunner(cfg.Runner)
+	if err != nil {
+		return err
+	}
+	toolchain = tc
+	logToolchain(tc)
+
+	store, err = OpenStore(StorePath)
+	if err != nil {
+		return err
+	}
+	slog.Info("store file path", "path", StorePath)
+
+	// Reconcile the jobs a crash interrupted while their broadcasts are
+	// still in the workspaces, before the warm-up build creates its own
+	RecoverJobs(context.Background())
+	if err := CleanStaleWorkspaces(); err != nil {
+		return err
+	}
+
+	if _, err := StartBuild(cfg.Paths.Contracts, false); err != nil {
+		return err
+	}
+
+	if cfg.LocalNetwork.Enabled {
+		if _, err := StartLocalNetwork(context.Background(), cfg.Paths.Contracts); err != nil {
+			return err
+		}
+	}
+
+	return StartJobs()
+}
 
-func Init(env string) {
-	// if env == "prod" {
-	// 	ContractEnvPath = "../contracts/.env"
-	// 	ContractPath = "../contracts"
-	// }
-	log.Println("contract env file path: ", ContractEnvPath)
-	log.Println("contract file path: ", ContractPath)
+// initRunner selects the executor of the runner config and discovers the
+// matching toolchain
+func initRunner(runner RunnerConfig) (*Toolchain, error) {
+	if runner.Kind == "host" {
+		return DiscoverToolchain(CurrentConfig().Paths.Contracts)
+	}
 
+	ce, err := NewContainerExecutor(runner.Kind, runner.Image)
+	if err != nil {
+		return nil, err
+	}
+	ce.CPUs = runner.CPUs
+	ce.Memory = runner.Memory
+	ce.Timeout = time.Duration(runner.Timeout)
+	ce.Network = runner.Network
+	SetExecutor(ce)
+	return DiscoverContainerToolchain(context.Background(), ce, CurrentConfig().Paths.Contracts)
 }
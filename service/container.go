@@ -0,0 +1,223 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	containerWorkdir = "/workspace"
+	// containerKeyEnv carries the private key into the container so it never
+	// appears on a command line
+	containerKeyEnv = "ADC_PRIVATE_KEY"
+)
+
+// ContainerExecutor runs commands inside a throwaway container through the
+// docker or podman CLI. Only the command's workspace is mounted, together
+// with the read-only targets of its top-level symlinks such as lib.
+type ContainerExecutor struct {
+	// Runtime is the docker or podman binary
+	Runtime string
+	// Image is the default toolchain image, overridden per contract type
+	Image string
+	// CPUs and Memory cap the container resources, e.g. "2" and "4g"
+	CPUs   string
+	Memory string
+	// Timeout caps the run time of a single command
+	Timeout time.Duration
+	// Network is the container network mode, e.g. host to reach a local node
+	Network string
+}
+
+// NewContainerExecutor returns an executor using the runtime binary, docker or podman
+func NewContainerExecutor(runtime, image string) (*ContainerExecutor, error) {
+	bin, err := exec.LookPath(runtime)
+	if err != nil {
+		return nil, fmt.Errorf("container runtime %s not found: %v", runtime, err)
+	}
+	if image == "" {
+		return nil, fmt.Errorf("runner.image is required for the %s runner", runtime)
+	}
+	return &ContainerExecutor{
+		Runtime: bin,
+		Image:   image,
+		CPUs:    "2",
+		Memory:  "4g",
+		Timeout: 30 * time.Minute,
+	}, nil
+}
+
+func (e *ContainerExecutor) Run(ctx context.Context, cmd Command) ([]byte, error) {
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+
+	name := "adc-" + newID()
+	args, secretEnv, err := e.runArgs(name, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	c := exec.CommandContext(ctx, e.Runtime, args...)
+	c.Env = append(os.Environ(), secretEnv...)
+	// Killing the CLI leaves the container running, stop it explicitly
+	c.Cancel = func() error {
+		_ = exec.Command(e.Runtime, "kill", name).Run()
+		return c.Process.Kill()
+	}
+
+	var output bytes.Buffer
+	c.Stdout = &output
+	if cmd.Output != nil {
+		c.Stdout = io.MultiWriter(&output, cmd.Output)
+	}
+	c.Stderr = c.Stdout
+
+	err = c.Run()
+	if ctx.Err() == context.DeadlineExceeded {
+		return output.Bytes(), fmt.Errorf("container %s timed out after %s", name, e.Timeout)
+	}
+	return output.Bytes(), err
+}
+
+// runArgs builds the container run arguments for cmd, returning the secret
+// env entries to set on the CLI process
+func (e *ContainerExecutor) runArgs(name string, cmd Command) ([]string, []string, error) {
+	image := e.Image
+	if cmd.Image != "" {
+		image = cmd.Image
+	}
+
+	args := []string{"run", "--rm", "--name", name, "--tmpfs", "/tmp"}
+	// Run as the host user so the files written to the workspace, such as
+	// the broadcast and build outputs, stay owned by the service
+	if uid, gid := os.Getuid(), os.Getgid(); uid >= 0 && gid >= 0 {
+		args = append(args, "--user", fmt.Sprintf("%d:%d", uid, gid))
+	}
+	if e.CPUs != "" {
+		args = append(args, "--cpus", e.CPUs)
+	}
+	if e.Memory != "" {
+		args = append(args, "--memory", e.Memory)
+	}
+	if e.Network != "" {
+		args = append(args, "--network", e.Network)
+	}
+
+	if cmd.Dir != "" {
+		args = append(args, "-v", cmd.Dir+":"+containerWorkdir, "-w", containerWorkdir)
+		mounts, err := symlinkMounts(cmd.Dir)
+		if err != nil {
+			return nil, nil, err
+		}
+		args = append(args, mounts...)
+	}
+
+	var secretEnv []string
+	var privateKey string
+	for _, entry := range jobEnv(cmd.Env) {
+		key, value, _ := strings.Cut(entry, "=")
+		if key == keyEnv {
+			privateKey = value
+			continue
+		}
+		args = append(args, "-e", key)
+		secretEnv = append(secretEnv, entry)
+	}
+
+	if privateKey == "" {
+		args = append(args, image, filepath.Base(cmd.Name))
+		return append(args, cmd.Args...), secretEnv, nil
+	}
+
+	// Hand the key to the tool inside the container under the env variable
+	// it reads
+	args = append(args, "-e", containerKeyEnv, image)
+	secretEnv = append(secretEnv, containerKeyEnv+"="+privateKey)
+	script := fmt.Sprintf(`%s="$%s" exec "$0" "$@"`, keyEnv, containerKeyEnv)
+	args = append(args, "sh", "-c", script, filepath.Base(cmd.Name))
+	return append(args, cmd.Args...), secretEnv, nil
+}
+
+// symlinkMounts returns read-only bind mounts for the targets of the
+// top-level symlinks of dir, mounted at the same path so the links resolve
+func symlinkMounts(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace: %v", err)
+	}
+	var mounts []string
+	for _, entry := range entries {
+		if entry.Type()&os.ModeSymlink == 0 {
+			continue
+		}
+		target, err := os.Readlink(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if filepath.IsAbs(target) {
+			mounts = append(mounts, "-v", target+":"+target+":ro")
+		}
+	}
+	return mounts, nil
+}
+
+// jobEnv returns the entries of env that are not inherited from the host,
+// i.e. the variables set for the job itself
+func jobEnv(env []string) []string {
+	host := make(map[string]bool)
+	for _, entry := range os.Environ() {
+		host[entry] = true
+	}
+	var entries []string
+	for _, entry := range env {
+		if host[entry] || strings.HasPrefix(entry, "PATH=") {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// DiscoverContainerToolchain probes the toolchain of the runner image
+func DiscoverContainerToolchain(ctx context.Context, e *ContainerExecutor, contractPath string) (*Toolchain, error) {
+	tc := &Toolchain{Runner: filepath.Base(e.Runtime), Image: e.Image, Forge: "forge", Cast: "cast", Make: "make", Node: "node"}
+	var problems []string
+	probe := func(name string) string {
+		output, err := e.Run(ctx, Command{Name: name, Args: []string{"--version"}})
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("failed to probe %s in image %s: %v: %s", name, e.Image, err, strings.TrimSpace(string(output))))
+			return ""
+		}
+		return strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0])
+	}
+	tc.ForgeVersion = probe("forge")
+	tc.CastVersion = probe("cast")
+	tc.NodeVersion = probe("node")
+
+	if err := checkPinnedVersion("forge", tc.ForgeVersion, CurrentConfig().Toolchain.ForgeVersion); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := checkPinnedVersion("node", tc.NodeVersion, CurrentConfig().Toolchain.NodeVersion); err != nil {
+		problems = append(problems, err.Error())
+	}
+	solcVersion, err := foundrySolcVersion(contractPath)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	tc.SolcVersion = solcVersion
+
+	if len(problems) > 0 {
+		return tc, fmt.Errorf("toolchain check failed:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return tc, nil
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestContainerExecutor_RunArgs checks the host user, workspace mounts,
+// resource caps and that secrets only travel through the env
+func TestContainerExecutor_RunArgs(t *testing.T) {
+	dir := t.TempDir()
+	lib := t.TempDir()
+	require.NoError(t, os.Symlink(lib, filepath.Join(dir, "lib")))
+
+	e := &ContainerExecutor{Runtime: "docker", Image: "foundry:default", CPUs: "2", Memory: "4g"}
+	args, secretEnv, err := e.runArgs("adc-test", Command{
+		Name:  "/home/ubuntu/.foundry/bin/cast",
+		Args:  []string{"send", "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707", "unpause()", "--legacy"},
+		Dir:   dir,
+		Env:   append(os.Environ(), "TOKEN_NAME=TokenName", "ETH_PRIVATE_KEY=0xsecret"),
+		Image: "foundry:pinned",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"run", "--rm", "--name", "adc-test", "--tmpfs", "/tmp", "--user", fmt.Sprintf("%d:%d", os.Getuid(), os.Getgid())}, args[:8])
+	cmdline := strings.Join(args, " ")
+	assert.NotContains(t, cmdline, "0xsecret")
+	assert.Contains(t, cmdline, "--cpus 2 --memory 4g")
+	assert.Contains(t, cmdline, "-v "+dir+":/workspace -w /workspace")
+	assert.Contains(t, cmdline, "-v "+lib+":"+lib+":ro")
+	assert.Contains(t, cmdline, "-e TOKEN_NAME")
+	assert.NotContains(t, cmdline, "-e ETH_PRIVATE_KEY")
+	assert.Contains(t, cmdline, `-e ADC_PRIVATE_KEY foundry:pinned sh -c ETH_PRIVATE_KEY="$ADC_PRIVATE_KEY" exec "$0" "$@" cast`)
+	assert.True(t, strings.HasSuffix(cmdline, "cast send 0x5FC8d32690cc91D4c39d9d3abcBD16989F875707 unpause() --legacy"))
+	assert.ElementsMatch(t, []string{"TOKEN_NAME=TokenName", "ADC_PRIVATE_KEY=0xsecret"}, secretEnv)
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrInvalidCall is returned for calls that cannot be encoded against
+	// the contract ABI
+	ErrInvalidCall = errors.New("invalid contract call")
+	// ErrCallReverted is returned when the contract reverts the call
+	ErrCallReverted = errors.New("contract call reverted")
+)
+
+// ContractCall is a read-only call of a deployed contract
+type ContractCall struct {
+	Address string
+	// Network defaults to the network of the recorded deployment, then to
+	// the default network
+	Network string
+	// ContractType and Artifact select the ABI: the artifact when set, else
+	// the artifact recorded with the deployment, else the contract of the type
+	ContractType string
+	Artifact     string
+	// Function name, or signature when overloaded
+	Function string
+	// Args as decoded by encoding/json with UseNumber
+	Args []interface{}
+	// Block is a block number, or latest (default), pending or earliest
+	Block string
+}
+
+// CallResult holds the decoded return values of a contract call
+type CallResult struct {
+	Contract string     `json:"contract"`
+	Network  string     `json:"network"`
+	Artifact string     `json:"artifact"`
+	Function string     `json:"function"`
+	Block    string     `json:"block"`
+	Outputs  []ABIValue `json:"outputs"`
+}
+
+// CallContract runs a view or pure function of a deployed contract with
+// eth_call and decodes its return values
+func CallContract(ctx context.Context, call ContractCall) (*CallResult, error) {
+	artifact, deployment, err := resolveArtifact(call)
+	if err != nil {
+		return nil, err
+	}
+	networkName := call.Network
+	if networkName == "" && deployment != nil {
+		networkName = deployment.Network
+	}
+	network, err := LookupNetwork(networkName)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCall, err)
+	}
+	block, err := blockTag(call.Block)
+	if err != nil {
+		return nil, err
+	}
+
+	function, err := loadFunction(artifact, call.Function)
+	if err != nil {
+		return nil, err
+	}
+	if !function.ReadOnly() {
+		return nil, fmt.Errorf("%w: %s is not a view function, send a transaction instead", ErrInvalidCall, function.Signature())
+	}
+	Logger(ctx).Info("calling contract", "contract", call.Address, "network", network.Name, "function", function.Signature(), "block", block)
+	outputs, err := callFunction(ctx, network, call.Address, function, call.Args, block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CallResult{
+		Contract: call.Address,
+		Network:  network.Name,
+		Artifact: artifact,
+		Function: function.Signature(),
+		Block:    block,
+		Outputs:  outputs,
+	}, nil
+}
+
+// callFunction runs function of the contract at address with eth_call at
+// block and decodes its return values
+func callFunction(ctx context.Context, network *Network, address string, function *ABIEntry, args []interface{}, block string) ([]ABIValue, error) {
+	data, err := function.EncodeCall(args)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCall, err)
+	}
+
+	Logger(ctx).Debug("eth_call", "contract", address, "network", network.Name, "function", function.Signature(), "block", block)
+	var output string
+	tx := map[string]string{"to": address, "data": "0x" + hex.EncodeToString(data)}
+	if err := rpcCall(network.RPCURL, "eth_call", &output, tx, block); err != nil {
+		if strings.Contains(err.Error(), "revert") {
+			return nil, fmt.Errorf("%w: %v", ErrCallReverted, err)
+		}
+		return nil, err
+	}
+	returned, err := hex.DecodeString(strings.TrimPrefix(output, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid eth_call result %q: %v", output, err)
+	}
+	if len(returned) == 0 && len(function.Outputs) > 0 {
+		return nil, fmt.Errorf("%w: no code at %s on %s", ErrInvalidCall, address, network.Name)
+	}
+	outputs, err := function.DecodeOutputs(returned)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s result: %v", function.Signature(), err)
+	}
+	return outputs, nil
+}
+
+// loadFunction returns the function of the ABI of artifact
+func loadFunction(artifact, name string) (*ABIEntry, error) {
+	abi, err := LoadABI(artifact)
+	if errors.Is(err, ErrBuildNotReady) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCall, err)
+	}
+	function, err := abi.Function(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCall, err)
+	}
+	return function, nil
+}
+
+// findDeployment returns the latest successful deployment whose proxy is
+// address, filtered by network and contract type when set
+func findDeployment(address, network, contractType string) *Deployment {
+	if store == nil {
+		return nil
+	}
+	matches := store.Deployments(func(d *Deployment) bool {
+		return d.Status == DeploymentSuccess && strings.EqualFold(d.ProxyAddress, address) &&
+			matchField(network, d.Network) && matchField(contractType, d.ContractType)
+	})
+	if len(matches) == 0 {
+		return nil
+	}
+	return matches[len(matches)-1]
+}
+
+// resolveArtifact returns the artifact holding the ABI of the called
+// contract, and its recorded deployment if any
+func resolveArtifact(call ContractCall) (string, *Deployment, error) {
+	deployment := findDeployment(call.Address, call.Network, call.ContractType)
+	if call.Artifact != "" {
+		return call.Artifact, deployment, nil
+	}
+	if deployment != nil && deployment.ABI != "" {
+		return deployment.ABI, deployment, nil
+	}
+
+	contractType := call.ContractType
+	if contractType == "" && deployment != nil {
+		contractType = deployment.ContractType
+	}
+	if contractType == "" {
+		return "", nil, fmt.Errorf("%w: no deployment recorded at %s, contract_type or artifact is required", ErrInvalidCall, call.Address)
+	}
+	spec, err := LookupContractType(contractType)
+	if err != nil {
+		return "", nil, fmt.Errorf("%w: %v", ErrInvalidCall, err)
+	}
+	if spec.Contract == "" {
+		return "", nil, fmt.Errorf("%w: contract type %s declares no contract, artifact is required", ErrInvalidCall, spec.Name)
+	}
+	return spec.Contract, deployment, nil
+}
+
+// blockTag returns the eth_call block parameter of block
+func blockTag(block string) (string, error) {
+	switch block {
+	case "":
+		return "latest", nil
+	case "latest", "pending", "earliest", "safe", "finalized":
+		return block, nil
+	}
+	number, err := strconv.ParseUint(block, 0, 64)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid block %q", ErrInvalidCall, block)
+	}
+	return hexBlock(number), nil
+}
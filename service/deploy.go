@@ -1,96 +1,155 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"log"
-	"os"
-	"os/exec"
+	"io"
+	"regexp"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
-type contractType int
+var (
+	ErrScriptFailed    = errors.New("deploy script failed")
+	ErrVerifyFailed    = errors.New("contract verification failed")
+	ErrAddressNotFound = errors.New("failed to parse contract addresses from output")
+)
 
-const (
-	IAO contractType = iota
-	STAKING
-	TOKEN
-	PAYMENT
+var (
+	proxyAddressPattern = regexp.MustCompile(`Proxy Contract deployed at:\s*(0x[0-9a-fA-F]{40})`)
+	verifyFailedPattern = regexp.MustCompile(`(?i)(failed to verify|verification failed|not verified)`)
 )
 
-// execCommand is used to make the function testable
-var execCommand = exec.Command
+// DeployContract runs the forge script of spec against network in a fresh
+// workspace and returns the deployed proxy address. When the deployment
+// landed but verification failed, the proxy address is returned together
+// with an error wrapping ErrVerifyFailed.
+func DeployContract(ctx context.Context, path string, scriptEnvVars map[string]string, spec *ContractTypeSpec, network *Network) (proxyAddress string, err error) {
+	defer trackInFlight("deploy")()
 
-func DeployContract(path, envPath string, scriptEnvVars map[string]string, tp contractType) (proxyAddress string, err error) {
-	err = LoadEnv("./.env")
-	if err != nil {
-		log.Fatal(err)
-	}
+	deployment := newDeployment(ctx, spec, network, scriptEnvVars)
+	ctx = WithLogger(ctx, Logger(ctx).With("deployment_id", deployment.ID))
+	logger := Logger(ctx)
+	logger.Info("deployment started", "contract_type", spec.Name, "network", network.Name)
 
-	err = WriteEnv(scriptEnvVars, envPath, tp)
+	ctx, span := StartSpan(ctx, "DeployContract", deploymentAttributes(spec, network)...)
+	span.SetAttributes(attribute.String("deploy.id", deployment.ID))
+	defer func() {
+		span.SetAttributes(attribute.String("deploy.result", deployment.Status))
+		EndSpan(span, err)
+	}()
+	defer func() {
+		recordDeployment(ctx, deployment, proxyAddress, err)
+	}()
+
+	ws, err := NewWorkspace(ctx, path)
 	if err != nil {
 		return "", err
 	}
+	recordJobWorkspace(ctx, deployment.ID, ws.Dir)
 	defer func() {
-		cleanCMD := execCommand("forge", "clean")
-		cleanCMD.Dir = path
-		// Set environment variables with explicit paths to avoid version conflicts
-		env := os.Environ()
-		// Prioritize correct Node.js version and forge paths
-		pathVar := "/home/ubuntu/.nvm/versions/node/v23.9.0/bin:/home/ubuntu/.foundry/bin:/usr/local/bin:/usr/bin:/bin"
-		env = append(env, "PATH="+pathVar)
-		cleanCMD.Env = env
-		_, _ = cleanCMD.CombinedOutput()
+		_, cleanupSpan := StartSpan(ctx, SpanCleanup)
+		ws.Cleanup(err != nil)
+		cleanupSpan.End()
 	}()
 
-	deployTarget := "deploy-XAAAIO-dbc-mainnet"
-	if tp == STAKING {
-		deployTarget = "deploy-staking-dbc-mainnet"
-	}
-	if tp == TOKEN {
-		deployTarget = "deploy-token-dbc-mainnet"
+	_, envSpan := StartSpan(ctx, SpanWriteEnv)
+	err = WriteEnv(scriptEnvVars, ws.EnvPath, spec, network)
+	EndSpan(envSpan, err)
+	if err != nil {
+		return "", err
 	}
-	if tp == PAYMENT {
-		deployTarget = "deploy-payment-mainnet"
+
+	compileStart := time.Now()
+	compileCtx, compileSpan := StartSpan(ctx, StageCompile)
+	restored := restoreArtifacts(compileCtx, path, ws)
+	compileSpan.SetAttributes(attribute.Bool("build.cached", restored))
+	compileSpan.End()
+	observeStage(StageCompile, compileStart)
+	if restored {
+		logger.Info("reusing cached build artifacts", "workspace", ws.Dir)
 	}
-	cmd := execCommand("bash", "-c", fmt.Sprintf(
-		"make %s PRIVATE_KEY=%s dbc-mainnet=%s MAIN_NET_VERIFIER_URL=%s",
-		deployTarget,
-		os.Getenv("PRIVATE_KEY"),
-		DBC_MAINNET,
-		MAIN_NET_VERIFIER_URL,
-	))
-	cmd.Dir = path
-	// Set environment variables with explicit paths to avoid version conflicts
-	env := os.Environ()
-	// Prioritize correct Node.js version and forge paths
-	pathVar := "/home/ubuntu/.nvm/versions/node/v23.9.0/bin:/home/ubuntu/.foundry/bin:/usr/local/bin:/usr/bin:/bin"
-	env = append(env, "PATH="+pathVar)
-	cmd.Env = env
-
-	log.Printf("Executing command:  make %s PRIVATE_KEY=%s dbc-mainnet=%s MAIN_NET_VERIFIER_URL=%s",
-		deployTarget,
-		os.Getenv("PRIVATE_KEY"),
-		DBC_MAINNET,
-		MAIN_NET_VERIFIER_URL)
-
-	output, err := cmd.CombinedOutput()
-	log.Printf("Command output:\n%s", string(output))
 
-	if err != nil {
-		return "", fmt.Errorf("deploy error: %v: %s", err, string(output))
+	// The scripts read the key from PRIVATE_KEY in the env, keeping it out
+	// of the argv
+	args := []string{
+		"script", spec.Script,
+		"--rpc-url", network.RPCURL,
+		"--broadcast",
+	}
+	if network.VerifierURL != "" {
+		args = append(args,
+			"--verify",
+			"--verifier", "blockscout",
+			"--verifier-url", network.VerifierURL,
+		)
 	}
+	args = append(args,
+		"--skip-simulation",
+		"--legacy",
+	)
+	stages := newStageTimer()
+	forgeLog := newLineLogger(ctx, "forge")
+	cmd := Command{
+		Name:   toolPath("forge"),
+		Args:   args,
+		Dir:    ws.Dir,
+		Env:    toolchainEnv(),
+		Image:  spec.Image,
+		Output: io.MultiWriter(stages, forgeLog),
+	}
+	for key, value := range scriptEnvVars {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+
+	logger.Info("executing command", "command", cmd.String())
+
+	output, runErr := executor.Run(ctx, cmd)
+	forgeLog.Flush()
+	stages.observe(ctx)
 
-	outputStr := string(output)
-	lines := strings.Split(outputStr, "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "Proxy Contract deployed at:") {
-			proxyAddress = strings.TrimSpace(strings.Split(line, ":")[1])
+	_, parseSpan := StartSpan(ctx, SpanParse)
+	defer func() {
+		EndSpan(parseSpan, err)
+	}()
+	proxyAddress, err = parseDeployOutput(string(output), runErr)
+	if proxyAddress != "" {
+		deployment.ProxyAddress = proxyAddress
+		span.SetAttributes(attribute.String("deploy.proxy_address", proxyAddress))
+		if err := readBroadcast(ws.Dir, spec, deployment); err != nil {
+			logger.Warn("failed to read broadcast", "error", err)
 		}
 	}
+	return proxyAddress, err
+}
 
-	if proxyAddress == "" {
-		return "", fmt.Errorf("failed to parse contract addresses from output")
+// parseDeployOutput extracts the proxy address from the forge script output
+// and maps failures to ErrScriptFailed, ErrVerifyFailed or ErrAddressNotFound
+func parseDeployOutput(output string, runErr error) (string, error) {
+	var proxyAddress string
+	if match := proxyAddressPattern.FindStringSubmatch(output); match != nil {
+		proxyAddress = match[1]
 	}
 
+	if runErr != nil {
+		if proxyAddress != "" && verifyFailedPattern.MatchString(output) {
+			return proxyAddress, fmt.Errorf("%w: %v: %s", ErrVerifyFailed, runErr, lastLines(output, 20))
+		}
+		return "", fmt.Errorf("%w: %v: %s", ErrScriptFailed, runErr, output)
+	}
+	if proxyAddress == "" {
+		return "", ErrAddressNotFound
+	}
 	return proxyAddress, nil
 }
+
+func lastLines(output string, n int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -1,52 +1,480 @@
 package service
 
 import (
-	"fmt"
-	"os/exec"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/assert"
-	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
-// MockCmd is a mock for command execution
-type MockCmd struct {
-	mock.Mock
+// Absolute paths resolved before setupTest changes the working dir
+var (
+	testdataPath, _  = filepath.Abs("testdata")
+	contractsPath, _ = filepath.Abs("../contracts")
+)
+
+var tokenEnvVars = map[string]string{
+	"TOKEN_OWNER":                    "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D",
+	"TOKEN_NAME":                     "TokenName",
+	"TOKEN_SYMBOL":                   "TN",
+	"TOKEN_INIT_SUPPLY":              "2000000000000000000000000000",
+	"TOKEN_SUPPLY_FIXED_YEARS":       "8",
+	"TOKEN_AMOUNT_CAN_MINT_PER_YEAR": "6000000000000000000000000000",
+	"IAO_CONTRACT_ADDRESS":           "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D",
+	"AMOUNT_TO_IAO":                  "100000000000000000000000000",
+}
+
+// setupTest installs fake as the executor and runs the test from a temp dir
+// holding an empty .env, with workspaces created under it
+func setupTest(t *testing.T, fake Executor) {
+	t.Helper()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), nil, 0600))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+
+	originalExecutor, originalRoot, originalKeep, originalConfig := executor, WorkspaceRoot, KeepFailedWorkspaces, activeConfig
+	executor = fake
+	WorkspaceRoot = filepath.Join(dir, "workspaces")
+	activeConfig = DefaultConfig()
+	activeConfig.Signer.PrivateKey = "0xkey"
+
+	t.Cleanup(func() {
+		executor, WorkspaceRoot, KeepFailedWorkspaces, activeConfig = originalExecutor, originalRoot, originalKeep, originalConfig
+		_ = os.Chdir(wd)
+	})
+}
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join(testdataPath, name))
+	require.NoError(t, err)
+	return content
+}
+
+// TestDeployContract replays captured forge output and checks parsing and
+// error mapping
+func TestDeployContract(t *testing.T) {
+	tests := []struct {
+		name      string
+		fixture   string
+		runErr    error
+		wantProxy string
+		wantErr   error
+	}{
+		{
+			name:      "success",
+			fixture:   "forge/success.txt",
+			wantProxy: "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707",
+		},
+		{
+			name:    "revert",
+			fixture: "forge/revert.txt",
+			runErr:  errors.New("exit status 1"),
+			wantErr: ErrScriptFailed,
+		},
+		{
+			name:      "verify failure",
+			fixture:   "forge/verify_failure.txt",
+			runErr:    errors.New("exit status 1"),
+			wantProxy: "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707",
+			wantErr:   ErrVerifyFailed,
+		},
+		{
+			name:    "missing address",
+			fixture: "forge/missing_address.txt",
+			wantErr: ErrAddressNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &FakeExecutor{Responses: []FakeResponse{
+				{Name: "forge", Args: []string{"script"}, Output: readFixture(t, tt.fixture), Err: tt.runErr},
+			}}
+			setupTest(t, fake)
+
+			spec, err := LookupContractType("token")
+			require.NoError(t, err)
+			network, err := LookupNetwork(DefaultNetwork)
+			require.NoError(t, err)
+
+			proxy, err := DeployContract(context.Background(), contractsPath, copyEnv(tokenEnvVars), spec, network)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantProxy, proxy)
+
+			calls := fake.Calls()
+			require.Len(t, calls, 1)
+			assert.Equal(t, "script/token/Deploy.s.sol:Deploy", calls[0].Args[1])
+			assert.Contains(t, calls[0].Args, "--verify")
+			assert.Contains(t, calls[0].Env, "TOKEN_NAME=TokenName")
+		})
+	}
 }
 
-func (m *MockCmd) CombinedOutput() ([]byte, error) {
-	args := m.Called()
-	return args.Get(0).([]byte), args.Error(1)
+// TestDeployContract_WritesEnv checks the workspace env file seen by forge
+func TestDeployContract_WritesEnv(t *testing.T) {
+	var envFile string
+	fake := &FakeExecutor{
+		Responses: []FakeResponse{{Name: "forge", Output: readFixture(t, "forge/success.txt")}},
+		OnRun: func(cmd Command) {
+			content, _ := os.ReadFile(filepath.Join(cmd.Dir, ".env"))
+			envFile = string(content)
+		},
+	}
+	setupTest(t, fake)
+
+	spec, err := LookupContractType("IAO")
+	require.NoError(t, err)
+	network := &Network{
+		Name:   "test",
+		RPCURL: "http://127.0.0.1:8545",
+		Constants: map[string]string{
+			"XAAIAO_NFT_HOLDER_CONTRACT": "0x0000000000000000000000000000000000000001",
+			"XAAIAO_TOKEN_IN_CONTRACT":   "0x0000000000000000000000000000000000000002",
+		},
+	}
+
+	_, err = DeployContract(context.Background(), contractsPath, map[string]string{"XAAIAO_OWNER": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"}, spec, network)
+	require.NoError(t, err)
+
+	assert.Contains(t, envFile, "XAAIAO_OWNER=0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D\n")
+	assert.Contains(t, envFile, "XAAIAO_NFT_HOLDER_CONTRACT=0x0000000000000000000000000000000000000001\n")
+	assert.Contains(t, envFile, "XAAIAO_TOKEN_IN_CONTRACT=0x0000000000000000000000000000000000000002\n")
+	assert.Contains(t, envFile, "PRIVATE_KEY=0xkey\n")
+	assert.NotContains(t, fake.Calls()[0].Args, "--verify")
+	assert.NotContains(t, fake.Calls()[0].Args, "--private-key")
+	assert.NotContains(t, fake.Calls()[0].Args, "0xkey")
+	assert.Contains(t, fake.Calls()[0].Env, "PRIVATE_KEY=0xkey")
+
+	// A token in address from the request replaces the default
+	_, err = DeployContract(context.Background(), contractsPath, map[string]string{"XAAIAO_TOKEN_IN_CONTRACT": XAAIAO_TOKEN_IN_CONTRACT}, spec, network)
+	require.NoError(t, err)
+	assert.Contains(t, envFile, "XAAIAO_TOKEN_IN_CONTRACT="+XAAIAO_TOKEN_IN_CONTRACT+"\n")
 }
 
-// mockExecCommand is used to inject mock for testing
-var mockExecCommand func(command string, args ...string) *exec.Cmd
+// TestDeployContract_LogsOutput checks every forge output line is logged
+// with the request and deployment IDs
+func TestDeployContract_LogsOutput(t *testing.T) {
+	fake := &FakeExecutor{Responses: []FakeResponse{
+		{Name: "forge", Args: []string{"script"}, Output: readFixture(t, "forge/success.txt")},
+	}}
+	setupTest(t, fake)
 
-// setupTest sets up the test environment
-func setupTest(t *testing.T) func() {
-	originalExecCommand := execCommand
-	execCommand = func(command string, args ...string) *exec.Cmd {
-		return &exec.Cmd{}
+	var logs bytes.Buffer
+	originalLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(originalLogger) })
+
+	spec, err := LookupContractType("token")
+	require.NoError(t, err)
+	network, err := LookupNetwork(DefaultNetwork)
+	require.NoError(t, err)
+	ctx := WithRequestID(context.Background(), "req-1")
+	_, err = DeployContract(ctx, contractsPath, copyEnv(tokenEnvVars), spec, network)
+	require.NoError(t, err)
+
+	var forgeLines int
+	decoder := json.NewDecoder(&logs)
+	for decoder.More() {
+		var entry map[string]interface{}
+		require.NoError(t, decoder.Decode(&entry))
+		assert.Equal(t, "req-1", entry["request_id"], entry["msg"])
+		assert.NotEmpty(t, entry["deployment_id"], entry["msg"])
+		if entry["stream"] == "forge" {
+			forgeLines++
+		}
 	}
-	return func() {
-		execCommand = originalExecCommand
+	assert.Equal(t, strings.Count(string(readFixture(t, "forge/success.txt")), "\n"), forgeLines)
+}
+
+// TestDeployContract_Cleanup checks workspaces are removed, or kept and
+// scrubbed on failure when configured
+func TestDeployContract_Cleanup(t *testing.T) {
+	spec, err := LookupContractType("token")
+	require.NoError(t, err)
+	network, err := LookupNetwork(DefaultNetwork)
+	require.NoError(t, err)
+
+	t.Run("success removes workspace", func(t *testing.T) {
+		setupTest(t, &FakeExecutor{Responses: []FakeResponse{{Name: "forge", Output: readFixture(t, "forge/success.txt")}}})
+		_, err := DeployContract(context.Background(), contractsPath, copyEnv(tokenEnvVars), spec, network)
+		require.NoError(t, err)
+
+		entries, err := os.ReadDir(WorkspaceRoot)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("failure keeps scrubbed workspace", func(t *testing.T) {
+		setupTest(t, &FakeExecutor{Responses: []FakeResponse{{Name: "forge", Output: readFixture(t, "forge/revert.txt"), Err: errors.New("exit status 1")}}})
+		KeepFailedWorkspaces = true
+		_, err := DeployContract(context.Background(), contractsPath, copyEnv(tokenEnvVars), spec, network)
+		require.Error(t, err)
+
+		entries, err := os.ReadDir(WorkspaceRoot)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.True(t, strings.HasPrefix(entries[0].Name(), failedWorkspacePrefix))
+
+		content, err := os.ReadFile(filepath.Join(WorkspaceRoot, entries[0].Name(), ".env"))
+		require.NoError(t, err)
+		assert.Contains(t, string(content), "TOKEN_NAME=TokenName")
+		assert.NotContains(t, string(content), "PRIVATE_KEY")
+	})
+}
+
+// TestRunActions checks actions stop at the first reverted transaction
+func TestRunActions(t *testing.T) {
+	fake := &FakeExecutor{Responses: []FakeResponse{
+		{Name: "cast", Args: []string{"send", "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707", "setStakingContract(address)"}, Output: readFixture(t, "cast/send_success.json")},
+		{Name: "cast", Args: []string{"send"}, Output: readFixture(t, "cast/send_revert.json")},
+	}}
+	setupTest(t, fake)
+
+	spec, err := LookupContractType("token")
+	require.NoError(t, err)
+	network, err := LookupNetwork(DefaultNetwork)
+	require.NoError(t, err)
+
+	results, err := RunActions(context.Background(), "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707", spec, network, []Action{
+		{Function: "setStakingContract", Args: []string{"0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"}},
+		{Function: "addLockTransferAdmin", Args: []string{"0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"}},
+		{Function: "addLockTransferAdmin", Args: []string{"0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D"}},
+	})
+	require.Error(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, ActionSuccess, results[0].Status)
+	assert.Equal(t, "0x2a7e1d0c9b8a7f6e5d4c3b2a1f0e9d8c7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e", results[0].TxHash)
+	assert.Equal(t, ActionFailed, results[1].Status)
+	assert.Equal(t, ActionSkipped, results[2].Status)
+	require.Len(t, fake.Calls(), 2)
+	send := fake.Calls()[0]
+	assert.NotContains(t, send.Args, "--private-key")
+	assert.NotContains(t, send.Args, "0xkey")
+	assert.Contains(t, send.Env, "ETH_PRIVATE_KEY=0xkey")
+
+	_, err = RunActions(context.Background(), "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707", spec, network, []Action{{Function: "setOracle", Args: []string{"0x0"}}})
+	assert.ErrorContains(t, err, "unsupported action")
+	_, err = RunActions(context.Background(), "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707", spec, network, []Action{{Function: "setStakingContract"}})
+	assert.ErrorContains(t, err, "expects 1 args, got 0")
+	assert.Len(t, fake.Calls(), 2)
+}
+
+// TestListDeployments checks recorded deployments can be searched by request
+// metadata and paged
+func TestListDeployments(t *testing.T) {
+	fake := &FakeExecutor{Responses: []FakeResponse{
+		{Name: "forge", Args: []string{"script"}, Output: readFixture(t, "forge/success.txt")},
+	}}
+	setupTest(t, fake)
+
+	originalStore := store
+	var err error
+	store, err = OpenStore(filepath.Join(t.TempDir(), "store.json"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store = originalStore })
+
+	staking, err := LookupContractType("staking")
+	require.NoError(t, err)
+	token, err := LookupContractType("token")
+	require.NoError(t, err)
+	network, err := LookupNetwork(DefaultNetwork)
+	require.NoError(t, err)
+
+	ctx := WithCaller(context.Background(), "admin")
+	_, err = DeployContract(ctx, contractsPath, map[string]string{"OWNER": "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D", "PROJECT_NAME": "DeepLink"}, staking, network)
+	require.NoError(t, err)
+	_, err = DeployContract(ctx, contractsPath, copyEnv(tokenEnvVars), token, network)
+	require.NoError(t, err)
+	fake.Responses[0] = FakeResponse{Name: "forge", Args: []string{"script"}, Output: readFixture(t, "forge/revert.txt"), Err: errors.New("exit status 1")}
+	_, err = DeployContract(context.Background(), contractsPath, copyEnv(tokenEnvVars), token, network)
+	require.Error(t, err)
+
+	deployments, total, err := ListDeployments(DeploymentFilter{ProjectName: "deeplink"})
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	assert.Equal(t, "staking", deployments[0].ContractType)
+	assert.Equal(t, "admin", deployments[0].Caller)
+	assert.Equal(t, "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D", deployments[0].Owner)
+	assert.Equal(t, "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707", deployments[0].ProxyAddress)
+
+	found, err := GetDeployment(deployments[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, deployments[0], found)
+
+	deployments, total, err = ListDeployments(DeploymentFilter{ContractType: "TOKEN", Sort: "created_at", PageSize: 1, Page: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, deployments, 1)
+	assert.Equal(t, DeploymentFailed, deployments[0].Status)
+	assert.Empty(t, deployments[0].Caller)
+
+	_, total, err = ListDeployments(DeploymentFilter{Caller: "admin", Status: DeploymentSuccess, From: time.Now().Add(-time.Hour)})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+
+	_, _, err = ListDeployments(DeploymentFilter{Sort: "params"})
+	assert.Error(t, err)
+	_, err = GetDeployment("missing")
+	assert.Error(t, err)
+}
+
+// TestBuildManifest checks the broadcast is recorded with the deployment and
+// exported in the manifest and devops formats
+func TestBuildManifest(t *testing.T) {
+	fake := &FakeExecutor{
+		Responses: []FakeResponse{
+			{Name: "forge", Args: []string{"script"}, Output: readFixture(t, "forge/success.txt")},
+		},
+		OnRun: func(cmd Command) {
+			broadcast := filepath.Join(cmd.Dir, "broadcast", "Deploy.s.sol", "19880818")
+			artifacts := filepath.Join(cmd.Dir, "out", "Token.sol")
+			require.NoError(t, os.MkdirAll(broadcast, 0755))
+			require.NoError(t, os.MkdirAll(artifacts, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(broadcast, "run-latest.json"), readFixture(t, "forge/broadcast/run-latest.json"), 0644))
+			require.NoError(t, os.WriteFile(filepath.Join(artifacts, "Token.json"), []byte(`{"abi":[]}`), 0644))
+		},
 	}
+	setupTest(t, fake)
+
+	originalStore := store
+	var err error
+	store, err = OpenStore(filepath.Join(t.TempDir(), "store.json"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store = originalStore })
+	succeeded := testutil.ToFloat64(deploymentsTotal.WithLabelValues("token", DefaultNetwork, DeploymentSuccess))
+	verified := histogramCount(t, StageVerify)
+
+	spec, err := LookupContractType("token")
+	require.NoError(t, err)
+	network, err := LookupNetwork(DefaultNetwork)
+	require.NoError(t, err)
+	_, err = DeployContract(context.Background(), contractsPath, copyEnv(tokenEnvVars), spec, network)
+	require.NoError(t, err)
+
+	manifest := BuildManifest(DeploymentFilter{})
+	require.Contains(t, manifest.Networks, DefaultNetwork)
+	assert.Equal(t, int64(DBC_MAINNET_CHAIN_ID), manifest.Networks[DefaultNetwork].ChainID)
+	contract := manifest.Networks[DefaultNetwork].Projects[DefaultProject]["token"]
+	require.NotNil(t, contract)
+	assert.Equal(t, "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707", contract.Proxy)
+	assert.Equal(t, "0xDc64a140Aa3E981100a9becA4E685f962f0cF6C9", contract.Implementation)
+	assert.Equal(t, "Token", contract.ContractName)
+	assert.Equal(t, filepath.Join("out", "Token.sol", "Token.json"), contract.ABI)
+	assert.Equal(t, "0x3f1c9d2e7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e", contract.TxHash)
+	assert.Equal(t, uint64(1203341), contract.BlockNumber)
+	deployment, err := GetDeployment(contract.DeploymentID)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(6122700), deployment.GasUsed)
+	assert.Equal(t, "6122700000000000", deployment.Fee)
+	assert.Equal(t, succeeded+1, testutil.ToFloat64(deploymentsTotal.WithLabelValues("token", DefaultNetwork, DeploymentSuccess)))
+	assert.Equal(t, verified+1, histogramCount(t, StageVerify))
+
+	run, err := BuildDevOpsRun(DeploymentFilter{Network: DefaultNetwork})
+	require.NoError(t, err)
+	require.Len(t, run.Transactions, 1)
+	assert.Equal(t, "Token", run.Transactions[0].ContractName)
+	assert.Equal(t, contract.Proxy, run.Transactions[0].ContractAddress)
+	_, err = BuildDevOpsRun(DeploymentFilter{})
+	assert.Error(t, err)
 }
 
-// TestDeployContract_Success tests the successful deployment scenario
-func TestDeployContract_Success(t *testing.T) {
+func histogramCount(t *testing.T, stage string) uint64 {
+	t.Helper()
+	var metric dto.Metric
+	require.NoError(t, stageDuration.WithLabelValues(stage).(prometheus.Histogram).Write(&metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func copyEnv(envVars map[string]string) map[string]string {
+	copied := make(map[string]string, len(envVars))
+	for key, value := range envVars {
+		copied[key] = value
+	}
+	return copied
+}
 
-	scriptEnvVars := map[string]string{
-		"XAAIAO_OWNER":                 "0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D",
-		"XAAIAO_REWARD_TOKEN_CONTRACT": "0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45",
-		"XAAIAO_START_TIMESTAMP":       "1743663600",
-		"XAAIAO_PERIOD_HOURS":          "72",
-		"XAAIAO_REWARD_AMOUNT":         "2000000000000000000000000001",
+// TestDeployContract_ReusesBuildCache checks deployments reuse the artifacts
+// of the warm-up build instead of recompiling
+func TestDeployContract_ReusesBuildCache(t *testing.T) {
+	var restored bool
+	fake := &FakeExecutor{
+		Responses: []FakeResponse{
+			{Name: "forge", Args: []string{"build"}},
+			{Name: "forge", Args: []string{"script"}, Output: readFixture(t, "forge/success.txt")},
+		},
+		OnRun: func(cmd Command) {
+			switch cmd.Args[0] {
+			case "build":
+				for _, name := range buildArtifactDirs {
+					require.NoError(t, os.MkdirAll(filepath.Join(cmd.Dir, name), 0755))
+				}
+				require.NoError(t, os.WriteFile(filepath.Join(cmd.Dir, "out", "Token.json"), []byte("{}"), 0644))
+			case "script":
+				_, err := os.Stat(filepath.Join(cmd.Dir, "out", "Token.json"))
+				restored = err == nil
+				assert.NotContains(t, cmd.Args, "--force")
+			}
+		},
 	}
+	setupTest(t, fake)
+	originalCacheDir := BuildCacheDir
+	BuildCacheDir = filepath.Join(WorkspaceRoot, "..", "build-cache")
+	t.Cleanup(func() {
+		builds.current = nil
+		BuildCacheDir = originalCacheDir
+	})
 
-	// Execute test
-	proxy, err := DeployContract("../XAASwap", "../XAASwap/envs/iao/.env", scriptEnvVars, IAO)
+	build, err := StartBuild(contractsPath, false)
+	require.NoError(t, err)
+	<-builds.current.done
+	assert.Equal(t, BuildReady, CurrentBuild().Status)
 
+	again, err := StartBuild(contractsPath, false)
+	require.NoError(t, err)
+	assert.Equal(t, build.Key, again.Key)
+
+	spec, err := LookupContractType("token")
+	require.NoError(t, err)
+	network, err := LookupNetwork(DefaultNetwork)
+	require.NoError(t, err)
+	_, err = DeployContract(context.Background(), contractsPath, copyEnv(tokenEnvVars), spec, network)
+	require.NoError(t, err)
+	assert.True(t, restored)
+	assert.Len(t, fake.Calls(), 2)
+
+	// A forced rebuild compiles into a new dir, the previous one is kept for
+	// its readers until released
+	previous := builds.current
+	require.True(t, acquireBuild(previous))
+	_, err = StartBuild(contractsPath, true)
+	require.NoError(t, err)
+	<-builds.current.done
+	assert.NotEqual(t, previous.dir, CurrentBuild().dir)
+	_, err = os.Stat(filepath.Join(previous.dir, "out", "Token.json"))
+	assert.NoError(t, err)
+	releaseBuild(previous)
+	_, err = os.Stat(previous.dir)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(CurrentBuild().dir, "out", "Token.json"))
 	assert.NoError(t, err)
-	fmt.Printf("proxy: %s", proxy)
 }
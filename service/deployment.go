@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	DeploymentSuccess = "success"
+	DeploymentFailed  = "failed"
+)
+
+// Deployment records a single contract deployment
+type Deployment struct {
+	ID           string `json:"id"`
+	ContractType string `json:"contract_type"`
+	Network      string `json:"network"`
+	Caller       string `json:"caller,omitempty"`
+	Owner        string `json:"owner,omitempty"`
+	ProjectName  string `json:"project_name,omitempty"`
+	ChainID      int64  `json:"chain_id"`
+	ProxyAddress string `json:"proxy_address,omitempty"`
+	// Implementation, contract name, ABI artifact path relative to the
+	// contracts dir, tx hash and block of the proxy, read from the broadcast
+	ImplementationAddress string `json:"implementation_address,omitempty"`
+	ContractName          string `json:"contract_name,omitempty"`
+	ABI                   string `json:"abi,omitempty"`
+	TxHash                string `json:"tx_hash,omitempty"`
+	BlockNumber           uint64 `json:"block_number,omitempty"`
+	GasUsed               uint64 `json:"gas_used,omitempty"`
+	// Fee paid by the deployment transactions, in wei
+	Fee        string            `json:"fee,omitempty"`
+	Params     map[string]string `json:"params"`
+	Status     string            `json:"status"`
+	Error      string            `json:"error,omitempty"`
+	Toolchain  *Toolchain        `json:"toolchain,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	FinishedAt time.Time         `json:"finished_at"`
+}
+
+type callerKey struct{}
+
+// WithCaller returns a context recording caller as the user requesting a deployment
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+func callerFrom(ctx context.Context) string {
+	caller, _ := ctx.Value(callerKey{}).(string)
+	return caller
+}
+
+func newDeployment(ctx context.Context, spec *ContractTypeSpec, network *Network, scriptEnvVars map[string]string) *Deployment {
+	params := make(map[string]string, len(scriptEnvVars))
+	for key, value := range scriptEnvVars {
+		if key == "PRIVATE_KEY" {
+			continue
+		}
+		params[key] = value
+	}
+	return &Deployment{
+		ID:           newID(),
+		ContractType: spec.Name,
+		Network:      network.Name,
+		ChainID:      network.ChainID,
+		Caller:       callerFrom(ctx),
+		Owner:        scriptEnvVars[spec.Env["owner"]],
+		ProjectName:  scriptEnvVars[spec.Env["project_name"]],
+		Params:       params,
+		Toolchain:    toolchain,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// recordDeployment stores the outcome of a deployment
+func recordDeployment(ctx context.Context, deployment *Deployment, proxyAddress string, err error) {
+	deployment.FinishedAt = time.Now()
+	deployment.ProxyAddress = proxyAddress
+	deployment.Status = DeploymentSuccess
+	if err != nil {
+		deployment.Status = DeploymentFailed
+		deployment.Error = err.Error()
+	}
+	observeDeployment(deployment)
+	logger := Logger(ctx)
+	logger.Info("deployment finished", "status", deployment.Status, "proxy_address", proxyAddress,
+		"duration", deployment.FinishedAt.Sub(deployment.CreatedAt).Round(time.Millisecond).String())
+	if store == nil {
+		return
+	}
+	if err := store.AddDeployment(deployment); err != nil {
+		logger.Error("failed to record deployment", "error", err)
+	}
+}
+
+// DeploymentFilter selects deployments in ListDeployments. Empty fields match
+// everything; string fields match case-insensitively.
+type DeploymentFilter struct {
+	ContractType string
+	Network      string
+	Owner        string
+	Caller       string
+	Status       string
+	ProxyAddress string
+	ProjectName  string
+	From         time.Time
+	To           time.Time
+	// Sort is a field name, prefixed with "-" for descending order.
+	// Defaults to "-created_at".
+	Sort     string
+	Page     int
+	PageSize int
+}
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// deploymentSortFields lists the fields deployments can be sorted by
+var deploymentSortFields = map[string]func(a, b *Deployment) bool{
+	"created_at":    func(a, b *Deployment) bool { return a.CreatedAt.Before(b.CreatedAt) },
+	"finished_at":   func(a, b *Deployment) bool { return a.FinishedAt.Before(b.FinishedAt) },
+	"contract_type": func(a, b *Deployment) bool { return strings.ToLower(a.ContractType) < strings.ToLower(b.ContractType) },
+	"network":       func(a, b *Deployment) bool { return a.Network < b.Network },
+	"status":        func(a, b *Deployment) bool { return a.Status < b.Status },
+	"project_name":  func(a, b *Deployment) bool { return a.ProjectName < b.ProjectName },
+}
+
+// Normalize applies the paging defaults and checks the sort field
+func (f *DeploymentFilter) Normalize() error {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.PageSize < 1 {
+		f.PageSize = DefaultPageSize
+	}
+	if f.PageSize > MaxPageSize {
+		f.PageSize = MaxPageSize
+	}
+	if f.Sort == "" {
+		f.Sort = "-created_at"
+	}
+	if _, ok := deploymentSortFields[strings.TrimPrefix(f.Sort, "-")]; !ok {
+		return fmt.Errorf("unsupported sort field: %s", f.Sort)
+	}
+	return nil
+}
+
+func (f *DeploymentFilter) match(d *Deployment) bool {
+	return matchField(f.ContractType, d.ContractType) &&
+		matchField(f.Network, d.Network) &&
+		matchField(f.Owner, d.Owner) &&
+		matchField(f.Caller, d.Caller) &&
+		matchField(f.Status, d.Status) &&
+		matchField(f.ProxyAddress, d.ProxyAddress) &&
+		matchField(f.ProjectName, d.ProjectName) &&
+		(f.From.IsZero() || !d.CreatedAt.Before(f.From)) &&
+		(f.To.IsZero() || d.CreatedAt.Before(f.To))
+}
+
+func matchField(want, value string) bool {
+	return want == "" || strings.EqualFold(want, value)
+}
+
+// ListDeployments returns one page of the deployments matching filter and
+// the total number of matches
+func ListDeployments(filter DeploymentFilter) ([]*Deployment, int, error) {
+	if err := filter.Normalize(); err != nil {
+		return nil, 0, err
+	}
+	if store == nil {
+		return []*Deployment{}, 0, nil
+	}
+
+	matches := store.Deployments(filter.match)
+	less := deploymentSortFields[strings.TrimPrefix(filter.Sort, "-")]
+	if strings.HasPrefix(filter.Sort, "-") {
+		sort.SliceStable(matches, func(i, j int) bool { return less(matches[j], matches[i]) })
+	} else {
+		sort.SliceStable(matches, func(i, j int) bool { return less(matches[i], matches[j]) })
+	}
+
+	total := len(matches)
+	start := (filter.Page - 1) * filter.PageSize
+	if start > total {
+		start = total
+	}
+	end := start + filter.PageSize
+	if end > total {
+		end = total
+	}
+	return matches[start:end], total, nil
+}
+
+// GetDeployment returns the deployment with id
+func GetDeployment(id string) (*Deployment, error) {
+	if store != nil {
+		if deployment, ok := store.Deployment(id); ok {
+			return deployment, nil
+		}
+	}
+	return nil, fmt.Errorf("deployment not found: %s", id)
+}
+
+// DeploymentActions returns the actions recorded against a deployment's proxy
+func DeploymentActions(deployment *Deployment) []ActionResult {
+	if store == nil || deployment.ProxyAddress == "" {
+		return []ActionResult{}
+	}
+	results := store.Actions(deployment.ProxyAddress)
+	if results == nil {
+		results = []ActionResult{}
+	}
+	return results
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -7,24 +7,42 @@ import (
 	"github.com/joho/godotenv"
 )
 
-// LoadEnv 加载并解析 .env 文件
+// EnvFile 为服务启动目录下的 .env 文件
+const EnvFile = "./.env"
+
+// envFileKeys 记录由 .env 文件设置的变量, 进程原有的环境变量不会被覆盖
+var envFileKeys = map[string]bool{}
+
+// LoadEnv 加载并解析 .env 文件, 可重复调用: 已修改的变量会被更新, 已删除的变量会被清除
 func LoadEnv(path string) error {
-	err := godotenv.Load(path)
+	values, err := godotenv.Read(path)
 	if err != nil {
-		return fmt.Errorf("failed to load .env file err: %v. path: %v", err, path)
+		return fmt.Errorf("failed to load .env file err: %w. path: %v", err, path)
+	}
+	for key, value := range values {
+		if _, ok := os.LookupEnv(key); ok && !envFileKeys[key] {
+			continue
+		}
+		os.Setenv(key, value)
+		envFileKeys[key] = true
+	}
+	for key := range envFileKeys {
+		if _, ok := values[key]; !ok {
+			os.Unsetenv(key)
+			delete(envFileKeys, key)
+		}
 	}
 	return nil
-
 }
 
-func WriteEnv(envVars map[string]string, path string, tp contractType) error {
-	envVars["PRIVATE_KEY"] = os.Getenv("PRIVATE_KEY")
+func WriteEnv(envVars map[string]string, path string, spec *ContractTypeSpec, network *Network) error {
+	envVars["PRIVATE_KEY"] = network.PrivateKey()
 
-	if tp == IAO {
-		envVars["XAAIAO_NFT_HOLDER_CONTRACT"] = XAAIAO_NFT_HOLDER_CONTRACT
-	}
-	if tp == STAKING {
-		envVars["DBC_AI_PROXY"] = DBC_AI_PROXY
+	// A constant mapped to a request field is only a default
+	for key, value := range spec.Constants {
+		if _, ok := envVars[key]; !ok {
+			envVars[key] = network.constant(key, value)
+		}
 	}
 
 	// 构建 .env 文件内容
@@ -33,7 +51,7 @@ func WriteEnv(envVars map[string]string, path string, tp contractType) error {
 		envContent += fmt.Sprintf("%s=%s\n", key, value)
 	}
 	// 将内容写入 .env 文件
-	err := os.WriteFile(path, []byte(envContent), 0644)
+	err := os.WriteFile(path, []byte(envContent), 0600)
 	if err != nil {
 		return fmt.Errorf("failed to write .env file: %v", err)
 	}
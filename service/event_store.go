@@ -0,0 +1,270 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// checkpointFile is the name of the indexer checkpoint file in the events dir
+// of each network
+const checkpointFile = "checkpoint.json"
+
+// eventStore persists the indexed events in an append-only JSON lines file
+// per network and contract, with the indexer checkpoint of each network, in
+// the events dir next to the store file, so indexing does not rewrite the
+// store. The events are kept in memory, by file, for the queries.
+type eventStore struct {
+	dir         string
+	events      map[string][]IndexedEvent
+	checkpoints map[string]IndexerCheckpoint
+}
+
+// openEventStore loads the event and checkpoint files of the store at path,
+// falling back to the legacy checkpoints of the store file for the networks
+// without one. Events above the checkpoint of their contract were appended
+// without the checkpoint being saved, and are dropped as the indexer indexes
+// them again.
+func openEventStore(path string, legacy []IndexerCheckpoint) (*eventStore, error) {
+	es := &eventStore{
+		dir:         filepath.Join(filepath.Dir(path), "events"),
+		events:      map[string][]IndexedEvent{},
+		checkpoints: map[string]IndexerCheckpoint{},
+	}
+	for _, checkpoint := range legacy {
+		es.checkpoints[checkpoint.Network] = checkpoint
+	}
+	files, err := filepath.Glob(filepath.Join(es.dir, "*", checkpointFile))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read checkpoint file: %v", err)
+		}
+		var checkpoint IndexerCheckpoint
+		if err := json.Unmarshal(content, &checkpoint); err != nil {
+			return nil, fmt.Errorf("failed to parse checkpoint file: %v. path: %v", err, file)
+		}
+		es.checkpoints[checkpoint.Network] = checkpoint
+	}
+
+	files, err = filepath.Glob(filepath.Join(es.dir, "*", "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	indexed := map[string]uint64{}
+	for _, checkpoint := range es.checkpoints {
+		for contract, block := range checkpoint.Contracts {
+			indexed[eventKey(checkpoint.Network, contract)] = block
+		}
+	}
+
+	for _, file := range files {
+		network, err := url.PathUnescape(filepath.Base(filepath.Dir(file)))
+		if err != nil {
+			continue
+		}
+		key := eventKey(network, strings.TrimSuffix(filepath.Base(file), ".jsonl"))
+		events, complete, err := readEventFile(file)
+		if err != nil {
+			return nil, err
+		}
+		last, ok := indexed[key]
+		kept := events[:0]
+		for _, event := range events {
+			if ok && event.BlockNumber <= last {
+				kept = append(kept, event)
+			}
+		}
+		if len(kept) > 0 {
+			es.events[key] = kept
+		}
+		if !complete || len(kept) < len(events) {
+			if err := es.rewrite(key); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return es, nil
+}
+
+// readEventFile reads the events of file. complete is false when the file
+// ends with a line cut by a crash, which is ignored.
+func readEventFile(file string) (events []IndexedEvent, complete bool, err error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read events file: %v", err)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+	for scanner.Scan() {
+		var event IndexedEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return events, false, nil
+		}
+		events = append(events, event)
+	}
+	return events, len(content) == 0 || content[len(content)-1] == '\n', nil
+}
+
+// eventKey returns the key of the events file of contract on network
+func eventKey(network, contract string) string {
+	return url.PathEscape(network) + "/" + strings.ToLower(contract)
+}
+
+// file returns the path of the events file of key, in a dir per network
+func (es *eventStore) file(key string) string {
+	return filepath.Join(es.dir, filepath.FromSlash(key)+".jsonl")
+}
+
+// checkpoint returns the indexer checkpoint of network
+func (es *eventStore) checkpoint(network string) (IndexerCheckpoint, bool) {
+	checkpoint, ok := es.checkpoints[network]
+	if !ok {
+		return IndexerCheckpoint{}, false
+	}
+	return checkpoint.clone(), true
+}
+
+// setCheckpoint writes the checkpoint file of its network atomically
+func (es *eventStore) setCheckpoint(checkpoint IndexerCheckpoint) error {
+	content, err := json.MarshalIndent(checkpoint, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode checkpoint: %v", err)
+	}
+	file := filepath.Join(es.dir, url.PathEscape(checkpoint.Network), checkpointFile)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create events dir: %v", err)
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint file: %v", err)
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		return err
+	}
+	es.checkpoints[checkpoint.Network] = checkpoint.clone()
+	return nil
+}
+
+// match returns the events accepted by match, by network and contract
+func (es *eventStore) match(match func(*IndexedEvent) bool) []IndexedEvent {
+	keys := make([]string, 0, len(es.events))
+	for key := range es.events {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var events []IndexedEvent
+	for _, key := range keys {
+		for i := range es.events[key] {
+			if match(&es.events[key][i]) {
+				events = append(events, es.events[key][i])
+			}
+		}
+	}
+	return events
+}
+
+// append appends events to the files of their contracts
+func (es *eventStore) append(events []IndexedEvent) error {
+	lines := map[string]*bytes.Buffer{}
+	var keys []string
+	for _, event := range events {
+		key := eventKey(event.Network, event.Contract)
+		if lines[key] == nil {
+			lines[key] = &bytes.Buffer{}
+			keys = append(keys, key)
+		}
+		if err := json.NewEncoder(lines[key]).Encode(event); err != nil {
+			return fmt.Errorf("failed to encode event: %v", err)
+		}
+	}
+	for _, key := range keys {
+		if err := os.MkdirAll(filepath.Dir(es.file(key)), 0755); err != nil {
+			return fmt.Errorf("failed to create events dir: %v", err)
+		}
+		f, err := os.OpenFile(es.file(key), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open events file: %v", err)
+		}
+		_, err = f.Write(lines[key].Bytes())
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write events file: %v", err)
+		}
+	}
+	for _, event := range events {
+		key := eventKey(event.Network, event.Contract)
+		es.events[key] = append(es.events[key], event)
+	}
+	return nil
+}
+
+// rollback drops the events of network above block, rewriting the files of
+// the contracts that had some
+func (es *eventStore) rollback(network string, block uint64) error {
+	for key, events := range es.events {
+		if !strings.HasPrefix(key, url.PathEscape(network)+"/") {
+			continue
+		}
+		kept := events[:0]
+		for _, event := range events {
+			if event.BlockNumber <= block {
+				kept = append(kept, event)
+			}
+		}
+		if len(kept) == len(events) {
+			continue
+		}
+		es.events[key] = kept
+		if err := es.rewrite(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replace writes events in place of those recorded
+func (es *eventStore) replace(events []IndexedEvent) error {
+	es.events = map[string][]IndexedEvent{}
+	for _, event := range events {
+		key := eventKey(event.Network, event.Contract)
+		es.events[key] = append(es.events[key], event)
+	}
+	for key := range es.events {
+		if err := es.rewrite(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rewrite writes the file of key atomically from the events in memory
+func (es *eventStore) rewrite(key string) error {
+	var content bytes.Buffer
+	encoder := json.NewEncoder(&content)
+	for _, event := range es.events[key] {
+		if err := encoder.Encode(event); err != nil {
+			return fmt.Errorf("failed to encode event: %v", err)
+		}
+	}
+	file := es.file(key)
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("failed to create events dir: %v", err)
+	}
+	tmp := file + ".tmp"
+	if err := os.WriteFile(tmp, content.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write events file: %v", err)
+	}
+	return os.Rename(tmp, file)
+}
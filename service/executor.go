@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Command describes a subprocess run by an Executor
+type Command struct {
+	Name string
+	Args []string
+	Dir  string
+	Env  []string
+	// Image pins the toolchain image when run by a ContainerExecutor
+	Image string
+	// Output, when set, receives the combined output as it is produced
+	Output io.Writer
+}
+
+// String renders the command line with secrets redacted
+func (c Command) String() string {
+	return strings.Join(append([]string{filepath.Base(c.Name)}, redactArgs(c.Args)...), " ")
+}
+
+// Executor runs the toolchain subprocesses of a deployment
+type Executor interface {
+	// Run runs cmd to completion and returns its combined output
+	Run(ctx context.Context, cmd Command) ([]byte, error)
+}
+
+// executor runs every forge and cast invocation
+var executor Executor = ExecExecutor{}
+
+// SetExecutor replaces the executor used by the service
+func SetExecutor(e Executor) {
+	executor = e
+}
+
+// ExecExecutor runs commands directly on the host
+type ExecExecutor struct{}
+
+func (ExecExecutor) Run(ctx context.Context, cmd Command) ([]byte, error) {
+	c := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	c.Dir = cmd.Dir
+	c.Env = cmd.Env
+	if cmd.Output == nil {
+		return c.CombinedOutput()
+	}
+
+	var output bytes.Buffer
+	c.Stdout = io.MultiWriter(&output, cmd.Output)
+	c.Stderr = c.Stdout
+	err := c.Run()
+	return output.Bytes(), err
+}
+
+// RecordedCall is a command run through a RecordingExecutor and its result
+type RecordedCall struct {
+	Command Command
+	Output  []byte
+	Err     error
+}
+
+// RecordingExecutor runs commands through Next and records every call. When
+// Dir is set the output of each call is also captured to a file there, to be
+// replayed later with a FakeExecutor.
+type RecordingExecutor struct {
+	Next Executor
+	Dir  string
+
+	mu    sync.Mutex
+	calls []RecordedCall
+}
+
+func (r *RecordingExecutor) Run(ctx context.Context, cmd Command) ([]byte, error) {
+	output, err := r.Next.Run(ctx, cmd)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, RecordedCall{Command: cmd, Output: output, Err: err})
+	if r.Dir != "" {
+		name := fmt.Sprintf("%03d-%s-%s.txt", len(r.calls), filepath.Base(cmd.Name), firstArg(cmd.Args))
+		content := "# " + cmd.String() + "\n" + string(output)
+		if writeErr := os.WriteFile(filepath.Join(r.Dir, name), []byte(content), 0600); writeErr != nil {
+			return output, fmt.Errorf("failed to capture output: %v", writeErr)
+		}
+	}
+	return output, err
+}
+
+// Calls returns the recorded calls
+func (r *RecordingExecutor) Calls() []RecordedCall {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedCall(nil), r.calls...)
+}
+
+// FakeResponse is a scripted result for the commands it matches
+type FakeResponse struct {
+	// Name matches the base name of the binary, e.g. forge
+	Name string
+	// Args matches a prefix of the arguments
+	Args   []string
+	Output []byte
+	Err    error
+}
+
+func (f FakeResponse) matches(cmd Command) bool {
+	if filepath.Base(cmd.Name) != f.Name || len(cmd.Args) < len(f.Args) {
+		return false
+	}
+	for i, arg := range f.Args {
+		if cmd.Args[i] != arg {
+			return false
+		}
+	}
+	return true
+}
+
+// FakeExecutor replays scripted responses without running anything. The
+// first response matching a command is used; unmatched commands fail.
+type FakeExecutor struct {
+	Responses []FakeResponse
+	// OnRun is called with every command before responding
+	OnRun func(cmd Command)
+
+	mu    sync.Mutex
+	calls []Command
+}
+
+func (f *FakeExecutor) Run(ctx context.Context, cmd Command) ([]byte, error) {
+	f.mu.Lock()
+	f.calls = append(f.calls, cmd)
+	f.mu.Unlock()
+
+	if f.OnRun != nil {
+		f.OnRun(cmd)
+	}
+	for _, resp := range f.Responses {
+		if resp.matches(cmd) {
+			if cmd.Output != nil {
+				_, _ = cmd.Output.Write(resp.Output)
+			}
+			return resp.Output, resp.Err
+		}
+	}
+	return nil, fmt.Errorf("unexpected command: %s", cmd)
+}
+
+// Calls returns the commands run so far
+func (f *FakeExecutor) Calls() []Command {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Command(nil), f.calls...)
+}
+
+// redactArgs masks the values of secret flags
+func redactArgs(args []string) []string {
+	redacted := append([]string(nil), args...)
+	for i := 0; i < len(redacted)-1; i++ {
+		if redacted[i] == "--private-key" {
+			redacted[i+1] = "***"
+		}
+	}
+	return redacted
+}
+
+func firstArg(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMinDeployerBalance is the deployer balance in wei below which the
+// service reports not ready unless limits.min_deployer_balance is set
+const DefaultMinDeployerBalance = "100000000000000000"
+
+var (
+	// ReadinessTTL is how long a readiness result is reused, so probes do
+	// not hit the RPCs and verifiers on every request
+	ReadinessTTL = 10 * time.Second
+
+	verifierClient = &http.Client{Timeout: 10 * time.Second}
+
+	readiness struct {
+		mu      sync.Mutex
+		checked time.Time
+		result  *Readiness
+	}
+	// deployerAddresses caches the address derived from each deployer key
+	deployerAddresses sync.Map
+)
+
+// Readiness is the outcome of the checks on the deploy path
+type Readiness struct {
+	Ready     bool          `json:"ready"`
+	Checks    []CheckResult `json:"checks"`
+	CheckedAt time.Time     `json:"checked_at"`
+}
+
+// Redacted returns the readiness with only the names and outcomes of the
+// checks, whose details and errors may hold RPC URLs with API keys, the
+// deployer address and build output
+func (r Readiness) Redacted() Readiness {
+	checks := make([]CheckResult, len(r.Checks))
+	for i, check := range r.Checks {
+		checks[i] = CheckResult{Name: check.Name, OK: check.OK}
+	}
+	r.Checks = checks
+	return r
+}
+
+// CheckResult is the outcome of a single readiness check
+type CheckResult struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// CheckReadiness checks that the toolchain is present, the contracts
+// compile, and that every network's RPC answers with the expected chain ID,
+// its deployer holds at least limits.min_deployer_balance and its verifier is
+// reachable. Results are cached for ReadinessTTL.
+func CheckReadiness(ctx context.Context) *Readiness {
+	readiness.mu.Lock()
+	defer readiness.mu.Unlock()
+	if readiness.result != nil && time.Since(readiness.checked) < ReadinessTTL {
+		return readiness.result
+	}
+
+	result := &Readiness{Ready: true, CheckedAt: time.Now()}
+	add := func(name, detail string, err error) {
+		check := CheckResult{Name: name, OK: err == nil, Detail: detail}
+		if err != nil {
+			check.Error = err.Error()
+			result.Ready = false
+		}
+		result.Checks = append(result.Checks, check)
+	}
+
+	if Draining() {
+		add("jobs", "", ErrShuttingDown)
+	}
+
+	tc := CurrentToolchain()
+	for _, name := range []string{"forge", "make"} {
+		bin, err := checkBinary(tc, name)
+		add(name, bin, err)
+	}
+	build, err := checkBuild()
+	add("build", build, err)
+
+	for _, network := range Networks() {
+		add(network.Name+"/rpc", network.RPCURL, checkChainID(network))
+		deployer, err := checkDeployer(ctx, network)
+		add(network.Name+"/deployer", deployer, err)
+		if network.VerifierURL != "" {
+			add(network.Name+"/verifier", network.VerifierURL, checkVerifier(ctx, network.VerifierURL))
+		}
+	}
+
+	readiness.checked = time.Now()
+	readiness.result = result
+	return result
+}
+
+func checkBinary(tc *Toolchain, name string) (string, error) {
+	if tc == nil {
+		return "", fmt.Errorf("toolchain not discovered")
+	}
+	bin := map[string]string{"forge": tc.Forge, "make": tc.Make}[name]
+	if bin == "" {
+		return "", fmt.Errorf("%s not found", name)
+	}
+	// Container toolchains are probed inside the image at startup
+	if tc.Runner != "host" {
+		return bin, nil
+	}
+	if _, err := exec.LookPath(bin); err != nil {
+		return bin, fmt.Errorf("%s not executable: %v", name, err)
+	}
+	return bin, nil
+}
+
+func checkBuild() (string, error) {
+	build := CurrentBuild()
+	if build == nil {
+		return "", fmt.Errorf("contracts not built")
+	}
+	switch build.Status {
+	case BuildReady:
+		return build.Key, nil
+	case BuildFailed:
+		return build.Key, fmt.Errorf("contracts failed to compile: %s", build.Error)
+	default:
+		return build.Key, fmt.Errorf("contracts %s", build.Status)
+	}
+}
+
+func checkChainID(network *Network) error {
+	id, err := chainID(network.RPCURL)
+	if err != nil {
+		return err
+	}
+	if id != network.ChainID {
+		return fmt.Errorf("chain ID %d, expected %d", id, network.ChainID)
+	}
+	return nil
+}
+
+func checkDeployer(ctx context.Context, network *Network) (string, error) {
+	key := network.PrivateKey()
+	if key == "" {
+		return "", fmt.Errorf("deployer key not loaded")
+	}
+	address, err := cachedDeployerAddress(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	var hex string
+	if err := rpcCall(network.RPCURL, "eth_getBalance", &hex, address, "latest"); err != nil {
+		return address, err
+	}
+	balance, ok := new(big.Int).SetString(strings.TrimPrefix(hex, "0x"), 16)
+	if !ok {
+		return address, fmt.Errorf("invalid balance: %s", hex)
+	}
+	observeDeployerBalance(network.Name, address, balance)
+	detail := fmt.Sprintf("%s balance %s wei", address, balance)
+	// Validated as an amount in wei
+	minBalance, _ := new(big.Int).SetString(CurrentConfig().Limits.MinDeployerBalance, 10)
+	if balance.Cmp(minBalance) < 0 {
+		return detail, fmt.Errorf("balance below %s wei", minBalance)
+	}
+	return detail, nil
+}
+
+func cachedDeployerAddress(ctx context.Context, key string) (string, error) {
+	if address, ok := deployerAddresses.Load(key); ok {
+		return address.(string), nil
+	}
+	address, err := deployerAddress(ctx, key)
+	if err != nil {
+		return "", err
+	}
+	deployerAddresses.Store(key, address)
+	return address, nil
+}
+
+// checkVerifier treats any response below 500 from the verifier API as reachable
+func checkVerifier(ctx context.Context, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := verifierClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("verifier returned %s", resp.Status)
+	}
+	return nil
+}
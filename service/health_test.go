@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckReadiness runs the readiness checks against a fake RPC node and
+// verifier, and checks a low deployer balance is reported
+func TestCheckReadiness(t *testing.T) {
+	var balance atomic.Value
+	balance.Store("0x16345785d8a0000") // 0.1 ether
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		result := map[string]string{"eth_chainId": "0x12f5b72", "eth_getBalance": balance.Load().(string)}[req.Method]
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer node.Close()
+	verifier := httptest.NewServer(http.NotFoundHandler())
+	defer verifier.Close()
+
+	fake := &FakeExecutor{Responses: []FakeResponse{
+		{Name: "cast", Args: []string{"wallet", "address"}, Output: []byte("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266\n")},
+	}}
+	setupTest(t, fake)
+
+	originalNetworks, originalToolchain, originalTTL := networks, toolchain, ReadinessTTL
+	networks = map[string]*Network{DefaultNetwork: {
+		Name:        DefaultNetwork,
+		RPCURL:      node.URL,
+		ChainID:     DBC_MAINNET_CHAIN_ID,
+		VerifierURL: verifier.URL,
+	}}
+	toolchain = &Toolchain{Runner: "host", Forge: "/bin/sh", Make: "/bin/sh"}
+	builds.current = &BuildInfo{Key: "abc", Status: BuildReady}
+	ReadinessTTL = 0
+	t.Cleanup(func() {
+		networks, toolchain, ReadinessTTL = originalNetworks, originalToolchain, originalTTL
+		builds.current = nil
+		readiness.result = nil
+	})
+
+	result := CheckReadiness(context.Background())
+	assert.True(t, result.Ready, "%+v", result.Checks)
+	assert.Len(t, result.Checks, 6)
+
+	balance.Store("0x0")
+	builds.current.Status = BuildFailed
+	result = CheckReadiness(context.Background())
+	assert.False(t, result.Ready)
+	var failed []string
+	for _, check := range result.Checks {
+		if !check.OK {
+			failed = append(failed, check.Name)
+		}
+	}
+	assert.Equal(t, []string{"build", DefaultNetwork + "/deployer"}, failed)
+
+	// The unauthenticated probe only gets the names and outcomes
+	redacted := result.Redacted()
+	assert.False(t, redacted.Ready)
+	require.Len(t, redacted.Checks, len(result.Checks))
+	for i, check := range redacted.Checks {
+		assert.Equal(t, CheckResult{Name: result.Checks[i].Name, OK: result.Checks[i].OK}, check)
+	}
+	assert.NotEmpty(t, result.Checks[0].Detail+result.Checks[0].Error)
+	require.Len(t, fake.Calls(), 1)
+	// The key is read from the env, not the argv
+	assert.Equal(t, []string{"wallet", "address"}, fake.Calls()[0].Args)
+	assert.Contains(t, fake.Calls()[0].Env, "ETH_PRIVATE_KEY=0xkey")
+}
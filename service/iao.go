@@ -0,0 +1,480 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IAOContractType is the contract type of XAAIAO deployments
+const IAOContractType = "IAO"
+
+// IAO statuses, read from the deposit period at the latest block
+const (
+	IAOPending = "pending"
+	IAOActive  = "active"
+	IAOEnded   = "ended"
+)
+
+// IAO results, known once the deposit period ended
+const (
+	IAOSuccess = "success"
+	IAOFailed  = "failed"
+)
+
+// Funding statuses of the IAO reward token
+const (
+	RewardUnset       = "unset"
+	RewardFunded      = "funded"
+	RewardUnderfunded = "underfunded"
+)
+
+// Webhook events of the IAO lifecycle
+const (
+	EventIAOStarted = "iao.started"
+	EventIAOEnded   = "iao.ended"
+)
+
+const zeroAddress = "0x0000000000000000000000000000000000000000"
+
+// DefaultTopDepositors is the number of depositors listed in an IAO summary
+const DefaultTopDepositors = 10
+
+// ErrIAONotFound is returned for addresses without a recorded IAO deployment
+var ErrIAONotFound = errors.New("IAO not found")
+
+// IAOSummary aggregates the on-chain state of a deployed IAO
+type IAOSummary struct {
+	DeploymentID string `json:"deployment_id"`
+	Contract     string `json:"contract"`
+	Network      string `json:"network"`
+	ProjectName  string `json:"project_name,omitempty"`
+	// Block is the block the state was read at
+	Block     uint64    `json:"block"`
+	BlockTime time.Time `json:"block_time"`
+	// Status is pending, active or ended
+	Status           string    `json:"status"`
+	StartTime        time.Time `json:"start_time"`
+	EndTime          time.Time `json:"end_time"`
+	RemainingSeconds uint64    `json:"remaining_seconds"`
+	TokenIn          string    `json:"token_in"`
+	// TotalDeposited is in the smallest unit of TokenIn
+	TotalDeposited string `json:"total_deposited"`
+	Depositors     uint64 `json:"depositors"`
+	// Result is success or failed once the IAO ended. ResultError is set
+	// instead when the contract could not tell, e.g. when its price oracle
+	// fails.
+	Result      string `json:"result,omitempty"`
+	ResultError string `json:"result_error,omitempty"`
+	RewardToken string `json:"reward_token"`
+	TotalReward string `json:"total_reward"`
+	// RewardBalance is the reward token balance of the IAO, RewardStatus
+	// whether it covers TotalReward
+	RewardBalance string         `json:"reward_balance"`
+	RewardStatus  string         `json:"reward_status"`
+	TopDepositors []IAODepositor `json:"top_depositors"`
+	// IndexedBlock is the last block of the indexed deposits
+	IndexedBlock *uint64 `json:"indexed_block"`
+	// Error is set in IAO lists for IAOs whose state could not be read
+	Error string `json:"error,omitempty"`
+}
+
+// IAODepositor is a depositor of an IAO
+type IAODepositor struct {
+	Address string `json:"address"`
+	// Amount is the deposit recorded by the contract
+	Amount string `json:"amount"`
+	// Deposits is the number of deposit transactions
+	Deposits int `json:"deposits"`
+}
+
+// erc20BalanceOf reads the reward token balance, the reward token not being
+// one of the compiled contracts
+var erc20BalanceOf = &ABIEntry{
+	Type:            "function",
+	Name:            "balanceOf",
+	Inputs:          []ABIParam{{Name: "account", Type: "address"}},
+	Outputs:         []ABIParam{{Type: "uint256"}},
+	StateMutability: "view",
+}
+
+// GetIAO returns the summary of the IAO deployed at address, listing top
+// depositors
+func GetIAO(ctx context.Context, address string, top int) (*IAOSummary, error) {
+	deployment := findDeployment(address, "", IAOContractType)
+	if deployment == nil {
+		return nil, fmt.Errorf("%w: no IAO deployment recorded at %s", ErrIAONotFound, address)
+	}
+	return iaoSummary(ctx, deployment, top)
+}
+
+// ListIAOs returns the summaries of the deployed IAOs, newest first. IAOs
+// whose state could not be read carry the error.
+func ListIAOs(ctx context.Context, top int) []IAOSummary {
+	summaries := []IAOSummary{}
+	deployments := iaoDeployments("")
+	for i := len(deployments) - 1; i >= 0; i-- {
+		deployment := deployments[i]
+		summary, err := iaoSummary(ctx, deployment, top)
+		if err != nil {
+			summary = &IAOSummary{
+				DeploymentID: deployment.ID,
+				Contract:     deployment.ProxyAddress,
+				Network:      deployment.Network,
+				ProjectName:  deployment.ProjectName,
+				Error:        err.Error(),
+			}
+		}
+		summaries = append(summaries, *summary)
+	}
+	return summaries
+}
+
+// iaoDeployments returns the latest successful IAO deployment of each
+// address, on network when set, oldest first
+func iaoDeployments(network string) []*Deployment {
+	if store == nil {
+		return nil
+	}
+	matches := store.Deployments(func(d *Deployment) bool {
+		return d.Status == DeploymentSuccess && d.ProxyAddress != "" &&
+			strings.EqualFold(d.ContractType, IAOContractType) && matchField(network, d.Network)
+	})
+	latest := map[string]int{}
+	var deployments []*Deployment
+	for _, deployment := range matches {
+		key := strings.ToLower(deployment.Network + "/" + deployment.ProxyAddress)
+		if i, ok := latest[key]; ok {
+			deployments[i] = deployment
+			continue
+		}
+		latest[key] = len(deployments)
+		deployments = append(deployments, deployment)
+	}
+	return deployments
+}
+
+// iaoSummary reads the state of the IAO of deployment at the latest block
+func iaoSummary(ctx context.Context, deployment *Deployment, top int) (*IAOSummary, error) {
+	network, err := LookupNetwork(deployment.Network)
+	if err != nil {
+		return nil, err
+	}
+	artifact, _, err := resolveArtifact(ContractCall{Address: deployment.ProxyAddress, Network: deployment.Network, ContractType: deployment.ContractType})
+	if err != nil {
+		return nil, err
+	}
+	abi, err := LoadABI(artifact)
+	if err != nil {
+		return nil, err
+	}
+	// All reads are pinned to one block to stay consistent
+	header, err := blockHeader(network.RPCURL, "latest")
+	if err != nil {
+		return nil, err
+	}
+	number, err := parseHexInt(header.Number)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block number %q: %v", header.Number, err)
+	}
+	timestamp, err := parseHexInt(header.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("invalid block timestamp %q: %v", header.Timestamp, err)
+	}
+	r := &contractReader{ctx: ctx, network: network, contract: deployment.ProxyAddress, abi: abi, block: header.Number}
+
+	summary := &IAOSummary{
+		DeploymentID:  deployment.ID,
+		Contract:      deployment.ProxyAddress,
+		Network:       network.Name,
+		ProjectName:   deployment.ProjectName,
+		Block:         uint64(number),
+		BlockTime:     time.Unix(timestamp, 0).UTC(),
+		TopDepositors: []IAODepositor{},
+	}
+	start, err := r.uint("startTime")
+	if err != nil {
+		return nil, err
+	}
+	end, err := r.uint("endTime")
+	if err != nil {
+		return nil, err
+	}
+	summary.StartTime = time.Unix(start.Int64(), 0).UTC()
+	summary.EndTime = time.Unix(end.Int64(), 0).UTC()
+	switch now := big.NewInt(timestamp); {
+	case now.Cmp(start) < 0:
+		summary.Status = IAOPending
+	case now.Cmp(end) <= 0:
+		summary.Status = IAOActive
+		summary.RemainingSeconds = new(big.Int).Sub(end, now).Uint64()
+	default:
+		summary.Status = IAOEnded
+	}
+
+	if summary.TokenIn, err = r.address("tokenIn"); err != nil {
+		return nil, err
+	}
+	deposited, err := r.uint("totalDepositedTokenIn")
+	if err != nil {
+		return nil, err
+	}
+	summary.TotalDeposited = deposited.String()
+	depositors, err := r.uint("totalJoinedAddress")
+	if err != nil {
+		return nil, err
+	}
+	summary.Depositors = depositors.Uint64()
+
+	if summary.Status == IAOEnded {
+		succeeded, err := r.bool("isSuccess")
+		switch {
+		case err != nil:
+			summary.ResultError = err.Error()
+		case succeeded:
+			summary.Result = IAOSuccess
+		default:
+			summary.Result = IAOFailed
+		}
+	}
+
+	if err := readRewardFunding(r, summary); err != nil {
+		return nil, err
+	}
+	if summary.TopDepositors, err = topDepositors(r, top); err != nil {
+		return nil, err
+	}
+	if block, ok := IndexedBlock(network.Name, deployment.ProxyAddress); ok {
+		summary.IndexedBlock = &block
+	}
+	return summary, nil
+}
+
+// readRewardFunding reads whether the reward token balance of the IAO covers
+// its total reward
+func readRewardFunding(r *contractReader, summary *IAOSummary) error {
+	totalReward, err := r.uint("totalReward")
+	if err != nil {
+		return err
+	}
+	summary.TotalReward = totalReward.String()
+	if summary.RewardToken, err = r.address("rewardToken"); err != nil {
+		return err
+	}
+	if summary.RewardToken == zeroAddress {
+		summary.RewardToken, summary.RewardBalance, summary.RewardStatus = "", "0", RewardUnset
+		return nil
+	}
+	outputs, err := callFunction(r.ctx, r.network, summary.RewardToken, erc20BalanceOf, []interface{}{r.contract}, r.block)
+	if err != nil {
+		return fmt.Errorf("failed to read reward token balance: %v", err)
+	}
+	balance, err := uintValue(outputs)
+	if err != nil {
+		return err
+	}
+	summary.RewardBalance = balance.String()
+	summary.RewardStatus = RewardFunded
+	if balance.Cmp(totalReward) < 0 {
+		summary.RewardStatus = RewardUnderfunded
+	}
+	return nil
+}
+
+// topDepositors returns the top depositors of the IAO, largest first.
+// XAAIAO emits DepositTokenIn twice per deposit, so the indexed events only
+// rank the depositors and the amounts are read from userDeposits.
+func topDepositors(r *contractReader, top int) ([]IAODepositor, error) {
+	depositors := []IAODepositor{}
+	if store == nil || top <= 0 {
+		return depositors, nil
+	}
+	totals := map[string]*big.Int{}
+	txs := map[string]map[string]bool{}
+	for _, event := range store.Events(func(e *IndexedEvent) bool {
+		return e.Event == "DepositTokenIn" && e.Network == r.network.Name && strings.EqualFold(e.Contract, r.contract)
+	}) {
+		user, _ := event.Arg("user")
+		amount, _ := event.Arg("amount")
+		address, _ := user.(string)
+		value, ok := new(big.Int).SetString(fmt.Sprint(amount), 10)
+		if address == "" || !ok {
+			continue
+		}
+		if totals[address] == nil {
+			totals[address], txs[address] = new(big.Int), map[string]bool{}
+		}
+		totals[address].Add(totals[address], value)
+		txs[address][event.TxHash] = true
+	}
+
+	for address := range totals {
+		depositors = append(depositors, IAODepositor{Address: address, Deposits: len(txs[address])})
+	}
+	sort.Slice(depositors, func(i, j int) bool {
+		if c := totals[depositors[i].Address].Cmp(totals[depositors[j].Address]); c != 0 {
+			return c > 0
+		}
+		return depositors[i].Address < depositors[j].Address
+	})
+	depositors = depositors[:min(top, len(depositors))]
+
+	amounts := make(map[string]*big.Int, len(depositors))
+	for i := range depositors {
+		amount, err := r.uint("userDeposits", depositors[i].Address)
+		if err != nil {
+			return nil, err
+		}
+		amounts[depositors[i].Address] = amount
+		depositors[i].Amount = amount.String()
+	}
+	sort.SliceStable(depositors, func(i, j int) bool {
+		return amounts[depositors[i].Address].Cmp(amounts[depositors[j].Address]) > 0
+	})
+	return depositors, nil
+}
+
+// iaoWatch holds the IAOs watchIAOs saw since the service started
+var iaoWatch struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// watchIAOs posts the start and the end of the IAOs deployed on network to
+// the webhook, once each. An IAO first seen already started or ended without
+// a notification recorded, such as when the webhook is first set, has its
+// events seeded instead of sent.
+func watchIAOs(ctx context.Context, network *Network) {
+	if !WebhooksEnabled() {
+		return
+	}
+	for _, deployment := range iaoDeployments(network.Name) {
+		if store.Notified(EventIAOEnded, network.Name, deployment.ProxyAddress) {
+			continue
+		}
+		summary, err := iaoSummary(ctx, deployment, DefaultTopDepositors)
+		if err != nil {
+			Logger(ctx).Warn("failed to read IAO", "contract", deployment.ProxyAddress, "error", err)
+			continue
+		}
+		firstSeen := markIAOSeen(network.Name, deployment.ProxyAddress)
+		if summary.Status == IAOPending {
+			continue
+		}
+		events := []string{EventIAOStarted}
+		if summary.Status == IAOEnded {
+			events = append(events, EventIAOEnded)
+		}
+		if firstSeen && !store.Notified(EventIAOStarted, network.Name, deployment.ProxyAddress) {
+			for _, event := range events {
+				if err := store.AddNotification(Notification{Event: event, Network: network.Name, Contract: deployment.ProxyAddress, Seeded: true}); err != nil {
+					Logger(ctx).Warn("failed to seed notification", "event", event, "contract", deployment.ProxyAddress, "error", err)
+				}
+			}
+			continue
+		}
+		for _, event := range events {
+			err := notify(ctx, Notification{Event: event, Network: network.Name, Contract: deployment.ProxyAddress, Data: summary})
+			if err != nil {
+				Logger(ctx).Warn("failed to send webhook", "event", event, "contract", deployment.ProxyAddress, "error", err)
+				break
+			}
+		}
+	}
+}
+
+// markIAOSeen records the IAO as seen, reporting whether it is the first time
+func markIAOSeen(network, contract string) bool {
+	iaoWatch.mu.Lock()
+	defer iaoWatch.mu.Unlock()
+	if iaoWatch.seen == nil {
+		iaoWatch.seen = map[string]bool{}
+	}
+	key := network + "/" + strings.ToLower(contract)
+	if iaoWatch.seen[key] {
+		return false
+	}
+	iaoWatch.seen[key] = true
+	return true
+}
+
+// contractReader reads a contract at a block
+type contractReader struct {
+	ctx      context.Context
+	network  *Network
+	contract string
+	abi      ABI
+	block    string
+}
+
+func (r *contractReader) call(name string, args ...interface{}) ([]ABIValue, error) {
+	function, err := r.abi.Function(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCall, err)
+	}
+	outputs, err := callFunction(r.ctx, r.network, r.contract, function, args, r.block)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return outputs, nil
+}
+
+func (r *contractReader) uint(name string, args ...interface{}) (*big.Int, error) {
+	outputs, err := r.call(name, args...)
+	if err != nil {
+		return nil, err
+	}
+	n, err := uintValue(outputs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return n, nil
+}
+
+func (r *contractReader) address(name string) (string, error) {
+	outputs, err := r.call(name)
+	if err != nil {
+		return "", err
+	}
+	if len(outputs) == 0 {
+		return "", fmt.Errorf("%s: no address returned", name)
+	}
+	address, ok := outputs[0].Value.(string)
+	if !ok {
+		return "", fmt.Errorf("%s: no address returned", name)
+	}
+	return address, nil
+}
+
+func (r *contractReader) bool(name string) (bool, error) {
+	outputs, err := r.call(name)
+	if err != nil {
+		return false, err
+	}
+	if len(outputs) == 0 {
+		return false, fmt.Errorf("%s: no bool returned", name)
+	}
+	value, ok := outputs[0].Value.(bool)
+	if !ok {
+		return false, fmt.Errorf("%s: no bool returned", name)
+	}
+	return value, nil
+}
+
+// uintValue returns the first output as an integer
+func uintValue(outputs []ABIValue) (*big.Int, error) {
+	if len(outputs) == 0 {
+		return nil, fmt.Errorf("no value returned")
+	}
+	value, _ := outputs[0].Value.(string)
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid integer %v", outputs[0].Value)
+	}
+	return n, nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testIAOABI = `[
+	{"type": "function", "name": "startTime", "inputs": [], "outputs": [{"type": "uint256"}], "stateMutability": "view"},
+	{"type": "function", "name": "endTime", "inputs": [], "outputs": [{"type": "uint256"}], "stateMutability": "view"},
+	{"type": "function", "name": "tokenIn", "inputs": [], "outputs": [{"type": "address"}], "stateMutability": "view"},
+	{"type": "function", "name": "rewardToken", "inputs": [], "outputs": [{"type": "address"}], "stateMutability": "view"},
+	{"type": "function", "name": "totalReward", "inputs": [], "outputs": [{"type": "uint256"}], "stateMutability": "view"},
+	{"type": "function", "name": "totalDepositedTokenIn", "inputs": [], "outputs": [{"type": "uint256"}], "stateMutability": "view"},
+	{"type": "function", "name": "totalJoinedAddress", "inputs": [], "outputs": [{"type": "uint256"}], "stateMutability": "view"},
+	{"type": "function", "name": "userDeposits", "inputs": [{"name": "", "type": "address"}], "outputs": [{"type": "uint256"}], "stateMutability": "view"},
+	{"type": "function", "name": "isSuccess", "inputs": [], "outputs": [{"type": "bool"}], "stateMutability": "view"},
+	{"type": "event", "name": "DepositTokenIn", "inputs": [
+		{"name": "user", "type": "address", "indexed": true},
+		{"name": "amount", "type": "uint256", "indexed": false}], "anonymous": false}
+]`
+
+const (
+	testRewardToken = "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+	testDepositorA  = "0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"
+	testDepositorB  = "0x90F79bf6EB2c4f870365E785982E1f101E93b906"
+)
+
+// resetIAOWatch forgets the IAOs seen by watchIAOs for the test
+func resetIAOWatch(t *testing.T) {
+	t.Helper()
+	iaoWatch.mu.Lock()
+	defer iaoWatch.mu.Unlock()
+	iaoWatch.seen = nil
+	t.Cleanup(func() {
+		iaoWatch.mu.Lock()
+		defer iaoWatch.mu.Unlock()
+		iaoWatch.seen = nil
+	})
+}
+
+// TestIAO summarizes an IAO through its lifecycle and posts its start and end
+// to the webhook once each
+func TestIAO(t *testing.T) {
+	setupTest(t, &FakeExecutor{})
+	resetIAOWatch(t)
+	chain := newFakeChain(t)
+	chain.mine(0, 10, "a")
+
+	originalStore, originalNetworks := store, networks
+	t.Cleanup(func() { store, networks = originalStore, originalNetworks })
+	var err error
+	store, err = OpenStore(filepath.Join(t.TempDir(), "store.json"))
+	require.NoError(t, err)
+	networks = map[string]*Network{DefaultNetwork: {Name: DefaultNetwork, RPCURL: chain.URL, ChainID: DBC_MAINNET_CHAIN_ID}}
+
+	require.NoError(t, store.AddDeployment(&Deployment{
+		ID:           "iao",
+		ContractType: IAOContractType,
+		Network:      DefaultNetwork,
+		ProxyAddress: testContract,
+		ABI:          "out/XAAIAO.sol/XAAIAO.json",
+		ProjectName:  "demo",
+		Status:       DeploymentSuccess,
+	}))
+	buildDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(buildDir, "out", "XAAIAO.sol"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(buildDir, "out", "XAAIAO.sol", "XAAIAO.json"), []byte(`{"abi": `+testIAOABI+`}`), 0644))
+	originalBuild := builds.current
+	builds.current = &BuildInfo{Key: "test", Status: BuildReady, dir: buildDir}
+	t.Cleanup(func() { builds.current = originalBuild })
+
+	var abi ABI
+	require.NoError(t, json.Unmarshal([]byte(testIAOABI), &abi))
+	answer := func(name string, output []byte, args ...interface{}) {
+		function, err := abi.Function(name)
+		require.NoError(t, err)
+		if args == nil {
+			args = []interface{}{}
+		}
+		chain.answer(t, testContract, function, args, output)
+	}
+	uint256 := func(n int64) []byte { return word(big.NewInt(n)) }
+	address := func(a string) []byte {
+		b, err := hex.DecodeString(a[2:])
+		require.NoError(t, err)
+		return leftPad(b)
+	}
+	answer("startTime", uint256(1000))
+	answer("endTime", uint256(2000))
+	answer("tokenIn", address(XAAIAO_TOKEN_IN_CONTRACT))
+	answer("rewardToken", address(testRewardToken))
+	answer("totalReward", uint256(200))
+	answer("totalDepositedTokenIn", uint256(12))
+	answer("totalJoinedAddress", uint256(2))
+	answer("userDeposits", uint256(5), testDepositorA)
+	answer("userDeposits", uint256(7), testDepositorB)
+	chain.answer(t, testRewardToken, erc20BalanceOf, []interface{}{testContract}, uint256(100))
+
+	// XAAIAO emits DepositTokenIn twice per deposit
+	var events []IndexedEvent
+	for i, deposit := range []struct {
+		user   string
+		amount string
+	}{{testDepositorA, "5"}, {testDepositorB, "3"}, {testDepositorB, "4"}} {
+		for j := 0; j < 2; j++ {
+			events = append(events, IndexedEvent{
+				Network:     DefaultNetwork,
+				Contract:    testContract,
+				Event:       "DepositTokenIn",
+				Args:        []ABIValue{{Name: "user", Type: "address", Value: deposit.user}, {Name: "amount", Type: "uint256", Value: deposit.amount}},
+				BlockNumber: uint64(i + 1),
+				TxHash:      "0xtx" + deposit.amount,
+				LogIndex:    uint64(j),
+			})
+		}
+	}
+	require.NoError(t, store.SaveIndexed(IndexerCheckpoint{Network: DefaultNetwork, Contracts: map[string]uint64{"0x5fc8d32690cc91d4c39d9d3abcbd16989f875707": 9}}, events, nil))
+
+	var webhook struct {
+		sync.Mutex
+		notifications []Notification
+		signatures    [][2]string
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var n Notification
+		require.NoError(t, json.Unmarshal(body, &n))
+		mac := hmac.New(sha256.New, []byte("key"))
+		mac.Write(body)
+		webhook.Lock()
+		defer webhook.Unlock()
+		webhook.notifications = append(webhook.notifications, n)
+		webhook.signatures = append(webhook.signatures, [2]string{"sha256=" + hex.EncodeToString(mac.Sum(nil)), r.Header.Get("X-Signature-256")})
+	}))
+	t.Cleanup(server.Close)
+	activeConfig.Webhooks.URL = server.URL
+	activeConfig.Webhooks.SigningKey = "key"
+	sent := func() []string {
+		webhook.Lock()
+		defer webhook.Unlock()
+		var events []string
+		for _, n := range webhook.notifications {
+			events = append(events, n.Event)
+		}
+		return events
+	}
+
+	ctx := context.Background()
+	network := networks[DefaultNetwork]
+
+	chain.setTime(500)
+	summary, err := GetIAO(ctx, testContract, 1)
+	require.NoError(t, err)
+	assert.Equal(t, IAOPending, summary.Status)
+	assert.Empty(t, summary.Result)
+	watchIAOs(ctx, network)
+	assert.Empty(t, sent())
+
+	chain.setTime(1500)
+	summary, err = GetIAO(ctx, testContract, 1)
+	require.NoError(t, err)
+	assert.Equal(t, IAOActive, summary.Status)
+	assert.Equal(t, uint64(10), summary.Block)
+	assert.Equal(t, int64(1000), summary.StartTime.Unix())
+	assert.Equal(t, int64(2000), summary.EndTime.Unix())
+	assert.Equal(t, uint64(500), summary.RemainingSeconds)
+	assert.Equal(t, "12", summary.TotalDeposited)
+	assert.Equal(t, uint64(2), summary.Depositors)
+	assert.Equal(t, XAAIAO_TOKEN_IN_CONTRACT, summary.TokenIn)
+	assert.Equal(t, testRewardToken, summary.RewardToken)
+	assert.Equal(t, "100", summary.RewardBalance)
+	assert.Equal(t, RewardUnderfunded, summary.RewardStatus)
+	assert.Equal(t, []IAODepositor{{Address: testDepositorB, Amount: "7", Deposits: 2}}, summary.TopDepositors)
+	require.NotNil(t, summary.IndexedBlock)
+	assert.Equal(t, uint64(9), *summary.IndexedBlock)
+
+	watchIAOs(ctx, network)
+	watchIAOs(ctx, network)
+	assert.Equal(t, []string{EventIAOStarted}, sent())
+
+	chain.setTime(2500)
+	answer("isSuccess", uint256(1))
+	summaries := ListIAOs(ctx, DefaultTopDepositors)
+	require.Len(t, summaries, 1)
+	assert.Empty(t, summaries[0].Error)
+	assert.Equal(t, IAOEnded, summaries[0].Status)
+	assert.Equal(t, IAOSuccess, summaries[0].Result)
+	assert.Equal(t, []IAODepositor{
+		{Address: testDepositorB, Amount: "7", Deposits: 2},
+		{Address: testDepositorA, Amount: "5", Deposits: 1},
+	}, summaries[0].TopDepositors)
+
+	watchIAOs(ctx, network)
+	assert.Equal(t, []string{EventIAOStarted, EventIAOEnded}, sent())
+	webhook.Lock()
+	for _, signature := range webhook.signatures {
+		assert.Equal(t, signature[0], signature[1])
+	}
+	webhook.Unlock()
+
+	// An IAO already ended when first seen, as when the webhook is first set
+	// or the store is new, has its events seeded instead of sent
+	deployment, ok := store.Deployment("iao")
+	require.True(t, ok)
+	store, err = OpenStore(filepath.Join(t.TempDir(), "store.json"))
+	require.NoError(t, err)
+	require.NoError(t, store.AddDeployment(deployment))
+	resetIAOWatch(t)
+	watchIAOs(ctx, network)
+	watchIAOs(ctx, network)
+	assert.Equal(t, []string{EventIAOStarted, EventIAOEnded}, sent())
+	assert.True(t, store.Notified(EventIAOStarted, DefaultNetwork, testContract))
+	assert.True(t, store.Notified(EventIAOEnded, DefaultNetwork, testContract))
+
+	_, err = GetIAO(ctx, "0x0000000000000000000000000000000000000001", 1)
+	assert.ErrorIs(t, err, ErrIAONotFound)
+}
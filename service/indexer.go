@@ -0,0 +1,377 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// IndexedEvent is an event of a deployed contract recorded by the indexer
+type IndexedEvent struct {
+	Network  string `json:"network"`
+	Contract string `json:"contract"`
+	// Event is the event name, Signature its signature
+	Event       string     `json:"event"`
+	Signature   string     `json:"signature"`
+	Args        []ABIValue `json:"args"`
+	BlockNumber uint64     `json:"block_number"`
+	BlockHash   string     `json:"block_hash"`
+	TxHash      string     `json:"tx_hash"`
+	LogIndex    uint64     `json:"log_index"`
+}
+
+// Arg returns the value of the argument name
+func (e *IndexedEvent) Arg(name string) (interface{}, bool) {
+	for _, arg := range e.Args {
+		if arg.Name == name {
+			return arg.Value, true
+		}
+	}
+	return nil, false
+}
+
+// IndexerCheckpoint records how far the events of a network are indexed
+type IndexerCheckpoint struct {
+	Network string `json:"network"`
+	// Contracts maps each indexed contract, lower case, to the last block
+	// indexed for it
+	Contracts map[string]uint64 `json:"contracts"`
+	// Heads are the last indexed heads, oldest first, checked against the
+	// chain to detect reorgs
+	Heads []BlockRef `json:"heads"`
+}
+
+// BlockRef identifies a block
+type BlockRef struct {
+	Number uint64 `json:"number"`
+	Hash   string `json:"hash"`
+}
+
+func (c IndexerCheckpoint) clone() IndexerCheckpoint {
+	contracts := make(map[string]uint64, len(c.Contracts))
+	for contract, block := range c.Contracts {
+		contracts[contract] = block
+	}
+	c.Contracts = contracts
+	c.Heads = append([]BlockRef(nil), c.Heads...)
+	return c
+}
+
+var indexer struct {
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartIndexer indexes the events of the deployed contracts in the
+// background until StopIndexer, posting the lifecycle webhooks after each
+// poll
+func StartIndexer() {
+	indexer.mu.Lock()
+	defer indexer.mu.Unlock()
+	if indexer.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	indexer.cancel, indexer.done = cancel, make(chan struct{})
+	go runIndexer(ctx, indexer.done)
+}
+
+// StopIndexer stops the indexer and waits for the running poll to finish
+func StopIndexer() {
+	indexer.mu.Lock()
+	defer indexer.mu.Unlock()
+	if indexer.cancel == nil {
+		return
+	}
+	indexer.cancel()
+	<-indexer.done
+	indexer.cancel, indexer.done = nil, nil
+}
+
+func runIndexer(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	ctx = WithLogger(ctx, slog.With("component", "indexer"))
+	for {
+		for _, network := range Networks() {
+			if err := indexNetwork(ctx, network); err != nil && ctx.Err() == nil {
+				Logger(ctx).Warn("failed to index events", "network", network.Name, "error", err)
+			}
+			watchIAOs(ctx, network)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Duration(CurrentConfig().Indexer.PollInterval)):
+		}
+	}
+}
+
+// indexNetwork indexes the events of the contracts deployed on network up to
+// its latest block, first rolling back the events of blocks a reorg replaced
+func indexNetwork(ctx context.Context, network *Network) error {
+	if store == nil {
+		return nil
+	}
+	deployments := store.Deployments(func(d *Deployment) bool {
+		return d.Status == DeploymentSuccess && d.ProxyAddress != "" && strings.EqualFold(d.Network, network.Name)
+	})
+	if len(deployments) == 0 {
+		return nil
+	}
+	cfg := CurrentConfig().Indexer
+
+	checkpoint, ok := store.Checkpoint(network.Name)
+	if !ok {
+		checkpoint = IndexerCheckpoint{Network: network.Name, Contracts: map[string]uint64{}}
+	}
+	if fork, reorged, err := findFork(network, checkpoint.Heads); err != nil {
+		return err
+	} else if reorged {
+		Logger(ctx).Warn("reorg detected, rolling back events", "network", network.Name, "fork_block", fork)
+		rollback(&checkpoint, fork)
+		if err := store.SaveIndexed(checkpoint, nil, &fork); err != nil {
+			return err
+		}
+	}
+
+	head, err := blockNumber(network.RPCURL)
+	if err != nil {
+		return err
+	}
+	// The hash is read before the logs, so a reorg in between is caught by
+	// the next poll
+	hash, err := blockHash(network.RPCURL, head)
+	if err != nil {
+		return err
+	}
+
+	indexed := map[string]bool{}
+	for _, deployment := range deployments {
+		contract := strings.ToLower(deployment.ProxyAddress)
+		if indexed[contract] {
+			continue
+		}
+		indexed[contract] = true
+		if err := indexContract(ctx, network, &checkpoint, deployment, head, cfg.BatchBlocks); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			Logger(ctx).Warn("failed to index contract events", "network", network.Name, "contract", deployment.ProxyAddress, "error", err)
+		}
+	}
+
+	if n := len(checkpoint.Heads); n == 0 || checkpoint.Heads[n-1].Number != head {
+		checkpoint.Heads = append(checkpoint.Heads, BlockRef{Number: head, Hash: hash})
+	}
+	if extra := len(checkpoint.Heads) - cfg.ReorgDepth; extra > 0 {
+		checkpoint.Heads = checkpoint.Heads[extra:]
+	}
+	return store.SaveIndexed(checkpoint, nil, nil)
+}
+
+// indexContract indexes the events of the deployment from the block after its
+// checkpoint, or its deployment block, up to head
+func indexContract(ctx context.Context, network *Network, checkpoint *IndexerCheckpoint, deployment *Deployment, head uint64, batch int) error {
+	contract := strings.ToLower(deployment.ProxyAddress)
+	from := deployment.BlockNumber
+	if last, ok := checkpoint.Contracts[contract]; ok {
+		from = last + 1
+	} else if from == 0 {
+		Logger(ctx).Warn("deployment block unknown, indexing from the latest block", "contract", deployment.ProxyAddress)
+		from = head
+	}
+	if from > head {
+		return nil
+	}
+
+	artifact, _, err := resolveArtifact(ContractCall{Address: deployment.ProxyAddress, Network: deployment.Network, ContractType: deployment.ContractType})
+	if err != nil {
+		return err
+	}
+	abi, err := LoadABI(artifact)
+	if err != nil {
+		return err
+	}
+
+	for start := from; start <= head; start += uint64(batch) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		end := min(start+uint64(batch)-1, head)
+		logs, err := contractLogs(network.RPCURL, deployment.ProxyAddress, start, end)
+		if err != nil {
+			return err
+		}
+		events := make([]IndexedEvent, 0, len(logs))
+		for _, log := range logs {
+			event, err := indexedEvent(abi, network, deployment, log)
+			if err != nil {
+				Logger(ctx).Warn("failed to decode event", "contract", deployment.ProxyAddress, "tx_hash", log.TransactionHash, "error", err)
+				continue
+			}
+			if event != nil {
+				events = append(events, *event)
+			}
+		}
+		checkpoint.Contracts[contract] = end
+		if err := store.SaveIndexed(*checkpoint, events, nil); err != nil {
+			return err
+		}
+		if len(events) > 0 {
+			Logger(ctx).Info("indexed events", "network", network.Name, "contract", deployment.ProxyAddress, "from_block", start, "to_block", end, "events", len(events))
+		}
+	}
+	return nil
+}
+
+// indexedEvent decodes log with abi, nil when abi does not declare its event
+func indexedEvent(abi ABI, network *Network, deployment *Deployment, log rpcLog) (*IndexedEvent, error) {
+	entry := abi.Event(firstTopic(log.Topics))
+	if entry == nil {
+		return nil, nil
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(log.Data, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid log data: %v", err)
+	}
+	args, err := entry.DecodeLog(log.Topics, data)
+	if err != nil {
+		return nil, err
+	}
+	block, err := parseHexInt(log.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log block number %q: %v", log.BlockNumber, err)
+	}
+	index, err := parseHexInt(log.LogIndex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log index %q: %v", log.LogIndex, err)
+	}
+	return &IndexedEvent{
+		Network:     network.Name,
+		Contract:    deployment.ProxyAddress,
+		Event:       entry.Name,
+		Signature:   entry.Signature(),
+		Args:        args,
+		BlockNumber: uint64(block),
+		BlockHash:   log.BlockHash,
+		TxHash:      log.TransactionHash,
+		LogIndex:    uint64(index),
+	}, nil
+}
+
+// findFork checks the indexed heads against the chain. When the latest one
+// was replaced it returns the block of the newest one still on the chain,
+// or 0 when none is, as the block the events are kept up to.
+func findFork(network *Network, heads []BlockRef) (uint64, bool, error) {
+	for i := len(heads) - 1; i >= 0; i-- {
+		hash, err := blockHash(network.RPCURL, heads[i].Number)
+		if err != nil && !strings.Contains(err.Error(), "not found") {
+			return 0, false, err
+		}
+		if strings.EqualFold(hash, heads[i].Hash) {
+			return heads[i].Number, i < len(heads)-1, nil
+		}
+	}
+	return 0, len(heads) > 0, nil
+}
+
+// rollback rewinds checkpoint to block
+func rollback(checkpoint *IndexerCheckpoint, block uint64) {
+	for contract, last := range checkpoint.Contracts {
+		if block == 0 {
+			delete(checkpoint.Contracts, contract)
+		} else if last > block {
+			checkpoint.Contracts[contract] = block
+		}
+	}
+	kept := checkpoint.Heads[:0]
+	for _, head := range checkpoint.Heads {
+		if head.Number <= block {
+			kept = append(kept, head)
+		}
+	}
+	checkpoint.Heads = kept
+}
+
+// EventFilter selects indexed events in ListEvents. Empty fields match
+// everything.
+type EventFilter struct {
+	Contract string
+	Network  string
+	// Event is an event name or signature
+	Event     string
+	FromBlock uint64
+	// ToBlock is inclusive, 0 for the latest
+	ToBlock  uint64
+	Page     int
+	PageSize int
+}
+
+// Normalize applies the paging defaults
+func (f *EventFilter) Normalize() {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.PageSize < 1 {
+		f.PageSize = DefaultPageSize
+	}
+	if f.PageSize > MaxPageSize {
+		f.PageSize = MaxPageSize
+	}
+}
+
+func (f *EventFilter) match(e *IndexedEvent) bool {
+	return matchField(f.Contract, e.Contract) &&
+		matchField(f.Network, e.Network) &&
+		(f.Event == "" || f.Event == e.Event || f.Event == e.Signature) &&
+		e.BlockNumber >= f.FromBlock &&
+		(f.ToBlock == 0 || e.BlockNumber <= f.ToBlock)
+}
+
+// ListEvents returns one page of the indexed events matching filter, in
+// chain order, and the total number of matches
+func ListEvents(filter EventFilter) ([]IndexedEvent, int) {
+	filter.Normalize()
+	if store == nil {
+		return []IndexedEvent{}, 0
+	}
+	events := store.Events(filter.match)
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].BlockNumber != events[j].BlockNumber {
+			return events[i].BlockNumber < events[j].BlockNumber
+		}
+		return events[i].LogIndex < events[j].LogIndex
+	})
+
+	total := len(events)
+	start := min((filter.Page-1)*filter.PageSize, total)
+	end := min(start+filter.PageSize, total)
+	return append([]IndexedEvent{}, events[start:end]...), total
+}
+
+// IndexedBlock returns the last block indexed for contract on network,
+// defaulting to the network of its recorded deployment
+func IndexedBlock(network, contract string) (uint64, bool) {
+	if store == nil {
+		return 0, false
+	}
+	if network == "" {
+		deployment := findDeployment(contract, "", "")
+		if deployment == nil {
+			return 0, false
+		}
+		network = deployment.Network
+	}
+	checkpoint, ok := store.Checkpoint(strings.ToLower(network))
+	if !ok {
+		return 0, false
+	}
+	block, ok := checkpoint.Contracts[strings.ToLower(contract)]
+	return block, ok
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeChain answers the calls of the indexer from its blocks and logs, and
+// eth_call from its calls
+type fakeChain struct {
+	*httptest.Server
+	mu     sync.Mutex
+	head   uint64
+	time   uint64
+	hashes map[uint64]string
+	logs   []rpcLog
+	// calls maps the lower case contract and call data to the result
+	calls map[string]string
+	// from records the fromBlock of each eth_getLogs
+	from []uint64
+}
+
+func newFakeChain(t *testing.T) *fakeChain {
+	chain := &fakeChain{hashes: map[uint64]string{}, calls: map[string]string{}}
+	chain.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		chain.mu.Lock()
+		defer chain.mu.Unlock()
+		var result interface{}
+		switch req.Method {
+		case "eth_blockNumber":
+			result = hexBlock(chain.head)
+		case "eth_getBlockByNumber":
+			number := int64(chain.head)
+			if tag := req.Params[0].(string); tag != "latest" {
+				var err error
+				number, err = parseHexInt(tag)
+				require.NoError(t, err)
+			}
+			if hash, ok := chain.hashes[uint64(number)]; ok && uint64(number) <= chain.head {
+				result = map[string]string{"number": hexBlock(uint64(number)), "hash": hash, "timestamp": hexBlock(chain.time)}
+			}
+		case "eth_call":
+			call := req.Params[0].(map[string]interface{})
+			output, ok := chain.calls[strings.ToLower(call["to"].(string))+call["data"].(string)]
+			if !ok {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": 3, "message": "execution reverted"}})
+				return
+			}
+			result = output
+		case "eth_getLogs":
+			filter := req.Params[0].(map[string]interface{})
+			from, err := parseHexInt(filter["fromBlock"].(string))
+			require.NoError(t, err)
+			to, err := parseHexInt(filter["toBlock"].(string))
+			require.NoError(t, err)
+			chain.from = append(chain.from, uint64(from))
+			logs := []rpcLog{}
+			for _, log := range chain.logs {
+				block, _ := parseHexInt(log.BlockNumber)
+				if block >= from && block <= to && strings.EqualFold(log.Address, filter["address"].(string)) {
+					log.BlockHash = chain.hashes[uint64(block)]
+					logs = append(logs, log)
+				}
+			}
+			result = logs
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(chain.Close)
+	return chain
+}
+
+// mine sets the head to block, giving the blocks from first on the hashes of
+// fork
+func (c *fakeChain) mine(first, head uint64, fork string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for n := first; n <= head; n++ {
+		c.hashes[n] = fmt.Sprintf("0x%s%d", fork, n)
+	}
+	c.head = head
+}
+
+// answer sets the result of function of contract called with args
+func (c *fakeChain) answer(t *testing.T, contract string, function *ABIEntry, args []interface{}, output []byte) {
+	data, err := function.EncodeCall(args)
+	require.NoError(t, err)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls[strings.ToLower(contract)+"0x"+hex.EncodeToString(data)] = "0x" + hex.EncodeToString(output)
+}
+
+func (c *fakeChain) setTime(time uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.time = time
+}
+
+func (c *fakeChain) transfer(block uint64, value int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logs = append(c.logs, rpcLog{
+		Address:         testContract,
+		BlockNumber:     hexBlock(block),
+		TransactionHash: fmt.Sprintf("0xtx%d", block),
+		LogIndex:        "0x0",
+		Data:            fmt.Sprintf("0x%064x", value),
+		Topics: []string{
+			"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+			"0x000000000000000000000000f39fd6e51aad88f6f4ce6ab8827279cfffb92266",
+			"0x0000000000000000000000005aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+		},
+	})
+}
+
+func (c *fakeChain) dropLogs(block uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	kept := c.logs[:0]
+	for _, log := range c.logs {
+		if log.BlockNumber != hexBlock(block) {
+			kept = append(kept, log)
+		}
+	}
+	c.logs = kept
+}
+
+func (c *fakeChain) fromBlocks() []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	from := c.from
+	c.from = nil
+	return from
+}
+
+func eventValues(events []IndexedEvent) []string {
+	values := make([]string, len(events))
+	for i, event := range events {
+		value, _ := event.Arg("value")
+		values[i] = strconv.FormatUint(event.BlockNumber, 10) + ":" + value.(string)
+	}
+	return values
+}
+
+// TestIndexer indexes the events of a deployment in batches, resumes from the
+// checkpoint and rolls back the events of a reorg
+func TestIndexer(t *testing.T) {
+	setupTest(t, &FakeExecutor{})
+	activeConfig.Indexer.BatchBlocks = 3
+	chain := newFakeChain(t)
+
+	originalStore, originalNetworks := store, networks
+	t.Cleanup(func() { store, networks = originalStore, originalNetworks })
+	var err error
+	store, err = OpenStore(filepath.Join(t.TempDir(), "store.json"))
+	require.NoError(t, err)
+	networks = map[string]*Network{DefaultNetwork: {Name: DefaultNetwork, RPCURL: chain.URL, ChainID: DBC_MAINNET_CHAIN_ID}}
+	network := networks[DefaultNetwork]
+
+	require.NoError(t, store.AddDeployment(&Deployment{
+		ID:           "dep",
+		ContractType: "token",
+		Network:      DefaultNetwork,
+		ProxyAddress: testContract,
+		ABI:          "out/Token.sol/Token.json",
+		BlockNumber:  5,
+		Status:       DeploymentSuccess,
+	}))
+	buildDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(buildDir, "out", "Token.sol"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(buildDir, "out", "Token.sol", "Token.json"), []byte(`{"abi": `+testABI+`}`), 0644))
+	originalBuild := builds.current
+	builds.current = &BuildInfo{Key: "test", Status: BuildReady, dir: buildDir}
+	t.Cleanup(func() { builds.current = originalBuild })
+
+	ctx := context.Background()
+	list := func() []string {
+		events, total := ListEvents(EventFilter{Contract: strings.ToLower(testContract), Event: "Transfer"})
+		assert.Len(t, events, total)
+		return eventValues(events)
+	}
+
+	_, ok := IndexedBlock("", testContract)
+	assert.False(t, ok)
+
+	chain.mine(0, 10, "a")
+	chain.transfer(6, 1)
+	chain.transfer(9, 2)
+	require.NoError(t, indexNetwork(ctx, network))
+	assert.Equal(t, []uint64{5, 8}, chain.fromBlocks())
+	assert.Equal(t, []string{"6:1", "9:2"}, list())
+	block, ok := IndexedBlock("", testContract)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(10), block)
+
+	events, total := ListEvents(EventFilter{Contract: testContract, FromBlock: 7, PageSize: 1})
+	assert.Equal(t, 1, total)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Transfer(address,address,uint256)", events[0].Signature)
+	assert.Equal(t, "0xa9", events[0].BlockHash)
+	assert.Equal(t, []ABIValue{
+		{Name: "from", Type: "address", Value: testDeployer},
+		{Name: "to", Type: "address", Value: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{Name: "value", Type: "uint256", Value: "2"},
+	}, events[0].Args)
+
+	// Resumes after the checkpoint
+	chain.mine(11, 12, "a")
+	chain.transfer(11, 3)
+	require.NoError(t, indexNetwork(ctx, network))
+	assert.Equal(t, []uint64{11}, chain.fromBlocks())
+	assert.Equal(t, []string{"6:1", "9:2", "11:3"}, list())
+
+	// Blocks 11 and 12 are replaced, the events are indexed again from the
+	// last head still on the chain
+	chain.mine(11, 13, "b")
+	chain.dropLogs(11)
+	chain.transfer(12, 4)
+	require.NoError(t, indexNetwork(ctx, network))
+	assert.Equal(t, []uint64{11}, chain.fromBlocks())
+	assert.Equal(t, []string{"6:1", "9:2", "12:4"}, list())
+
+	checkpoint, ok := store.Checkpoint(DefaultNetwork)
+	require.True(t, ok)
+	assert.Equal(t, []BlockRef{{Number: 10, Hash: "0xa10"}, {Number: 13, Hash: "0xb13"}}, checkpoint.Heads)
+
+	// The events survive a restart
+	reopened, err := OpenStore(store.path)
+	require.NoError(t, err)
+	assert.Len(t, reopened.Events(func(*IndexedEvent) bool { return true }), 3)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultJobLogDir is the job log dir unless paths.job_logs is set
+const DefaultJobLogDir = "./data/job-logs"
+
+var (
+	// JobLogDir holds the log of every job, one file per job ID
+	JobLogDir = DefaultJobLogDir
+	// jobLogPoll is how often a followed log is checked for new lines
+	jobLogPoll = 500 * time.Millisecond
+)
+
+func jobLogPath(id string) string {
+	return filepath.Join(JobLogDir, id+".log")
+}
+
+// openJobLog returns a context whose logger also writes to the log file of
+// job, and a func closing the file
+func openJobLog(ctx context.Context, job *Job) (context.Context, func()) {
+	if err := os.MkdirAll(JobLogDir, 0755); err != nil {
+		Logger(ctx).Error("failed to create job log dir", "error", err)
+		return ctx, func() {}
+	}
+	file, err := os.OpenFile(jobLogPath(job.ID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		Logger(ctx).Error("failed to open job log", "error", err)
+		return ctx, func() {}
+	}
+	handler := teeHandler{Logger(ctx).Handler(), slog.NewTextHandler(file, nil)}
+	return WithLogger(ctx, slog.New(handler)), func() { file.Close() }
+}
+
+// FollowJobLog copies the log of the job with id to w. With follow set it
+// keeps copying new lines until the job finishes or ctx is done.
+func FollowJobLog(ctx context.Context, id string, w io.Writer, follow bool) error {
+	if _, err := GetJob(id); err != nil {
+		return err
+	}
+	file, err := os.Open(jobLogPath(id))
+	if errors.Is(err, os.ErrNotExist) && follow {
+		// Not started yet: wait for the worker to create the log
+		file, err = waitJobLog(ctx, id)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open job log: %v", err)
+	}
+	defer file.Close()
+
+	for {
+		if _, err := io.Copy(w, file); err != nil {
+			return err
+		}
+		if flusher, ok := w.(interface{ Flush() }); ok {
+			flusher.Flush()
+		}
+		if !follow {
+			return nil
+		}
+		job, err := GetJob(id)
+		if err != nil {
+			return err
+		}
+		if job.Status != JobQueued && job.Status != JobRunning {
+			// Pick up the lines written before the job finished
+			_, err := io.Copy(w, file)
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(jobLogPoll):
+		}
+	}
+}
+
+func waitJobLog(ctx context.Context, id string) (*os.File, error) {
+	for {
+		file, err := os.Open(jobLogPath(id))
+		if !errors.Is(err, os.ErrNotExist) {
+			return file, err
+		}
+		if job, err := GetJob(id); err != nil || (job.Status != JobQueued && job.Status != JobRunning) {
+			return os.Open(jobLogPath(id))
+		}
+		select {
+		case <-ctx.Done():
+			return nil, os.ErrNotExist
+		case <-time.After(jobLogPoll):
+		}
+	}
+}
+
+// teeHandler sends every record to both handlers
+type teeHandler [2]slog.Handler
+
+func (h teeHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h[0].Enabled(ctx, level) || h[1].Enabled(ctx, level)
+}
+
+func (h teeHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, record.Level) {
+			errs = append(errs, handler.Handle(ctx, record.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h teeHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return teeHandler{h[0].WithAttrs(attrs), h[1].WithAttrs(attrs)}
+}
+
+func (h teeHandler) WithGroup(name string) slog.Handler {
+	return teeHandler{h[0].WithGroup(name), h[1].WithGroup(name)}
+}
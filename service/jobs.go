@@ -0,0 +1,448 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	JobDeploy  = "deploy"
+	JobActions = "actions"
+	JobVerify  = "verify"
+	JobUpgrade = "upgrade"
+	// JobTransact sends a transaction of the transact API
+	JobTransact = "transact"
+
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	// JobNeedsReview marks an interrupted job whose outcome could not be
+	// established from the broadcast and the chain
+	JobNeedsReview = "needs_review"
+)
+
+var (
+	ErrShuttingDown  = errors.New("service is shutting down")
+	ErrQueueFull     = errors.New("job queue is full")
+	ErrActionsFailed = errors.New("post-deploy actions failed")
+)
+
+const (
+	// DefaultMaxQueueDepth bounds the jobs waiting behind the running one
+	// unless limits.max_queue_depth is set
+	DefaultMaxQueueDepth = 20
+	// DefaultShutdownTimeout is how long shutdown waits for the running job
+	// unless limits.shutdown_timeout is set
+	DefaultShutdownTimeout = 10 * time.Minute
+)
+
+var jobs = newJobQueue()
+
+// Job is a deployment or an action run, executed one at a time so the
+// deployer's transactions never race for nonces
+type Job struct {
+	ID           string `json:"id"`
+	Kind         string `json:"kind"`
+	Status       string `json:"status"`
+	ContractType string `json:"contract_type"`
+	Network      string `json:"network"`
+	// Contract is the target of an actions job
+	Contract string `json:"contract,omitempty"`
+	// ImplementationAddress is the new implementation of an upgrade job
+	ImplementationAddress string `json:"implementation_address,omitempty"`
+	// Params are the script env vars of a deploy job, without the private key
+	Params  map[string]string `json:"params,omitempty"`
+	Actions []Action          `json:"actions,omitempty"`
+	// Transaction is sent by a transact job
+	Transaction   *Transaction   `json:"transaction,omitempty"`
+	Caller        string         `json:"caller,omitempty"`
+	RequestID     string         `json:"request_id,omitempty"`
+	ProxyAddress  string         `json:"proxy_address,omitempty"`
+	ActionResults []ActionResult `json:"action_results,omitempty"`
+	Error         string         `json:"error,omitempty"`
+	// DeploymentID and Workspace identify the deployment a running deploy
+	// job started, or the target of a verify or upgrade job. RecoverJobs
+	// uses them after a crash.
+	DeploymentID string `json:"deployment_id,omitempty"`
+	Workspace    string `json:"workspace,omitempty"`
+	// DeployerNonce is the deployer's pending nonce before the job sent
+	// anything, compared by RecoverJobs after a crash
+	DeployerNonce *uint64   `json:"deployer_nonce,omitempty"`
+	Recovered     bool      `json:"recovered,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	StartedAt     time.Time `json:"started_at,omitempty"`
+	FinishedAt    time.Time `json:"finished_at,omitempty"`
+
+	err     error
+	done    chan struct{}
+	spanCtx trace.SpanContext
+}
+
+// Err returns the error the job finished with
+func (j *Job) Err() error {
+	return j.err
+}
+
+// signer returns the signer the job sends with, empty for the deployer
+func (j *Job) signer() string {
+	if j.Transaction != nil {
+		return j.Transaction.Signer
+	}
+	return ""
+}
+
+type jobQueue struct {
+	mu       sync.Mutex
+	queue    []*Job
+	running  *Job
+	byID     map[string]*Job
+	draining chan struct{}
+	wake     chan struct{}
+	stopped  chan struct{}
+	started  bool
+}
+
+func newJobQueue() *jobQueue {
+	return &jobQueue{
+		byID:     map[string]*Job{},
+		draining: make(chan struct{}),
+		wake:     make(chan struct{}, 1),
+		stopped:  make(chan struct{}),
+	}
+}
+
+// StartJobs resumes the jobs left queued by the previous run and starts the
+// worker
+func StartJobs() error {
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	if jobs.started {
+		return fmt.Errorf("jobs already started")
+	}
+	jobs.started = true
+
+	if store != nil {
+		for _, job := range store.Jobs() {
+			if job.Status != JobQueued {
+				continue
+			}
+			job.done = make(chan struct{})
+			jobs.queue = append(jobs.queue, job)
+			jobs.byID[job.ID] = job
+			slog.Info("resuming queued job", "job_id", job.ID, "kind", job.Kind, "contract_type", job.ContractType)
+		}
+	}
+	queueDepth.Set(float64(len(jobs.queue)))
+	go jobs.work()
+	jobs.signal()
+	return nil
+}
+
+// SubmitJob queues job for execution. The caller, request ID and trace of
+// ctx are carried over to the job.
+func SubmitJob(ctx context.Context, job *Job) (*Job, error) {
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	select {
+	case <-jobs.draining:
+		return nil, ErrShuttingDown
+	default:
+	}
+	if len(jobs.queue) >= CurrentConfig().Limits.MaxQueueDepth {
+		return nil, ErrQueueFull
+	}
+
+	job.ID = newID()
+	job.Status = JobQueued
+	job.Caller = callerFrom(ctx)
+	job.RequestID = requestIDFrom(ctx)
+	job.CreatedAt = time.Now()
+	job.done = make(chan struct{})
+	job.spanCtx = trace.SpanContextFromContext(ctx)
+	jobs.persist(job)
+
+	jobs.queue = append(jobs.queue, job)
+	jobs.byID[job.ID] = job
+	queueDepth.Set(float64(len(jobs.queue)))
+	jobs.signal()
+	Logger(ctx).Info("job queued", "job_id", job.ID, "kind", job.Kind, "position", len(jobs.queue))
+	return job, nil
+}
+
+// WaitJob waits for job to finish. It returns ErrShuttingDown when the
+// service starts draining before the job left the queue; the job then
+// resumes after the restart.
+func WaitJob(ctx context.Context, job *Job) error {
+	select {
+	case <-job.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-jobs.draining:
+	}
+
+	jobs.mu.Lock()
+	queued := job.Status == JobQueued
+	jobs.mu.Unlock()
+	if queued {
+		return ErrShuttingDown
+	}
+	select {
+	case <-job.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetJob returns a snapshot of the job with id
+func GetJob(id string) (*Job, error) {
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	if job, ok := jobs.byID[id]; ok {
+		snapshot := *job
+		return &snapshot, nil
+	}
+	if store != nil {
+		if job, ok := store.Job(id); ok {
+			return job, nil
+		}
+	}
+	return nil, fmt.Errorf("job not found: %s", id)
+}
+
+// ListJobs returns the recorded jobs with status, or all of them when status
+// is empty, newest first
+func ListJobs(status string) []*Job {
+	if store == nil {
+		return nil
+	}
+	all := store.Jobs()
+	list := make([]*Job, 0, len(all))
+	for i := len(all) - 1; i >= 0; i-- {
+		if status == "" || all[i].Status == status {
+			list = append(list, all[i])
+		}
+	}
+	return list
+}
+
+// ShutdownJobs stops accepting jobs and waits until the running job, if any,
+// finishes or ctx expires. Queued jobs stay persisted and resume on the next
+// start.
+func ShutdownJobs(ctx context.Context) error {
+	jobs.mu.Lock()
+	select {
+	case <-jobs.draining:
+	default:
+		close(jobs.draining)
+	}
+	started := jobs.started
+	queued := len(jobs.queue)
+	jobs.mu.Unlock()
+	jobs.signal()
+
+	if !started {
+		return nil
+	}
+	slog.Info("draining jobs", "queued", queued)
+	select {
+	case <-jobs.stopped:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("running job did not finish before shutdown: %v", ctx.Err())
+	}
+}
+
+// Draining reports whether the service stopped accepting jobs
+func Draining() bool {
+	select {
+	case <-jobs.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+func (q *jobQueue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (q *jobQueue) work() {
+	defer close(q.stopped)
+	for {
+		q.mu.Lock()
+		if Draining() {
+			q.mu.Unlock()
+			return
+		}
+		if len(q.queue) == 0 {
+			q.mu.Unlock()
+			<-q.wake
+			continue
+		}
+		job := q.queue[0]
+		q.queue = q.queue[1:]
+		queueDepth.Set(float64(len(q.queue)))
+		job.Status = JobRunning
+		job.StartedAt = time.Now()
+		q.running = job
+		q.persist(job)
+		q.mu.Unlock()
+
+		err := runJob(job)
+
+		q.mu.Lock()
+		job.FinishedAt = time.Now()
+		job.Status = JobSucceeded
+		job.err = err
+		if err != nil {
+			job.Status = JobFailed
+			job.Error = err.Error()
+		}
+		q.running = nil
+		q.persist(job)
+		// GetJob reads the finished job from the store
+		if store != nil {
+			delete(q.byID, job.ID)
+		}
+		close(job.done)
+		q.mu.Unlock()
+	}
+}
+
+// persist saves a snapshot of job. The caller must hold q.mu.
+func (q *jobQueue) persist(job *Job) {
+	if store == nil {
+		return
+	}
+	if err := store.SaveJob(*job); err != nil {
+		slog.Error("failed to persist job", "job_id", job.ID, "error", err)
+	}
+}
+
+type jobKey struct{}
+
+// recordJobWorkspace notes on the job running in ctx, if any, the deployment
+// it started and its workspace
+func recordJobWorkspace(ctx context.Context, deploymentID, dir string) {
+	job, ok := ctx.Value(jobKey{}).(*Job)
+	if !ok {
+		return
+	}
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	job.DeploymentID = deploymentID
+	job.Workspace = dir
+	jobs.persist(job)
+}
+
+// runJob executes job with the caller, request ID and trace of the request
+// that submitted it
+func runJob(job *Job) (err error) {
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), job.spanCtx)
+	ctx = WithCaller(ctx, job.Caller)
+	if job.RequestID != "" {
+		ctx = WithRequestID(ctx, job.RequestID)
+	}
+	ctx, closeLog := openJobLog(ctx, job)
+	defer closeLog()
+	ctx = WithLogger(ctx, Logger(ctx).With("job_id", job.ID))
+	ctx = context.WithValue(ctx, jobKey{}, job)
+
+	logger := Logger(ctx)
+	logger.Info("job started", "kind", job.Kind, "contract_type", job.ContractType, "network", job.Network)
+	defer func() {
+		if err != nil {
+			logger.Error("job failed", "error", err)
+			return
+		}
+		logger.Info("job succeeded")
+	}()
+
+	spec, err := LookupContractType(job.ContractType)
+	if err != nil {
+		return err
+	}
+	network, err := LookupNetwork(job.Network)
+	if err != nil {
+		return err
+	}
+
+	if nonce, err := deployerNonce(ctx, network, job.signer()); err != nil {
+		Logger(ctx).Warn("failed to read deployer nonce", "error", err)
+	} else {
+		jobs.mu.Lock()
+		job.DeployerNonce = &nonce
+		jobs.persist(job)
+		jobs.mu.Unlock()
+	}
+
+	switch job.Kind {
+	case JobVerify, JobUpgrade:
+		deployment, err := GetDeployment(job.DeploymentID)
+		if err != nil {
+			return err
+		}
+		// The upgrade transaction is recorded with the proxy's actions
+		implementation := deployment.ImplementationAddress
+		if job.Kind == JobVerify {
+			err = VerifyDeployment(ctx, deployment, network)
+		} else {
+			implementation, _, err = UpgradeDeployment(ctx, deployment, network)
+		}
+		jobs.mu.Lock()
+		job.ProxyAddress = deployment.ProxyAddress
+		job.ImplementationAddress = implementation
+		jobs.mu.Unlock()
+		return err
+	case JobTransact:
+		result, err := runTransaction(ctx, job, network)
+		jobs.mu.Lock()
+		job.ActionResults = []ActionResult{result}
+		jobs.mu.Unlock()
+		return err
+	}
+
+	contract := job.Contract
+	if job.Kind == JobDeploy {
+		// Checked again for jobs queued before a config change
+		if err := ValidateActionOwner(ctx, spec, network, job.Params, job.Actions); err != nil {
+			return err
+		}
+		params := make(map[string]string, len(job.Params))
+		for key, value := range job.Params {
+			params[key] = value
+		}
+		proxyAddress, err := DeployContract(ctx, CurrentConfig().Paths.Contracts, params, spec, network)
+		jobs.mu.Lock()
+		job.ProxyAddress = proxyAddress
+		jobs.mu.Unlock()
+		if err != nil {
+			return err
+		}
+		contract = proxyAddress
+	}
+	if len(job.Actions) == 0 {
+		return nil
+	}
+
+	results, err := RunActions(ctx, contract, spec, network, job.Actions)
+	jobs.mu.Lock()
+	job.ActionResults = results
+	jobs.mu.Unlock()
+	if err != nil && job.Kind == JobDeploy {
+		return fmt.Errorf("%w: %w", ErrActionsFailed, err)
+	}
+	return err
+}
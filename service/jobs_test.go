@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDeployer = "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+
+// fakeNode is an RPC node answering eth_getTransactionCount with nonce,
+// eth_getTransactionReceipt from receipts, keyed by hash, and eth_call with
+// callError when set, or callResult
+type fakeNode struct {
+	*httptest.Server
+
+	mu         sync.Mutex
+	nonce      string
+	receipts   map[string]*txReceipt
+	callError  string
+	callResult string
+}
+
+func newFakeNode(t *testing.T) *fakeNode {
+	node := &fakeNode{nonce: "0x7", receipts: map[string]*txReceipt{}}
+	node.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		var result interface{}
+		switch req.Method {
+		case "eth_call":
+			if node.callError != "" {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "error": map[string]interface{}{"code": 3, "message": node.callError}})
+				return
+			}
+			if node.callResult != "" {
+				result = node.callResult
+			}
+		case "eth_getTransactionCount":
+			result = node.nonce
+		case "eth_getTransactionReceipt":
+			if receipt, ok := node.receipts[req.Params[0].(string)]; ok {
+				result = receipt
+			}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	t.Cleanup(node.Close)
+	return node
+}
+
+// setupJobs gives the test its own job queue and store, with deployments
+// run from the repo contracts against node
+func setupJobs(t *testing.T, node *fakeNode) {
+	t.Helper()
+
+	originalStore, originalJobs, originalNetworks, originalLogDir, originalConfig := store, jobs, networks, JobLogDir, activeConfig
+	var err error
+	store, err = OpenStore(filepath.Join(t.TempDir(), "store.json"))
+	require.NoError(t, err)
+	jobs = newJobQueue()
+	cfg := *activeConfig
+	cfg.Paths.Contracts = contractsPath
+	activeConfig = &cfg
+	JobLogDir = t.TempDir()
+	networks = map[string]*Network{DefaultNetwork: {
+		Name:    DefaultNetwork,
+		RPCURL:  node.URL,
+		ChainID: DBC_MAINNET_CHAIN_ID,
+	}}
+
+	t.Cleanup(func() {
+		_ = ShutdownJobs(context.Background())
+		store, jobs, networks, JobLogDir, activeConfig = originalStore, originalJobs, originalNetworks, originalLogDir, originalConfig
+		deployerAddresses.Range(func(key, _ interface{}) bool {
+			deployerAddresses.Delete(key)
+			return true
+		})
+	})
+}
+
+func TestJobs_ShutdownKeepsQueued(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	fake := &FakeExecutor{
+		Responses: []FakeResponse{
+			{Name: "forge", Args: []string{"script"}, Output: readFixture(t, "forge/success.txt")},
+			{Name: "cast", Args: []string{"wallet", "address"}, Output: []byte(testDeployer + "\n")},
+		},
+		OnRun: func(cmd Command) {
+			if cmd.Name == "forge" && len(cmd.Args) > 0 && cmd.Args[0] == "script" {
+				select {
+				case started <- struct{}{}:
+				default:
+				}
+				<-release
+			}
+		},
+	}
+	setupTest(t, fake)
+	setupJobs(t, newFakeNode(t))
+	require.NoError(t, StartJobs())
+
+	ctx := WithCaller(context.Background(), "admin")
+	submit := func() *Job {
+		job, err := SubmitJob(ctx, &Job{Kind: JobDeploy, ContractType: "token", Network: DefaultNetwork, Params: copyEnv(tokenEnvVars)})
+		require.NoError(t, err)
+		return job
+	}
+	running := submit()
+	<-started
+	queued := submit()
+
+	shutdown := make(chan error, 1)
+	go func() { shutdown <- ShutdownJobs(context.Background()) }()
+	require.Eventually(t, Draining, time.Second, 10*time.Millisecond)
+
+	_, err := SubmitJob(ctx, &Job{Kind: JobDeploy, ContractType: "token", Network: DefaultNetwork})
+	assert.ErrorIs(t, err, ErrShuttingDown)
+	assert.ErrorIs(t, WaitJob(context.Background(), queued), ErrShuttingDown)
+
+	// The running deployment finishes before shutdown returns
+	close(release)
+	require.NoError(t, <-shutdown)
+	require.NoError(t, WaitJob(context.Background(), running))
+	assert.NoError(t, running.Err())
+	jobs.mu.Lock()
+	assert.NotContains(t, jobs.byID, running.ID)
+	jobs.mu.Unlock()
+
+	stored, ok := store.Job(running.ID)
+	require.True(t, ok)
+	assert.Equal(t, JobSucceeded, stored.Status)
+	assert.Equal(t, "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707", stored.ProxyAddress)
+	assert.Equal(t, "admin", stored.Caller)
+	require.NotNil(t, stored.DeployerNonce)
+	assert.Equal(t, uint64(7), *stored.DeployerNonce)
+	assert.NotEmpty(t, stored.DeploymentID)
+	stored, ok = store.Job(queued.ID)
+	require.True(t, ok)
+	assert.Equal(t, JobQueued, stored.Status)
+
+	// A restart resumes the queued job
+	jobs = newJobQueue()
+	require.NoError(t, StartJobs())
+	require.Eventually(t, func() bool {
+		job, err := GetJob(queued.ID)
+		return err == nil && job.Status == JobSucceeded
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+// TestJobs_Upgrade runs an upgrade job through the upgrade script and
+// follows its log, and rejects the upgrade of a proxy the signer does not own
+func TestJobs_Upgrade(t *testing.T) {
+	const (
+		proxy          = "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707"
+		implementation = "0xa513E6E4b8f2a923D98304ec87F64353C4D5C853"
+	)
+	fake := &FakeExecutor{
+		Responses: []FakeResponse{
+			{Name: "forge", Args: []string{"script", "script/token/Upgrade.s.sol:Upgrade"}, Output: []byte("Proxy Address: " + proxy + "\n")},
+			{Name: "cast", Args: []string{"wallet", "address"}, Output: []byte(testDeployer + "\n")},
+		},
+		OnRun: func(cmd Command) {
+			if cmd.Args[0] != "script" {
+				return
+			}
+			dir := filepath.Join(cmd.Dir, "broadcast", "Upgrade.s.sol", "19880818")
+			require.NoError(t, os.MkdirAll(dir, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "run-latest.json"), []byte(`{
+				"transactions": [
+					{"hash": "0xcreate", "transactionType": "CREATE", "contractName": "Token", "contractAddress": "`+implementation+`"},
+					{"hash": "0xupgrade", "transactionType": "CALL", "contractAddress": "`+proxy+`"}
+				],
+				"receipts": [
+					{"transactionHash": "0xcreate", "status": "0x1"},
+					{"transactionHash": "0xupgrade", "status": "0x1"}
+				]
+			}`), 0644))
+		},
+	}
+	setupTest(t, fake)
+	node := newFakeNode(t)
+	setupJobs(t, node)
+	require.NoError(t, store.AddDeployment(&Deployment{
+		ID:                    "dep",
+		ContractType:          "token",
+		Network:               DefaultNetwork,
+		ProxyAddress:          proxy,
+		ImplementationAddress: "0xDc64a140Aa3E981100a9becA4E685f962f0cF6C9",
+		ContractName:          "Token",
+		Status:                DeploymentSuccess,
+	}))
+	require.NoError(t, StartJobs())
+	owner := func(address string) {
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		node.callResult = "0x000000000000000000000000" + strings.TrimPrefix(address, "0x")
+	}
+
+	owner("0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D")
+	job, err := SubmitJob(context.Background(), &Job{Kind: JobUpgrade, ContractType: "token", Network: DefaultNetwork, DeploymentID: "dep"})
+	require.NoError(t, err)
+	require.NoError(t, WaitJob(context.Background(), job))
+	require.Error(t, job.Err())
+	assert.Contains(t, job.Err().Error(), "is owned by 0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D")
+	for _, call := range fake.Calls() {
+		assert.NotEqual(t, "script", call.Args[0])
+	}
+
+	owner(testDeployer)
+	job, err = SubmitJob(context.Background(), &Job{Kind: JobUpgrade, ContractType: "token", Network: DefaultNetwork, DeploymentID: "dep"})
+	require.NoError(t, err)
+	var log strings.Builder
+	require.NoError(t, FollowJobLog(context.Background(), job.ID, &log, true))
+	require.NoError(t, WaitJob(context.Background(), job))
+	require.NoError(t, job.Err())
+
+	calls := fake.Calls()
+	script := calls[len(calls)-1]
+	assert.Equal(t, "script/token/Upgrade.s.sol:Upgrade", script.Args[1])
+	assert.NotContains(t, script.Args, "--private-key")
+	assert.Contains(t, script.Env, "PRIVATE_KEY=0xkey")
+	assert.Contains(t, script.Env, "PROXY_CONTRACT="+proxy)
+
+	assert.Contains(t, log.String(), "Proxy Address: "+proxy)
+	assert.Contains(t, log.String(), "proxy upgraded")
+	assert.Contains(t, log.String(), "job succeeded")
+	assert.NotContains(t, log.String(), "0xkey")
+	assert.Equal(t, implementation, job.ImplementationAddress)
+	deployment, err := GetDeployment("dep")
+	require.NoError(t, err)
+	assert.Equal(t, implementation, deployment.ImplementationAddress)
+	actions := DeploymentActions(deployment)
+	require.Len(t, actions, 1)
+	assert.Equal(t, upgradeSignature, actions[0].Function)
+	assert.Equal(t, "0xupgrade", actions[0].TxHash)
+	assert.Equal(t, ActionSuccess, actions[0].Status)
+}
+
+// TestJobs_DeployActions runs the post-deploy actions after the deployment,
+// and rejects them before deploying when the owner is not the deployer
+func TestJobs_DeployActions(t *testing.T) {
+	fake := &FakeExecutor{Responses: []FakeResponse{
+		{Name: "forge", Args: []string{"script"}, Output: readFixture(t, "forge/success.txt")},
+		{Name: "cast", Args: []string{"wallet", "address"}, Output: []byte(testDeployer + "\n")},
+		{Name: "cast", Args: []string{"send"}, Output: readFixture(t, "cast/send_success.json")},
+	}}
+	setupTest(t, fake)
+	setupJobs(t, newFakeNode(t))
+	require.NoError(t, StartJobs())
+
+	run := func(owner string) *Job {
+		params := copyEnv(tokenEnvVars)
+		params["TOKEN_OWNER"] = owner
+		job, err := SubmitJob(context.Background(), &Job{
+			Kind:         JobDeploy,
+			ContractType: "token",
+			Network:      DefaultNetwork,
+			Params:       params,
+			Actions:      []Action{{Function: "setStakingContract", Args: []string{"0x07D325030dA1A8c1f96C414BFFbe4fBD539CED45"}}},
+		})
+		require.NoError(t, err)
+		require.NoError(t, WaitJob(context.Background(), job))
+		return job
+	}
+
+	job := run("0xAE5015960Ff1E3ad095a7037533b1e3E7240b54D")
+	assert.ErrorContains(t, job.Err(), "would revert for owner")
+	for _, cmd := range fake.Calls() {
+		assert.NotEqual(t, "forge", cmd.Name, "a deployment whose actions would revert must not run")
+	}
+
+	job = run(testDeployer)
+	require.NoError(t, job.Err())
+	var sequence []string
+	for _, cmd := range fake.Calls() {
+		if cmd.Name == "forge" || (cmd.Name == "cast" && cmd.Args[0] == "send") {
+			sequence = append(sequence, cmd.Name+" "+cmd.Args[0])
+		}
+	}
+	assert.Equal(t, []string{"forge script", "cast send"}, sequence)
+	require.Len(t, job.ActionResults, 1)
+	assert.Equal(t, ActionSuccess, job.ActionResults[0].Status)
+	assert.Equal(t, job.ProxyAddress, job.ActionResults[0].Contract)
+}
+
+func TestSubmitJob_QueueFull(t *testing.T) {
+	setupJobs(t, newFakeNode(t))
+	activeConfig.Limits.MaxQueueDepth = 1
+
+	// Without a worker the jobs stay queued
+	_, err := SubmitJob(context.Background(), &Job{Kind: JobDeploy, ContractType: "token", Network: DefaultNetwork})
+	require.NoError(t, err)
+	_, err = SubmitJob(context.Background(), &Job{Kind: JobDeploy, ContractType: "token", Network: DefaultNetwork})
+	assert.ErrorIs(t, err, ErrQueueFull)
+}
+
+// TestRecoverJobs reconciles jobs interrupted in each way against the
+// broadcast in their workspace and a fake node
+func TestRecoverJobs(t *testing.T) {
+	setupTest(t, &FakeExecutor{Responses: []FakeResponse{
+		{Name: "cast", Args: []string{"wallet", "address"}, Output: []byte(testDeployer + "\n")},
+	}})
+	node := newFakeNode(t)
+	setupJobs(t, node)
+
+	const (
+		implTx  = "0x8b4e4e2a9a3ec4b1f1ad6b8c2a2f52f4dfc3a4a0e7a7c5d8de2dd0d7a4b6c9e1"
+		proxyTx = "0x3f1c9d2e7b6a5f4e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a1f0e"
+	)
+	node.receipts[implTx] = &txReceipt{Status: "0x1"}
+	node.receipts[proxyTx] = &txReceipt{Status: "0x1"}
+
+	// workspace returns an interrupted workspace, with the fixture broadcast
+	// when broadcast is set
+	workspace := func(broadcast bool) string {
+		dir, err := os.MkdirTemp(WorkspaceRoot, workspacePrefix)
+		if os.IsNotExist(err) {
+			require.NoError(t, os.MkdirAll(WorkspaceRoot, 0700))
+			dir, err = os.MkdirTemp(WorkspaceRoot, workspacePrefix)
+		}
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), []byte("PRIVATE_KEY=0xkey\n"), 0600))
+		if broadcast {
+			path := filepath.Join(dir, "broadcast", "Deploy.s.sol", "19880818")
+			require.NoError(t, os.MkdirAll(path, 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(path, "run-latest.json"), readFixture(t, "forge/broadcast/run-latest.json"), 0644))
+		}
+		return dir
+	}
+	nonce := func(n uint64) *uint64 { return &n }
+	save := func(job Job) {
+		job.Status = JobRunning
+		job.ContractType = "token"
+		job.Network = DefaultNetwork
+		job.StartedAt = time.Now()
+		require.NoError(t, store.SaveJob(job))
+	}
+
+	save(Job{ID: "landed", Kind: JobDeploy, Caller: "admin", Params: copyEnv(tokenEnvVars), DeploymentID: "dep-landed", Workspace: workspace(true), DeployerNonce: nonce(5)})
+	save(Job{ID: "unsent", Kind: JobDeploy, Workspace: workspace(false), DeployerNonce: nonce(7)})
+	save(Job{ID: "actions", Kind: JobActions, Contract: "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707", DeployerNonce: nonce(6)})
+	save(Job{ID: "unknown", Kind: JobDeploy})
+	require.NoError(t, store.SaveJob(Job{ID: "queued", Kind: JobDeploy, Status: JobQueued}))
+
+	RecoverJobs(context.Background())
+
+	status := func(id string) *Job {
+		job, ok := store.Job(id)
+		require.True(t, ok)
+		return job
+	}
+	landed := status("landed")
+	assert.Equal(t, JobSucceeded, landed.Status)
+	assert.True(t, landed.Recovered)
+	// forge records addresses lowercase in the broadcast
+	assert.Equal(t, "0x5fc8d32690cc91d4c39d9d3abcbd16989f875707", landed.ProxyAddress)
+	deployment, err := GetDeployment("dep-landed")
+	require.NoError(t, err)
+	assert.Equal(t, DeploymentSuccess, deployment.Status)
+	assert.Equal(t, "admin", deployment.Caller)
+	assert.Equal(t, "0xDc64a140Aa3E981100a9becA4E685f962f0cF6C9", deployment.ImplementationAddress)
+	assert.Equal(t, proxyTx, deployment.TxHash)
+
+	assert.Equal(t, JobFailed, status("unsent").Status)
+	assert.Equal(t, JobNeedsReview, status("actions").Status)
+	assert.Contains(t, status("actions").Error, "nonce moved from 6 to 7")
+	assert.Equal(t, JobNeedsReview, status("unknown").Status)
+	assert.Equal(t, JobQueued, status("queued").Status)
+
+	// A proxy transaction still pending needs review, and its workspace is
+	// kept with the key scrubbed
+	delete(node.receipts, proxyTx)
+	save(Job{ID: "pending", Kind: JobDeploy, Params: copyEnv(tokenEnvVars), Workspace: workspace(true), DeployerNonce: nonce(5)})
+	RecoverJobs(context.Background())
+	pending := status("pending")
+	assert.Equal(t, JobNeedsReview, pending.Status)
+	assert.Contains(t, pending.Error, "not mined")
+	assert.Contains(t, filepath.Base(pending.Workspace), failedWorkspacePrefix)
+	env, err := os.ReadFile(filepath.Join(pending.Workspace, ".env"))
+	require.NoError(t, err)
+	assert.NotContains(t, string(env), "0xkey")
+
+	require.NoError(t, CleanStaleWorkspaces())
+	assert.DirExists(t, pending.Workspace)
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// anvilPrivateKey is the key of the first well-known anvil dev account
+	anvilPrivateKey = "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+	anvilChainID    = 31337
+)
+
+// DefaultLocalRPCURL is the anvil node backing the local network unless
+// local_network.rpc_url is set
+const DefaultLocalRPCURL = "http://127.0.0.1:8545"
+
+var (
+	anvilCmd *exec.Cmd
+
+	deployedToPattern = regexp.MustCompile(`Deployed to:\s*(0x[0-9a-fA-F]{40})`)
+)
+
+// localDependency is a mock from contracts/test deployed to the local network
+// in place of a mainnet dependency
+type localDependency struct {
+	// Contract type constant replaced by the mock address
+	Constant string
+	// forge create target
+	Contract string
+	// ConstructorOwner passes the deployer as the only constructor argument
+	ConstructorOwner bool
+	// Initialize is called with the deployer after creation when set
+	Initialize string
+}
+
+var localDependencies = []localDependency{
+	{Constant: "XAAIAO_TOKEN_IN_CONTRACT", Contract: "test/MockRewardToken.sol:Token", Initialize: "initialize(address)"},
+	{Constant: "XAAIAO_NFT_HOLDER_CONTRACT", Contract: "test/MockERC1155.t.sol:DLCNode", ConstructorOwner: true},
+	{Constant: "DBC_AI_PROXY", Contract: "test/MockDBCAIContract.sol:DBCStakingContractMock"},
+}
+
+// StartLocalNetwork connects to the anvil node at local_network.rpc_url, starting one
+// when none answers, deploys the mock dependencies and registers the local
+// network with their addresses in place of the mainnet constants.
+func StartLocalNetwork(ctx context.Context, contractPath string) (network *Network, err error) {
+	rpcURL := CurrentConfig().LocalNetwork.RPCURL
+	network = &Network{
+		Name:       LocalNetwork,
+		RPCURL:     rpcURL,
+		Constants:  map[string]string{},
+		privateKey: anvilPrivateKey,
+	}
+	if key := CurrentConfig().LocalNetwork.PrivateKey; key != "" {
+		network.privateKey = key
+	}
+
+	network.ChainID, err = chainID(rpcURL)
+	if err != nil {
+		if err := startAnvil(rpcURL); err != nil {
+			return nil, err
+		}
+		network.ChainID = anvilChainID
+	}
+	slog.Info("local network", "rpc_url", network.RPCURL, "chain_id", network.ChainID)
+
+	deployer, err := deployerAddress(ctx, network.PrivateKey())
+	if err != nil {
+		return nil, err
+	}
+
+	ws, err := NewWorkspace(ctx, contractPath)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		ws.Cleanup(err != nil)
+	}()
+
+	for _, dep := range localDependencies {
+		address, err := deployMock(ctx, ws.Dir, network, dep, deployer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to deploy %s to local network: %v", dep.Contract, err)
+		}
+		network.Constants[dep.Constant] = address
+		slog.Info("local dependency deployed", "constant", dep.Constant, "address", address, "contract", dep.Contract)
+	}
+
+	configMu.Lock()
+	networks[LocalNetwork] = network
+	configMu.Unlock()
+	return network, nil
+}
+
+// StopLocalNetwork stops the anvil node started by StartLocalNetwork
+func StopLocalNetwork() {
+	if anvilCmd == nil || anvilCmd.Process == nil {
+		return
+	}
+	_ = anvilCmd.Process.Kill()
+	_ = anvilCmd.Wait()
+	anvilCmd = nil
+}
+
+func startAnvil(localRPCURL string) error {
+	home, _ := os.UserHomeDir()
+	bin, err := lookupBinary("anvil", CurrentConfig().Toolchain.Anvil, []string{filepath.Join(home, ".foundry", "bin")})
+	if err != nil {
+		return err
+	}
+	rpcURL, err := url.Parse(localRPCURL)
+	if err != nil {
+		return fmt.Errorf("invalid local rpc url: %v", err)
+	}
+	port := rpcURL.Port()
+	if port == "" {
+		port = "8545"
+	}
+
+	// anvil keeps running in the background, so it is started directly
+	// rather than through the executor
+	cmd := exec.Command(bin, "--port", port, "--chain-id", fmt.Sprintf("%d", anvilChainID), "--silent")
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start anvil: %v", err)
+	}
+	anvilCmd = cmd
+	slog.Info("started anvil", "port", port, "pid", cmd.Process.Pid)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, err := chainID(localRPCURL); err == nil {
+			return nil
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+	StopLocalNetwork()
+	return fmt.Errorf("anvil did not answer on %s", localRPCURL)
+}
+
+func deployMock(ctx context.Context, dir string, network *Network, dep localDependency, deployer string) (string, error) {
+	args := []string{
+		"create", dep.Contract,
+		"--rpc-url", network.RPCURL,
+		"--broadcast",
+	}
+	if dep.ConstructorOwner {
+		args = append(args, "--constructor-args", deployer)
+	}
+	env := append(toolchainEnv(), keyEnv+"="+network.PrivateKey())
+	output, err := executor.Run(ctx, Command{Name: toolPath("forge"), Args: args, Dir: dir, Env: env})
+	if err != nil {
+		return "", fmt.Errorf("forge create error: %v: %s", err, string(output))
+	}
+	match := deployedToPattern.FindSubmatch(output)
+	if match == nil {
+		return "", fmt.Errorf("failed to parse deployed address from output: %s", string(output))
+	}
+	address := string(match[1])
+
+	if dep.Initialize != "" {
+		if _, err := sendTransaction(ctx, network, address, dep.Initialize, []string{deployer}); err != nil {
+			return "", err
+		}
+	}
+	return address, nil
+}
+
+// deployerAddress returns the address of privateKey
+func deployerAddress(ctx context.Context, privateKey string) (string, error) {
+	output, err := executor.Run(ctx, Command{
+		Name: toolPath("cast"),
+		Args: []string{"wallet", "address"},
+		Env:  append(toolchainEnv(), keyEnv+"="+privateKey),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to derive deployer address: %v: %s", err, string(output))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
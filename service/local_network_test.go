@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLocalNetwork_DeployToken runs a real token deployment against anvil
+func TestLocalNetwork_DeployToken(t *testing.T) {
+	for _, bin := range []string{"anvil", "forge", "cast"} {
+		if _, err := exec.LookPath(bin); err != nil {
+			t.Skipf("%s not installed", bin)
+		}
+	}
+
+	contractPath, err := filepath.Abs("../contracts")
+	require.NoError(t, err)
+	tc, err := DiscoverToolchain(contractPath)
+	require.NoError(t, err)
+
+	// DeployContract loads ./.env, run from an empty dir
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".env"), nil, 0600))
+	require.NoError(t, os.Chdir(dir))
+
+	originalToolchain, originalRoot, originalConfig := toolchain, WorkspaceRoot, activeConfig
+	t.Cleanup(func() {
+		StopLocalNetwork()
+		configMu.Lock()
+		delete(networks, LocalNetwork)
+		configMu.Unlock()
+		toolchain, WorkspaceRoot, activeConfig = originalToolchain, originalRoot, originalConfig
+		_ = os.Chdir(wd)
+	})
+	toolchain = tc
+	WorkspaceRoot = filepath.Join(dir, "workspaces")
+	cfg := *activeConfig
+	cfg.LocalNetwork.RPCURL = "http://127.0.0.1:18545"
+	activeConfig = &cfg
+	network, err := StartLocalNetwork(context.Background(), contractPath)
+	require.NoError(t, err)
+
+	for _, dep := range localDependencies {
+		assert.Regexp(t, "^0x[0-9a-fA-F]{40}$", network.Constants[dep.Constant])
+	}
+
+	deployer, err := deployerAddress(context.Background(), network.PrivateKey())
+	require.NoError(t, err)
+	spec, err := LookupContractType("token")
+	require.NoError(t, err)
+
+	proxy, err := DeployContract(context.Background(), contractPath, map[string]string{
+		"TOKEN_OWNER":                    deployer,
+		"TOKEN_NAME":                     "LocalToken",
+		"TOKEN_SYMBOL":                   "LT",
+		"TOKEN_INIT_SUPPLY":              "2000000000000000000000000000",
+		"TOKEN_SUPPLY_FIXED_YEARS":       "8",
+		"TOKEN_AMOUNT_CAN_MINT_PER_YEAR": "6000000000000000000000000000",
+		"IAO_CONTRACT_ADDRESS":           network.Constants["XAAIAO_TOKEN_IN_CONTRACT"],
+		"AMOUNT_TO_IAO":                  "100000000000000000000000000",
+	}, spec, network)
+	require.NoError(t, err)
+	assert.Regexp(t, "^0x[0-9a-fA-F]{40}$", proxy)
+}
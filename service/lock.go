@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// lockFileName is the lock file in the data dir, the dir of the store
+const lockFileName = "adc.lock"
+
+// ErrDataDirLocked is returned when a server or a CLI in local mode already
+// uses the data dir
+var ErrDataDirLocked = errors.New("data dir is in use by another process")
+
+// dataDirLock is the lock file held by LockDataDir
+var dataDirLock *os.File
+
+// LockDataDir takes an exclusive lock on the data dir, so a single process
+// writes the store, job logs and workspaces. The lock is held until
+// UnlockDataDir or the process exits.
+func LockDataDir() error {
+	if dataDirLock != nil {
+		return nil
+	}
+	dir := filepath.Dir(StorePath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create data dir: %v", err)
+	}
+	path := filepath.Join(dir, lockFileName)
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open lock file: %v", err)
+	}
+	if err := tryLock(file); err != nil {
+		file.Close()
+		if errors.Is(err, ErrDataDirLocked) {
+			return fmt.Errorf("%w: %s is held", ErrDataDirLocked, path)
+		}
+		return fmt.Errorf("failed to lock %s: %v", path, err)
+	}
+	dataDirLock = file
+	return nil
+}
+
+// UnlockDataDir releases the lock taken by LockDataDir
+func UnlockDataDir() {
+	if dataDirLock == nil {
+		return
+	}
+	_ = dataDirLock.Close()
+	dataDirLock = nil
+}
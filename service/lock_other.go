@@ -0,0 +1,10 @@
+//go:build !unix
+
+package service
+
+import "os"
+
+// tryLock is a no-op where flock is not available
+func tryLock(file *os.File) error {
+	return nil
+}
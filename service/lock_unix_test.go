@@ -0,0 +1,47 @@
+//go:build unix
+
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLockDataDir checks the data dir is refused while another process holds
+// its lock, and held until released
+func TestLockDataDir(t *testing.T) {
+	originalPath := StorePath
+	StorePath = filepath.Join(t.TempDir(), "data", "store.json")
+	t.Cleanup(func() {
+		UnlockDataDir()
+		StorePath = originalPath
+	})
+	path := filepath.Join(filepath.Dir(StorePath), lockFileName)
+	flock := func() (*os.File, error) {
+		file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+		require.NoError(t, err)
+		return file, syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	}
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	other, err := flock()
+	require.NoError(t, err)
+	assert.ErrorIs(t, LockDataDir(), ErrDataDirLocked)
+	other.Close()
+
+	require.NoError(t, LockDataDir())
+	require.NoError(t, LockDataDir())
+	other, err = flock()
+	assert.ErrorIs(t, err, syscall.EWOULDBLOCK)
+	other.Close()
+
+	UnlockDataDir()
+	other, err = flock()
+	assert.NoError(t, err)
+	other.Close()
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type (
+	loggerKey    struct{}
+	requestIDKey struct{}
+)
+
+// SetupLogging installs the default structured logger, configured by the
+// logging config: level debug, info, warn or error, format json or text,
+// and output stderr, stdout or a file path. The standard log package is
+// routed through it as well.
+func SetupLogging(cfg LoggingConfig) error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.Level)); err != nil {
+		return fmt.Errorf("invalid logging level: %s", cfg.Level)
+	}
+
+	var output io.Writer
+	switch cfg.Output {
+	case "", "stderr":
+		output = os.Stderr
+	case "stdout":
+		output = os.Stdout
+	default:
+		file, err := os.OpenFile(cfg.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log output: %v", err)
+		}
+		output = file
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format := strings.ToLower(cfg.Format); format {
+	case "", "json":
+		handler = slog.NewJSONHandler(output, opts)
+	case "text":
+		handler = slog.NewTextHandler(output, opts)
+	default:
+		return fmt.Errorf("invalid logging format: %s", format)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// WithLogger returns a context carrying logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// Logger returns the logger carried by ctx, or the default logger
+func Logger(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// WithRequestID returns a context carrying the request ID id, whose logger
+// tags every line with it
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, id)
+	return WithLogger(ctx, Logger(ctx).With("request_id", id))
+}
+
+func requestIDFrom(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// lineWriter calls onLine with every complete line written to it
+type lineWriter struct {
+	onLine func(line string)
+	buf    bytes.Buffer
+}
+
+func newLineWriter(onLine func(line string)) *lineWriter {
+	return &lineWriter{onLine: onLine}
+}
+
+// newLineLogger logs every line written to it with the logger of ctx,
+// tagged with stream
+func newLineLogger(ctx context.Context, stream string) *lineWriter {
+	logger := Logger(ctx).With("stream", stream)
+	return newLineWriter(func(line string) {
+		logger.Info(line)
+	})
+}
+
+func (l *lineWriter) Write(p []byte) (int, error) {
+	l.buf.Write(p)
+	for {
+		line, err := l.buf.ReadString('\n')
+		if err != nil {
+			// keep the partial line for the next write
+			l.buf.Reset()
+			l.buf.WriteString(line)
+			return len(p), nil
+		}
+		l.onLine(strings.TrimRight(line, "\n"))
+	}
+}
+
+// Flush passes on the trailing partial line
+func (l *lineWriter) Flush() {
+	scanner := bufio.NewScanner(&l.buf)
+	for scanner.Scan() {
+		l.onLine(scanner.Text())
+	}
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// ManifestVersion is bumped on incompatible changes to the manifest layout
+	ManifestVersion = 1
+	// DefaultProject groups deployments recorded without a project name
+	DefaultProject = "default"
+)
+
+// Manifest is an address book of the latest successful deployment of each
+// contract type, keyed by network, project and contract type
+type Manifest struct {
+	Version     int                         `json:"version" yaml:"version"`
+	GeneratedAt time.Time                   `json:"generated_at" yaml:"generated_at"`
+	Networks    map[string]*ManifestNetwork `json:"networks" yaml:"networks"`
+}
+
+type ManifestNetwork struct {
+	ChainID  int64                                   `json:"chain_id" yaml:"chain_id"`
+	Projects map[string]map[string]*ManifestContract `json:"projects" yaml:"projects"`
+}
+
+type ManifestContract struct {
+	Proxy          string    `json:"proxy" yaml:"proxy"`
+	Implementation string    `json:"implementation,omitempty" yaml:"implementation,omitempty"`
+	ContractName   string    `json:"contract_name,omitempty" yaml:"contract_name,omitempty"`
+	ABI            string    `json:"abi,omitempty" yaml:"abi,omitempty"`
+	BlockNumber    uint64    `json:"block_number,omitempty" yaml:"block_number,omitempty"`
+	TxHash         string    `json:"tx_hash,omitempty" yaml:"tx_hash,omitempty"`
+	DeploymentID   string    `json:"deployment_id" yaml:"deployment_id"`
+	DeployedAt     time.Time `json:"deployed_at" yaml:"deployed_at"`
+}
+
+// latestDeployments returns the successful deployments matching filter,
+// oldest first
+func latestDeployments(filter DeploymentFilter) []*Deployment {
+	if store == nil {
+		return nil
+	}
+	filter.Status = DeploymentSuccess
+	deployments := store.Deployments(filter.match)
+	sort.SliceStable(deployments, func(i, j int) bool {
+		return deployments[i].CreatedAt.Before(deployments[j].CreatedAt)
+	})
+	return deployments
+}
+
+// BuildManifest returns the address book of the deployments matching filter
+func BuildManifest(filter DeploymentFilter) *Manifest {
+	manifest := &Manifest{
+		Version:     ManifestVersion,
+		GeneratedAt: time.Now().UTC(),
+		Networks:    map[string]*ManifestNetwork{},
+	}
+	for _, d := range latestDeployments(filter) {
+		network, ok := manifest.Networks[d.Network]
+		if !ok {
+			network = &ManifestNetwork{ChainID: d.ChainID, Projects: map[string]map[string]*ManifestContract{}}
+			manifest.Networks[d.Network] = network
+		}
+		project := d.ProjectName
+		if project == "" {
+			project = DefaultProject
+		}
+		if network.Projects[project] == nil {
+			network.Projects[project] = map[string]*ManifestContract{}
+		}
+		network.Projects[project][strings.ToLower(d.ContractType)] = &ManifestContract{
+			Proxy:          d.ProxyAddress,
+			Implementation: d.ImplementationAddress,
+			ContractName:   d.ContractName,
+			ABI:            d.ABI,
+			BlockNumber:    d.BlockNumber,
+			TxHash:         d.TxHash,
+			DeploymentID:   d.ID,
+			DeployedAt:     d.FinishedAt,
+		}
+	}
+	return manifest
+}
+
+// DevOpsRun mirrors the forge broadcast run-latest.json layout read by
+// foundry-devops DevOpsTools.get_most_recent_deployment
+type DevOpsRun struct {
+	Transactions []DevOpsTransaction `json:"transactions"`
+	Receipts     []interface{}       `json:"receipts"`
+	Libraries    []string            `json:"libraries"`
+	Pending      []string            `json:"pending"`
+	Returns      map[string]string   `json:"returns"`
+	Timestamp    int64               `json:"timestamp"`
+	Chain        int64               `json:"chain"`
+	Commit       *string             `json:"commit"`
+}
+
+type DevOpsTransaction struct {
+	Hash            string `json:"hash"`
+	TransactionType string `json:"transactionType"`
+	ContractName    string `json:"contractName"`
+	ContractAddress string `json:"contractAddress"`
+}
+
+// BuildDevOpsRun returns the deployments on one network as a broadcast run.
+// Each proxy is listed under its implementation contract name, so saved as
+// broadcast/<any>/<chain id>/run-latest.json in contracts/,
+// get_most_recent_deployment("NFTStaking", chainId) resolves to the latest
+// staking proxy.
+func BuildDevOpsRun(filter DeploymentFilter) (*DevOpsRun, error) {
+	if filter.Network == "" {
+		return nil, fmt.Errorf("network is required for the devops format")
+	}
+	network, err := LookupNetwork(filter.Network)
+	if err != nil {
+		return nil, err
+	}
+	run := &DevOpsRun{
+		Transactions: []DevOpsTransaction{},
+		Receipts:     []interface{}{},
+		Libraries:    []string{},
+		Pending:      []string{},
+		Returns:      map[string]string{},
+		Chain:        network.ChainID,
+	}
+	for _, d := range latestDeployments(filter) {
+		if d.ContractName == "" {
+			continue
+		}
+		run.Transactions = append(run.Transactions, DevOpsTransaction{
+			Hash:            d.TxHash,
+			TransactionType: "CREATE",
+			ContractName:    d.ContractName,
+			ContractAddress: d.ProxyAddress,
+		})
+		run.Timestamp = d.FinishedAt.Unix()
+	}
+	return run, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Deployment stages timed by the stage duration histogram
+const (
+	StageCompile   = "compile"
+	StageBroadcast = "broadcast"
+	StageVerify    = "verify"
+)
+
+var (
+	deploymentsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "adc_deployments_total",
+		Help: "Deployments by contract type, network and outcome.",
+	}, []string{"contract_type", "network", "status"})
+
+	stageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "adc_deploy_stage_duration_seconds",
+		Help: "Duration of the compile, broadcast and verify stages of a deployment.",
+		// 1s to ~34m, deploys compile for minutes on a cold cache
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"stage"})
+
+	jobsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "adc_jobs_in_flight",
+		Help: "Deployments and action runs currently executing.",
+	}, []string{"kind"})
+
+	queueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "adc_job_queue_depth",
+		Help: "Jobs waiting to run.",
+	})
+
+	gasUsedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "adc_gas_used_total",
+		Help: "Gas used by deployment transactions.",
+	}, []string{"network"})
+
+	gasSpentTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "adc_gas_spent_wei_total",
+		Help: "Fees paid by deployment transactions, in wei.",
+	}, []string{"network"})
+
+	deployerBalance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "adc_deployer_balance_wei",
+		Help: "Deployer balance in wei, refreshed by the readiness check.",
+	}, []string{"network", "address"})
+)
+
+// observeDeployment counts a finished deployment
+func observeDeployment(deployment *Deployment) {
+	deploymentsTotal.WithLabelValues(strings.ToLower(deployment.ContractType), deployment.Network, deployment.Status).Inc()
+}
+
+func observeStage(stage string, start time.Time) {
+	stageDuration.WithLabelValues(stage).Observe(time.Since(start).Seconds())
+}
+
+// trackInFlight marks a job of kind as running until the returned func is called
+func trackInFlight(kind string) func() {
+	gauge := jobsInFlight.WithLabelValues(kind)
+	gauge.Inc()
+	return gauge.Dec
+}
+
+func observeGas(network string, gasUsed uint64, fee *big.Int) {
+	gasUsedTotal.WithLabelValues(network).Add(float64(gasUsed))
+	if fee != nil {
+		wei, _ := new(big.Float).SetInt(fee).Float64()
+		gasSpentTotal.WithLabelValues(network).Add(wei)
+	}
+}
+
+func observeDeployerBalance(network, address string, balance *big.Int) {
+	wei, _ := new(big.Float).SetInt(balance).Float64()
+	deployerBalance.WithLabelValues(network, address).Set(wei)
+}
+
+// stageTimer splits the forge script run into the broadcast and verify
+// stages by watching its output for the start of verification
+type stageTimer struct {
+	*lineWriter
+
+	mu       sync.Mutex
+	start    time.Time
+	verifyAt time.Time
+}
+
+func newStageTimer() *stageTimer {
+	t := &stageTimer{start: time.Now()}
+	t.lineWriter = newLineWriter(func(line string) {
+		if strings.HasPrefix(line, "Start verification") {
+			t.mu.Lock()
+			t.verifyAt = time.Now()
+			t.mu.Unlock()
+		}
+	})
+	return t
+}
+
+// observe records the stage durations and spans once the script has exited
+func (t *stageTimer) observe(ctx context.Context) {
+	t.Flush()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	end := time.Now()
+	if t.verifyAt.IsZero() {
+		stageDuration.WithLabelValues(StageBroadcast).Observe(end.Sub(t.start).Seconds())
+		recordSpan(ctx, StageBroadcast, t.start, end)
+		return
+	}
+	stageDuration.WithLabelValues(StageBroadcast).Observe(t.verifyAt.Sub(t.start).Seconds())
+	stageDuration.WithLabelValues(StageVerify).Observe(end.Sub(t.verifyAt).Seconds())
+	recordSpan(ctx, StageBroadcast, t.start, t.verifyAt)
+	recordSpan(ctx, StageVerify, t.verifyAt, end)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	DefaultNetwork = "dbc-mainnet"
+	LocalNetwork   = "local"
+)
+
+// Network is a chain contracts can be deployed to
+type Network struct {
+	Name    string `json:"name"`
+	RPCURL  string `json:"rpc_url"`
+	ChainID int64  `json:"chain_id"`
+	// Verifier API used by forge, empty to skip verification
+	VerifierURL string `json:"verifier_url,omitempty"`
+	// Overrides of the contract type constants on this network
+	Constants map[string]string `json:"constants,omitempty"`
+
+	// privateKey overrides the deployer key of the signer config
+	privateKey string
+}
+
+var networks = map[string]*Network{
+	DefaultNetwork: {
+		Name:        DefaultNetwork,
+		RPCURL:      DBC_MAINNET,
+		ChainID:     DBC_MAINNET_CHAIN_ID,
+		VerifierURL: MAIN_NET_VERIFIER_URL,
+	},
+}
+
+// LookupNetwork returns the network registered under name, or the default
+// network when name is empty
+func LookupNetwork(name string) (*Network, error) {
+	if name == "" {
+		name = DefaultNetwork
+	}
+	configMu.RLock()
+	network, ok := networks[strings.ToLower(name)]
+	configMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown network: %s", name)
+	}
+	return network, nil
+}
+
+// Networks returns the registered networks sorted by name
+func Networks() []*Network {
+	configMu.RLock()
+	list := make([]*Network, 0, len(networks))
+	for _, network := range networks {
+		list = append(list, network)
+	}
+	configMu.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// PrivateKey returns the deployer key used on the network
+func (n *Network) PrivateKey() string {
+	if n.privateKey != "" {
+		return n.privateKey
+	}
+	return CurrentConfig().Signer.PrivateKey
+}
+
+// Signer returns the key and scope of the signer named name on the network,
+// the deployer when name is empty
+func (n *Network) Signer(name string) (string, SignerScope, error) {
+	cfg := CurrentConfig()
+	if name == "" || strings.EqualFold(name, DeployerSigner) {
+		return n.PrivateKey(), cfg.Signer.Scope, nil
+	}
+	for _, key := range cfg.Signer.Keys {
+		if strings.EqualFold(key.Name, name) {
+			return key.PrivateKey, key.Scope, nil
+		}
+	}
+	return "", SignerScope{}, fmt.Errorf("unknown signer: %s", name)
+}
+
+// Allows reports whether the scope covers calling the function with
+// signature on a contract of contractType on network. Only the listed
+// functions are allowed, while empty networks and contract types allow any.
+func (s SignerScope) Allows(network, contractType, signature string) bool {
+	name, _, _ := strings.Cut(signature, "(")
+	return (len(s.Networks) == 0 || scopeIncludes(s.Networks, network)) &&
+		(len(s.ContractTypes) == 0 || scopeIncludes(s.ContractTypes, contractType)) &&
+		(scopeIncludes(s.Functions, name) || slices.Contains(s.Functions, signature))
+}
+
+func scopeIncludes(list []string, value string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// constant returns the value of a contract type constant on the network
+func (n *Network) constant(key, value string) string {
+	if override, ok := n.Constants[key]; ok {
+		return override
+	}
+	return value
+}
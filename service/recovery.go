@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// deployerNonce returns the pending nonce of the deployer on network, or of
+// the named signer of a transact job
+func deployerNonce(ctx context.Context, network *Network, signer string) (uint64, error) {
+	key, _, err := network.Signer(signer)
+	if err != nil {
+		return 0, err
+	}
+	if key == "" {
+		return 0, fmt.Errorf("deployer key not loaded")
+	}
+	address, err := cachedDeployerAddress(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	return transactionCount(network.RPCURL, address, "pending")
+}
+
+// RecoverJobs reconciles the jobs a crash left running. A deploy job is
+// checked against the broadcast in its workspace and the receipts of the
+// transactions listed there; without a broadcast, or for an actions job,
+// the deployer's nonce tells whether anything was sent. Each job ends up
+// succeeded (recording the recovered deployment), failed or needs_review.
+// Run it before CleanStaleWorkspaces, which removes the broadcasts.
+func RecoverJobs(ctx context.Context) {
+	if store == nil {
+		return
+	}
+	for _, job := range store.Jobs() {
+		if job.Status != JobRunning {
+			continue
+		}
+		logger := Logger(ctx).With("job_id", job.ID, "kind", job.Kind, "contract_type", job.ContractType)
+		jobCtx := WithLogger(WithCaller(ctx, job.Caller), logger)
+
+		status, reason := recoverJob(jobCtx, job)
+		job.Status = status
+		job.Error = reason
+		job.Recovered = true
+		job.FinishedAt = time.Now()
+		if status == JobNeedsReview && job.Workspace != "" {
+			if _, err := os.Stat(job.Workspace); err == nil {
+				ws := &Workspace{Dir: job.Workspace, EnvPath: filepath.Join(job.Workspace, ".env"), logger: logger}
+				job.Workspace = ws.Keep()
+			}
+		}
+		if err := store.SaveJob(*job); err != nil {
+			logger.Error("failed to persist job", "error", err)
+		}
+		level := slog.LevelInfo
+		if status == JobNeedsReview {
+			level = slog.LevelWarn
+		}
+		logger.Log(ctx, level, "recovered interrupted job", "status", status, "proxy_address", job.ProxyAddress, "reason", reason)
+	}
+}
+
+// recoverJob returns the status of the interrupted job, with the reason when
+// it did not succeed
+func recoverJob(ctx context.Context, job *Job) (string, string) {
+	spec, err := LookupContractType(job.ContractType)
+	if err != nil {
+		return JobNeedsReview, err.Error()
+	}
+	network, err := LookupNetwork(job.Network)
+	if err != nil {
+		return JobNeedsReview, err.Error()
+	}
+
+	if job.Kind == JobDeploy && job.Workspace != "" {
+		run, err := loadBroadcast(job.Workspace, spec.Script)
+		if err == nil && len(run.Transactions) > 0 {
+			return recoverDeployment(ctx, job, spec, network, run)
+		}
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return JobNeedsReview, err.Error()
+		}
+	}
+
+	// Nothing recorded as broadcast: the job failed cleanly only if the
+	// deployer sent nothing since it started
+	if job.DeployerNonce == nil {
+		return JobNeedsReview, "interrupted; deployer nonce at start unknown"
+	}
+	nonce, err := deployerNonce(ctx, network, job.signer())
+	if err != nil {
+		return JobNeedsReview, fmt.Sprintf("interrupted; failed to read deployer nonce: %v", err)
+	}
+	if nonce != *job.DeployerNonce {
+		return JobNeedsReview, fmt.Sprintf("interrupted; deployer nonce moved from %d to %d", *job.DeployerNonce, nonce)
+	}
+	return JobFailed, "interrupted before sending any transaction"
+}
+
+// recoverDeployment checks the receipts of the transactions in the broadcast
+// of job and records the deployment when all of them landed
+func recoverDeployment(ctx context.Context, job *Job, spec *ContractTypeSpec, network *Network, run *broadcastRun) (string, string) {
+	for _, tx := range run.Transactions {
+		receipt, err := transactionReceipt(network.RPCURL, tx.Hash)
+		if err != nil {
+			return JobNeedsReview, fmt.Sprintf("failed to fetch receipt of %s: %v", tx.Hash, err)
+		}
+		if receipt == nil {
+			return JobNeedsReview, fmt.Sprintf("transaction %s not mined", tx.Hash)
+		}
+		if receipt.Status != "0x1" {
+			return JobFailed, fmt.Sprintf("transaction %s reverted", tx.Hash)
+		}
+	}
+
+	// The script deploys the implementation, then the ERC1967Proxy
+	var proxyAddress string
+	for _, tx := range run.Transactions {
+		if tx.TransactionType == "CREATE" && tx.ContractName == "ERC1967Proxy" {
+			proxyAddress = tx.ContractAddress
+		}
+	}
+	if proxyAddress == "" {
+		return JobNeedsReview, "all transactions landed but no proxy was created"
+	}
+
+	deployment := newDeployment(ctx, spec, network, job.Params)
+	if job.DeploymentID != "" {
+		deployment.ID = job.DeploymentID
+	}
+	deployment.CreatedAt = job.StartedAt
+	deployment.ProxyAddress = proxyAddress
+	if err := readBroadcast(job.Workspace, spec, deployment); err != nil {
+		Logger(ctx).Warn("failed to read broadcast", "error", err)
+	}
+	recordDeployment(ctx, deployment, proxyAddress, nil)
+	job.ProxyAddress = proxyAddress
+	if len(job.Actions) > 0 {
+		return JobNeedsReview, fmt.Sprintf("deployed %s; post-deploy actions were interrupted", proxyAddress)
+	}
+	return JobSucceeded, ""
+}
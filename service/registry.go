@@ -0,0 +1,236 @@
+package service
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// builtinContractTypes declares the contract types shipped with the service
+//
+//go:embed contract_types.json
+var builtinContractTypes []byte
+
+// DefaultContractTypesPath is the contract types file unless
+// paths.contract_types is set
+const DefaultContractTypesPath = "./contract_types.json"
+
+var (
+	// ContractTypesPath optionally declares additional contract types, or
+	// overrides built-in ones with the same name
+	ContractTypesPath = DefaultContractTypesPath
+
+	registry = map[string]*ContractTypeSpec{}
+)
+
+// ContractTypeSpec declares how a contract type is deployed
+type ContractTypeSpec struct {
+	// Name used in routes, e.g. /deploy/:type
+	Name string `json:"name"`
+	// Forge script relative to the contracts tree, e.g. script/token/Deploy.s.sol:Deploy
+	Script string `json:"script"`
+	// Toolchain image pinned for the containerized runner
+	Image string `json:"image,omitempty"`
+	// Compiled contract queried by the call API, e.g. Token.sol:Token
+	Contract string `json:"contract,omitempty"`
+	// Forge script upgrading a deployed proxy with Upgrades.upgradeProxy,
+	// e.g. script/token/Upgrade.s.sol:Upgrade
+	UpgradeScript string `json:"upgrade_script,omitempty"`
+	// Env variable the upgrade script reads the proxy from, PROXY_CONTRACT
+	// by default
+	ProxyEnv string `json:"proxy_env,omitempty"`
+	// Request field to script env variable mapping
+	Env map[string]string `json:"env"`
+	// Env variables injected into every deployment
+	Constants map[string]string `json:"constants,omitempty"`
+	// Post-deploy action name to function signature
+	Actions map[string]string `json:"actions,omitempty"`
+	// JSON schema the request body is validated against
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
+
+func init() {
+	specs, err := parseContractTypes(builtinContractTypes)
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in contract types: %v", err))
+	}
+	for _, spec := range specs {
+		registry[strings.ToLower(spec.Name)] = spec
+	}
+}
+
+// LoadContractTypes registers the contract types declared in the file at
+// path. A missing file is not an error.
+func LoadContractTypes(path string) error {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read contract types: %v", err)
+	}
+	specs, err := parseContractTypes(content)
+	if err != nil {
+		return fmt.Errorf("invalid contract types in %s: %v", path, err)
+	}
+	for _, spec := range specs {
+		registry[strings.ToLower(spec.Name)] = spec
+	}
+	return nil
+}
+
+// LookupContractType returns the contract type registered under name
+func LookupContractType(name string) (*ContractTypeSpec, error) {
+	spec, ok := registry[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unknown contract type: %s", name)
+	}
+	return spec, nil
+}
+
+// ContractTypes returns the registered contract types sorted by name
+func ContractTypes() []*ContractTypeSpec {
+	specs := make([]*ContractTypeSpec, 0, len(registry))
+	for _, spec := range registry {
+		specs = append(specs, spec)
+	}
+	sort.Slice(specs, func(i, j int) bool {
+		return specs[i].Name < specs[j].Name
+	})
+	return specs
+}
+
+// ContractTypeInfo describes a deployable contract type
+type ContractTypeInfo struct {
+	Name   string `json:"name"`
+	Script string `json:"script"`
+	// Networks the type can be deployed to
+	Networks []string `json:"networks"`
+	// Post-deploy actions accepted by the type
+	Actions  []string             `json:"actions,omitempty"`
+	Versions ContractTypeVersions `json:"versions"`
+}
+
+// ContractTypeVersions identifies what a deployment of the type runs now
+type ContractTypeVersions struct {
+	// Build is the key of the compiled artifacts, changing with the sources
+	Build string `json:"build,omitempty"`
+	Forge string `json:"forge,omitempty"`
+	Solc  string `json:"solc,omitempty"`
+	// Image is the toolchain image of the containerized runner
+	Image string `json:"image,omitempty"`
+}
+
+// DescribeContractTypes returns the registered contract types with the
+// networks and versions they are deployed with
+func DescribeContractTypes() []ContractTypeInfo {
+	var networkNames []string
+	for _, network := range Networks() {
+		networkNames = append(networkNames, network.Name)
+	}
+	var versions ContractTypeVersions
+	if build := CurrentBuild(); build != nil {
+		versions.Build = build.Key
+	}
+	if tc := CurrentToolchain(); tc != nil {
+		versions.Forge, versions.Solc, versions.Image = tc.ForgeVersion, tc.SolcVersion, tc.Image
+	}
+
+	specs := ContractTypes()
+	infos := make([]ContractTypeInfo, 0, len(specs))
+	for _, spec := range specs {
+		info := ContractTypeInfo{
+			Name:     spec.Name,
+			Script:   spec.Script,
+			Networks: networkNames,
+			Versions: versions,
+		}
+		// The image pinned by the type only applies to a container runner
+		if spec.Image != "" && versions.Image != "" {
+			info.Versions.Image = spec.Image
+		}
+		for name := range spec.Actions {
+			info.Actions = append(info.Actions, name)
+		}
+		sort.Strings(info.Actions)
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+// EnvVars maps the request params to the script env variables
+func (spec *ContractTypeSpec) EnvVars(params map[string]interface{}) (map[string]string, error) {
+	envVars := make(map[string]string, len(spec.Env))
+	for field, key := range spec.Env {
+		value, ok := params[field]
+		if !ok || value == nil {
+			continue
+		}
+		str, err := envValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %v", field, err)
+		}
+		envVars[key] = str
+	}
+	return envVars, nil
+}
+
+// ValidateParams validates the request params against the type's schema
+func (spec *ContractTypeSpec) ValidateParams(params map[string]interface{}) error {
+	if len(spec.Schema) == 0 {
+		return nil
+	}
+	var schema Schema
+	if err := json.Unmarshal(spec.Schema, &schema); err != nil {
+		return fmt.Errorf("invalid schema for contract type %s: %v", spec.Name, err)
+	}
+	return schema.Validate(params)
+}
+
+func parseContractTypes(content []byte) ([]*ContractTypeSpec, error) {
+	var specs []*ContractTypeSpec
+	if err := json.Unmarshal(content, &specs); err != nil {
+		return nil, err
+	}
+	for _, spec := range specs {
+		if spec.Name == "" || spec.Script == "" {
+			return nil, fmt.Errorf("contract type requires name and script")
+		}
+		if len(spec.Schema) > 0 {
+			var schema Schema
+			if err := json.Unmarshal(spec.Schema, &schema); err != nil {
+				return nil, fmt.Errorf("invalid schema for contract type %s: %v", spec.Name, err)
+			}
+		}
+		for name, signature := range spec.Actions {
+			if !strings.HasPrefix(signature, name+"(") || !strings.HasSuffix(signature, ")") {
+				return nil, fmt.Errorf("invalid signature %q for action %s of contract type %s", signature, name, spec.Name)
+			}
+		}
+	}
+	return specs, nil
+}
+
+// envValue formats a decoded JSON value as an env variable value
+func envValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	default:
+		content, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	}
+}
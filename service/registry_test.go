@@ -0,0 +1,101 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// restoreRegistry restores the built-in contract types after the test
+func restoreRegistry(t *testing.T) {
+	original := make(map[string]*ContractTypeSpec, len(registry))
+	for name, spec := range registry {
+		original[name] = spec
+	}
+	t.Cleanup(func() { registry = original })
+}
+
+// TestLoadContractTypes checks declared types are registered, override the
+// built-in ones case insensitively and are rejected when invalid
+func TestLoadContractTypes(t *testing.T) {
+	restoreRegistry(t)
+	dir := t.TempDir()
+
+	assert.NoError(t, LoadContractTypes(filepath.Join(dir, "missing.json")))
+
+	path := filepath.Join(dir, "contract_types.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+		{"name": "Vault", "script": "script/vault/Deploy.s.sol:Deploy", "env": {"owner": "VAULT_OWNER"},
+		 "actions": {"pause": "pause()"},
+		 "schema": {"type": "object", "required": ["owner"], "properties": {"owner": {"type": "string"}}}},
+		{"name": "TOKEN", "script": "script/token/DeployV2.s.sol:Deploy", "env": {}}
+	]`), 0644))
+	require.NoError(t, LoadContractTypes(path))
+
+	spec, err := LookupContractType("vault")
+	require.NoError(t, err)
+	assert.Equal(t, "Vault", spec.Name)
+	assert.Equal(t, "pause()", spec.Actions["pause"])
+	assert.ErrorContains(t, spec.ValidateParams(map[string]interface{}{}), "missing required field owner")
+	assert.NoError(t, spec.ValidateParams(map[string]interface{}{"owner": "0x1"}))
+
+	token, err := LookupContractType("token")
+	require.NoError(t, err)
+	assert.Equal(t, "script/token/DeployV2.s.sol:Deploy", token.Script)
+
+	var names []string
+	for _, spec := range ContractTypes() {
+		names = append(names, spec.Name)
+	}
+	assert.Equal(t, []string{"IAO", "TOKEN", "Vault", "payment", "staking"}, names)
+
+	_, err = LookupContractType("unknown")
+	assert.EqualError(t, err, "unknown contract type: unknown")
+
+	for name, content := range map[string]string{
+		"missing script":    `[{"name": "x"}]`,
+		"invalid schema":    `[{"name": "x", "script": "s", "schema": {"required": "owner"}}]`,
+		"invalid signature": `[{"name": "x", "script": "s", "actions": {"pause": "unpause()"}}]`,
+		"invalid json":      `{`,
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		assert.Error(t, LoadContractTypes(path), name)
+	}
+	_, err = LookupContractType("x")
+	assert.Error(t, err)
+}
+
+// TestContractTypeSpec_EnvVars checks request params are formatted as env
+// values and unset params are left out
+func TestContractTypeSpec_EnvVars(t *testing.T) {
+	spec := &ContractTypeSpec{Env: map[string]string{
+		"name":    "TOKEN_NAME",
+		"supply":  "TOKEN_SUPPLY",
+		"ratio":   "TOKEN_RATIO",
+		"fixed":   "TOKEN_FIXED",
+		"holders": "TOKEN_HOLDERS",
+		"owner":   "TOKEN_OWNER",
+		"admin":   "TOKEN_ADMIN",
+	}}
+	envVars, err := spec.EnvVars(map[string]interface{}{
+		"name":    "Token",
+		"supply":  json.Number("2000000000000000000000000000"),
+		"ratio":   1e21,
+		"fixed":   true,
+		"holders": []interface{}{"0x1", "0x2"},
+		"admin":   nil,
+		"extra":   "ignored",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"TOKEN_NAME":    "Token",
+		"TOKEN_SUPPLY":  "2000000000000000000000000000",
+		"TOKEN_RATIO":   "1000000000000000000000",
+		"TOKEN_FIXED":   "true",
+		"TOKEN_HOLDERS": `["0x1","0x2"]`,
+	}, envVars)
+}
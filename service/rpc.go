@@ -0,0 +1,165 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var rpcClient = &http.Client{Timeout: 10 * time.Second}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// rpcCall performs a JSON-RPC call against url and decodes the result
+func rpcCall(url, method string, result interface{}, params ...interface{}) error {
+	if params == nil {
+		params = []interface{}{}
+	}
+	body, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: 1, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+	resp, err := rpcClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s request failed: %v", method, err)
+	}
+	defer resp.Body.Close()
+
+	var rpcResp rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("%s response invalid: %v", method, err)
+	}
+	if rpcResp.Error != nil {
+		return fmt.Errorf("%s failed: %s", method, rpcResp.Error.Message)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(rpcResp.Result, result)
+}
+
+// chainID returns the chain ID reported by the node at url
+func chainID(url string) (int64, error) {
+	var hex string
+	if err := rpcCall(url, "eth_chainId", &hex); err != nil {
+		return 0, err
+	}
+	return parseHexInt(hex)
+}
+
+func parseHexInt(hex string) (int64, error) {
+	return strconv.ParseInt(strings.TrimPrefix(hex, "0x"), 16, 64)
+}
+
+// transactionCount returns the nonce of address at block ("latest" or "pending")
+func transactionCount(url, address, block string) (uint64, error) {
+	var hex string
+	if err := rpcCall(url, "eth_getTransactionCount", &hex, address, block); err != nil {
+		return 0, err
+	}
+	nonce, err := parseHexInt(hex)
+	if err != nil {
+		return 0, fmt.Errorf("invalid nonce %q: %v", hex, err)
+	}
+	return uint64(nonce), nil
+}
+
+// rpcLog is a log of a transaction receipt or eth_getLogs
+type rpcLog struct {
+	Address         string   `json:"address"`
+	Topics          []string `json:"topics"`
+	Data            string   `json:"data"`
+	BlockNumber     string   `json:"blockNumber"`
+	BlockHash       string   `json:"blockHash"`
+	TransactionHash string   `json:"transactionHash"`
+	LogIndex        string   `json:"logIndex"`
+}
+
+// blockNumber returns the number of the latest block
+func blockNumber(url string) (uint64, error) {
+	var hex string
+	if err := rpcCall(url, "eth_blockNumber", &hex); err != nil {
+		return 0, err
+	}
+	number, err := parseHexInt(hex)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block number %q: %v", hex, err)
+	}
+	return uint64(number), nil
+}
+
+// rpcBlock is the subset of a block header read by the indexer
+type rpcBlock struct {
+	Number    string `json:"number"`
+	Hash      string `json:"hash"`
+	Timestamp string `json:"timestamp"`
+}
+
+// blockHeader returns the block tag, a hex number or latest
+func blockHeader(url, tag string) (*rpcBlock, error) {
+	var block *rpcBlock
+	if err := rpcCall(url, "eth_getBlockByNumber", &block, tag, false); err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %s not found", tag)
+	}
+	return block, nil
+}
+
+// blockHash returns the hash of the block number
+func blockHash(url string, number uint64) (string, error) {
+	block, err := blockHeader(url, hexBlock(number))
+	if err != nil {
+		return "", err
+	}
+	return block.Hash, nil
+}
+
+// contractLogs returns the logs contract emitted in the block range
+func contractLogs(url, contract string, from, to uint64) ([]rpcLog, error) {
+	var logs []rpcLog
+	filter := map[string]string{"address": contract, "fromBlock": hexBlock(from), "toBlock": hexBlock(to)}
+	if err := rpcCall(url, "eth_getLogs", &logs, filter); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func hexBlock(number uint64) string {
+	return "0x" + strconv.FormatUint(number, 16)
+}
+
+// txReceipt is the subset of a transaction receipt checked by job recovery
+type txReceipt struct {
+	Status          string `json:"status"`
+	ContractAddress string `json:"contractAddress"`
+	BlockNumber     string `json:"blockNumber"`
+}
+
+// transactionReceipt returns the receipt of the transaction hash, or nil
+// while it is not mined
+func transactionReceipt(url, hash string) (*txReceipt, error) {
+	var receipt *txReceipt
+	if err := rpcCall(url, "eth_getTransactionReceipt", &receipt, hash); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema used to validate deploy requests:
+// type, properties, required, additionalProperties, items, enum, pattern,
+// minLength, maxLength, minimum and maximum.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Example              interface{}        `json:"example,omitempty"`
+}
+
+// Validate checks value, as decoded by encoding/json, against the schema
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate("$", value)
+}
+
+func (s *Schema) validate(path string, value interface{}) error {
+	if err := s.validateType(path, value); err != nil {
+		return err
+	}
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		return fmt.Errorf("%s: value %v is not one of %v", path, value, s.Enum)
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, field := range s.Required {
+			if _, ok := v[field]; !ok {
+				return fmt.Errorf("%s: missing required field %s", path, field)
+			}
+		}
+		fields := make([]string, 0, len(v))
+		for field := range v {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			prop, ok := s.Properties[field]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return fmt.Errorf("%s: unknown field %s", path, field)
+				}
+				continue
+			}
+			if err := prop.validate(path+"."+field, v[field]); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case string:
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			return fmt.Errorf("%s: length must be at least %d", path, *s.MinLength)
+		}
+		if s.MaxLength != nil && len(v) > *s.MaxLength {
+			return fmt.Errorf("%s: length must be at most %d", path, *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				return fmt.Errorf("%s: invalid pattern %q: %v", path, s.Pattern, err)
+			}
+			if !re.MatchString(v) {
+				return fmt.Errorf("%s: value %q does not match pattern %s", path, v, s.Pattern)
+			}
+		}
+	}
+
+	if n, ok := number(value); ok {
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Errorf("%s: value must be at least %v", path, *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fmt.Errorf("%s: value must be at most %v", path, *s.Maximum)
+		}
+	}
+	return nil
+}
+
+func (s *Schema) validateType(path string, value interface{}) error {
+	if s.Type == "" {
+		return nil
+	}
+	var ok bool
+	switch s.Type {
+	case "object":
+		_, ok = value.(map[string]interface{})
+	case "array":
+		_, ok = value.([]interface{})
+	case "string":
+		_, ok = value.(string)
+	case "boolean":
+		_, ok = value.(bool)
+	case "number":
+		_, ok = number(value)
+	case "integer":
+		switch v := value.(type) {
+		case json.Number:
+			_, err := v.Int64()
+			ok = err == nil || !strings.ContainsAny(v.String(), ".eE")
+		case float64:
+			ok = v == float64(int64(v))
+		}
+	case "null":
+		ok = value == nil
+	default:
+		return fmt.Errorf("%s: unsupported schema type %s", path, s.Type)
+	}
+	if !ok {
+		return fmt.Errorf("%s: expected %s", path, s.Type)
+	}
+	return nil
+}
+
+func number(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	got, _ := envValue(value)
+	for _, candidate := range enum {
+		if want, _ := envValue(candidate); want == got {
+			return true
+		}
+	}
+	return false
+}
+
+// SchemaOf generates the schema of the JSON encoding of v, a struct, from
+// its json, binding and example tags. The binding rules required, min, max,
+// len, oneof and nowhitespace are translated, and dive applies the rules
+// after it to the items of a slice.
+func SchemaOf(v interface{}) *Schema {
+	return schemaOfType(reflect.TypeOf(v))
+}
+
+func schemaOfType(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaOfType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if !field.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			prop := schemaOfType(field.Type)
+			if example, ok := field.Tag.Lookup("example"); ok {
+				exampleOf(prop, example)
+			}
+			if applyBinding(prop, field.Tag.Get("binding")) {
+				schema.Required = append(schema.Required, name)
+			}
+			schema.Properties[name] = prop
+		}
+		return schema
+	}
+	return &Schema{}
+}
+
+// applyBinding translates the binding rules to schema keywords and reports
+// whether the field is required
+func applyBinding(schema *Schema, rules string) (required bool) {
+	if rules == "" {
+		return false
+	}
+	for _, rule := range strings.Split(rules, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			if schema.Items != nil {
+				applyBinding(schema.Items, rules[strings.Index(rules, "dive")+len("dive"):])
+			}
+			return required
+		case "min", "max", "len":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			if schema.Type == "string" {
+				if name != "max" {
+					schema.MinLength = &n
+				}
+				if name != "min" {
+					schema.MaxLength = &n
+				}
+			} else if schema.Type == "integer" || schema.Type == "number" {
+				f := float64(n)
+				if name != "max" {
+					schema.Minimum = &f
+				}
+				if name != "min" {
+					schema.Maximum = &f
+				}
+			}
+		case "oneof":
+			for _, value := range strings.Fields(param) {
+				schema.Enum = append(schema.Enum, value)
+			}
+		case "nowhitespace":
+			schema.Pattern = `^\S*$`
+		}
+	}
+	return required
+}
+
+// exampleOf sets the example of schema from an example tag, typed as the
+// schema, or as its items for arrays
+func exampleOf(schema *Schema, example string) {
+	if schema.Type == "array" && schema.Items != nil {
+		exampleOf(schema.Items, example)
+		return
+	}
+	switch schema.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(example, 10, 64); err == nil {
+			schema.Example = n
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(example, 64); err == nil {
+			schema.Example = f
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(example); err == nil {
+			schema.Example = b
+		}
+	default:
+		schema.Example = example
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaOf(t *testing.T) {
+	type request struct {
+		Name    string   `json:"name" binding:"required,nowhitespace,max=8" example:"Token"`
+		Years   int      `json:"years" binding:"required,min=1" example:"8"`
+		Mode    string   `json:"mode" binding:"oneof=fixed linear"`
+		Actions []Action `json:"actions" binding:"omitempty,dive"`
+		Ignored string   `json:"-"`
+	}
+
+	schema := SchemaOf(request{})
+	assert.Equal(t, []string{"name", "years"}, schema.Required)
+	assert.Equal(t, []string{"actions", "mode", "name", "years"}, sortedKeys(schema.Properties))
+	assert.Equal(t, `^\S*$`, schema.Properties["name"].Pattern)
+	assert.Equal(t, 8, *schema.Properties["name"].MaxLength)
+	assert.Equal(t, int64(8), schema.Properties["years"].Example)
+	assert.Equal(t, []string{"function"}, schema.Properties["actions"].Items.Required)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"name": "Token", "years": 8, "mode": "fixed", "actions": [{"function": "setOracle"}]}`), &body))
+	assert.NoError(t, schema.Validate(body))
+	body["mode"] = "exponential"
+	assert.ErrorContains(t, schema.Validate(body), "$.mode")
+	delete(body, "years")
+	assert.ErrorContains(t, schema.Validate(body), "missing required field years")
+}
+
+// TestSchema_Validate checks each supported keyword against a decoded body
+func TestSchema_Validate(t *testing.T) {
+	closed := false
+	min, max := 2, 4
+	low, high := 1.0, 10.0
+	schema := &Schema{
+		Type:                 "object",
+		Required:             []string{"name"},
+		AdditionalProperties: &closed,
+		Properties: map[string]*Schema{
+			"name":    {Type: "string", MinLength: &min, MaxLength: &max, Pattern: `^[A-Z]+$`},
+			"years":   {Type: "integer", Minimum: &low, Maximum: &high},
+			"ratio":   {Type: "number"},
+			"fixed":   {Type: "boolean"},
+			"mode":    {Enum: []interface{}{"fixed", "linear"}},
+			"holders": {Type: "array", Items: &Schema{Type: "string", Pattern: `^0x`}},
+			"note":    {Type: "null"},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{name: "valid", body: `{"name": "TK", "years": 8, "ratio": 0.5, "fixed": true, "mode": "linear", "holders": ["0x1"], "note": null}`},
+		{name: "large integer", body: `{"name": "TK", "years": 100000000000000000000}`, wantErr: "$.years: value must be at most 10"},
+		{name: "missing required", body: `{}`, wantErr: "$: missing required field name"},
+		{name: "unknown field", body: `{"name": "TK", "owner": "0x1"}`, wantErr: "$: unknown field owner"},
+		{name: "wrong type", body: `{"name": 1}`, wantErr: "$.name: expected string"},
+		{name: "too short", body: `{"name": "T"}`, wantErr: "$.name: length must be at least 2"},
+		{name: "too long", body: `{"name": "TOKEN"}`, wantErr: "$.name: length must be at most 4"},
+		{name: "pattern", body: `{"name": "tk"}`, wantErr: `$.name: value "tk" does not match pattern ^[A-Z]+$`},
+		{name: "fraction", body: `{"name": "TK", "years": 1.5}`, wantErr: "$.years: expected integer"},
+		{name: "below minimum", body: `{"name": "TK", "years": 0}`, wantErr: "$.years: value must be at least 1"},
+		{name: "enum", body: `{"name": "TK", "mode": "step"}`, wantErr: "$.mode: value step is not one of [fixed linear]"},
+		{name: "boolean", body: `{"name": "TK", "fixed": "yes"}`, wantErr: "$.fixed: expected boolean"},
+		{name: "array item", body: `{"name": "TK", "holders": ["0x1", "1x"]}`, wantErr: "$.holders[1]: value \"1x\" does not match pattern ^0x"},
+		{name: "null", body: `{"name": "TK", "note": "x"}`, wantErr: "$.note: expected null"},
+		{name: "not an object", body: `[]`, wantErr: "$: expected object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := json.NewDecoder(strings.NewReader(tt.body))
+			decoder.UseNumber()
+			var body interface{}
+			require.NoError(t, decoder.Decode(&body))
+
+			err := schema.Validate(body)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+
+	assert.ErrorContains(t, (&Schema{Type: "date"}).Validate("x"), "unsupported schema type date")
+	assert.ErrorContains(t, (&Schema{Pattern: "("}).Validate("x"), "invalid pattern")
+}
+
+func sortedKeys(m map[string]*Schema) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
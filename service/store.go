@@ -0,0 +1,260 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// DefaultStorePath is the store file unless paths.store is set
+const DefaultStorePath = "./data/store.json"
+
+var (
+	StorePath = DefaultStorePath
+
+	store *Store
+)
+
+// Store persists service records to a JSON file, and the indexed events to
+// the files of an eventStore next to it
+type Store struct {
+	mu     sync.RWMutex
+	path   string
+	data   storeData
+	events *eventStore
+}
+
+type storeData struct {
+	Deployments []*Deployment  `json:"deployments"`
+	Actions     []ActionResult `json:"actions"`
+	Jobs        []Job          `json:"jobs"`
+	// Events and Checkpoints are the indexed events and indexer checkpoints
+	// of stores written before the events files, moved to them on open
+	Events      []IndexedEvent      `json:"events,omitempty"`
+	Checkpoints []IndexerCheckpoint `json:"checkpoints,omitempty"`
+	// Notifications are the sent webhooks, without their data
+	Notifications []Notification `json:"notifications,omitempty"`
+}
+
+// OpenStore loads the store at path, starting empty if the file does not exist
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read store file: %v", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(content, &s.data); err != nil {
+			return nil, fmt.Errorf("failed to parse store file: %v. path: %v", err, path)
+		}
+	}
+	if s.events, err = openEventStore(path, s.data.Checkpoints); err != nil {
+		return nil, err
+	}
+	if len(s.data.Events) > 0 || len(s.data.Checkpoints) > 0 {
+		if len(s.data.Events) > 0 {
+			if err := s.events.replace(s.data.Events); err != nil {
+				return nil, err
+			}
+		}
+		for _, legacy := range s.data.Checkpoints {
+			checkpoint, _ := s.events.checkpoint(legacy.Network)
+			if err := s.events.setCheckpoint(checkpoint); err != nil {
+				return nil, err
+			}
+		}
+		s.data.Events, s.data.Checkpoints = nil, nil
+		if err := s.save(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// OpenDefaultStore opens the store at StorePath without the rest of Init,
+// for reading records from the CLI local mode
+func OpenDefaultStore() error {
+	var err error
+	store, err = OpenStore(StorePath)
+	return err
+}
+
+// AddDeployment appends a deployment record and persists the store
+func (s *Store) AddDeployment(deployment *Deployment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Deployments = append(s.data.Deployments, deployment)
+	return s.save()
+}
+
+// AddActions appends action results and persists the store
+func (s *Store) AddActions(results []ActionResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Actions = append(s.data.Actions, results...)
+	return s.save()
+}
+
+// Deployments returns the recorded deployments accepted by match, oldest first
+func (s *Store) Deployments(match func(*Deployment) bool) []*Deployment {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	deployments := []*Deployment{}
+	for _, deployment := range s.data.Deployments {
+		if match(deployment) {
+			deployments = append(deployments, deployment)
+		}
+	}
+	return deployments
+}
+
+// Deployment returns the deployment with id
+func (s *Store) Deployment(id string) (*Deployment, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, deployment := range s.data.Deployments {
+		if deployment.ID == id {
+			return deployment, true
+		}
+	}
+	return nil, false
+}
+
+// UpdateDeployment applies update to the deployment with id and persists the
+// store
+func (s *Store) UpdateDeployment(id string, update func(*Deployment)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, deployment := range s.data.Deployments {
+		if deployment.ID == id {
+			update(deployment)
+			return s.save()
+		}
+	}
+	return fmt.Errorf("deployment not found: %s", id)
+}
+
+// SaveJob inserts or replaces the record of job and persists the store
+func (s *Store) SaveJob(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.data.Jobs {
+		if s.data.Jobs[i].ID == job.ID {
+			s.data.Jobs[i] = job
+			return s.save()
+		}
+	}
+	s.data.Jobs = append(s.data.Jobs, job)
+	return s.save()
+}
+
+// Jobs returns copies of the recorded jobs, oldest first
+func (s *Store) Jobs() []*Job {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	jobs := make([]*Job, 0, len(s.data.Jobs))
+	for _, job := range s.data.Jobs {
+		job := job
+		jobs = append(jobs, &job)
+	}
+	return jobs
+}
+
+// Job returns a copy of the job with id
+func (s *Store) Job(id string) (*Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, job := range s.data.Jobs {
+		if job.ID == id {
+			job := job
+			return &job, true
+		}
+	}
+	return nil, false
+}
+
+// Actions returns the recorded actions executed against contract
+func (s *Store) Actions(contract string) []ActionResult {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var results []ActionResult
+	for _, result := range s.data.Actions {
+		if strings.EqualFold(result.Contract, contract) {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// Events returns the indexed events accepted by match, by contract in
+// indexing order
+func (s *Store) Events(match func(*IndexedEvent) bool) []IndexedEvent {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.events.match(match)
+}
+
+// Checkpoint returns the indexer checkpoint of network
+func (s *Store) Checkpoint(network string) (IndexerCheckpoint, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.events.checkpoint(network)
+}
+
+// SaveIndexed appends events indexed up to checkpoint and persists both in
+// the events dir, without rewriting the store file, after dropping the events of the network above rollback, when set, left by
+// a reorg
+func (s *Store) SaveIndexed(checkpoint IndexerCheckpoint, events []IndexedEvent, rollback *uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// The events are written before the checkpoint covering them
+	if rollback != nil {
+		if err := s.events.rollback(checkpoint.Network, *rollback); err != nil {
+			return err
+		}
+	}
+	if err := s.events.append(events); err != nil {
+		return err
+	}
+	return s.events.setCheckpoint(checkpoint)
+}
+
+// Notified reports whether event was sent for contract on network
+func (s *Store) Notified(event, network, contract string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, n := range s.data.Notifications {
+		if n.Event == event && n.Network == network && strings.EqualFold(n.Contract, contract) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddNotification records a sent notification
+func (s *Store) AddNotification(n Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n.Data = nil
+	s.data.Notifications = append(s.data.Notifications, n)
+	return s.save()
+}
+
+// save writes the store atomically. The caller must hold s.mu.
+func (s *Store) save() error {
+	content, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode store: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create store dir: %v", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, content, 0644); err != nil {
+		return fmt.Errorf("failed to write store file: %v", err)
+	}
+	return os.Rename(tmp, s.path)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	otherContract = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	legacyStore   = `{"deployments": [], "actions": [], "jobs": [],
+	"events": [{"network": "dbc-mainnet", "contract": "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707", "event": "Transfer", "block_number": 3}],
+	"checkpoints": [{"network": "dbc-mainnet", "contracts": {"0x5fc8d32690cc91d4c39d9d3abcbd16989f875707": 3}, "heads": []}]}`
+)
+
+func eventBlocks(events []IndexedEvent) []uint64 {
+	blocks := []uint64{}
+	for _, event := range events {
+		blocks = append(blocks, event.BlockNumber)
+	}
+	return blocks
+}
+
+// TestStore_Events checks the indexed events are appended to a file per
+// contract and the checkpoints written next to them instead of the store
+// file, and the events reloaded up to the checkpoints
+func TestStore_Events(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	s, err := OpenStore(path)
+	require.NoError(t, err)
+	all := func(*IndexedEvent) bool { return true }
+
+	checkpoint := IndexerCheckpoint{Network: DefaultNetwork, Contracts: map[string]uint64{"0x5fc8d32690cc91d4c39d9d3abcbd16989f875707": 10, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed": 10}}
+	require.NoError(t, s.SaveIndexed(checkpoint, []IndexedEvent{
+		{Network: DefaultNetwork, Contract: testContract, Event: "Transfer", BlockNumber: 5},
+		{Network: DefaultNetwork, Contract: otherContract, Event: "Transfer", BlockNumber: 6},
+		{Network: DefaultNetwork, Contract: testContract, Event: "Transfer", BlockNumber: 9},
+	}, nil))
+	assert.NoFileExists(t, path)
+	assert.FileExists(t, filepath.Join(filepath.Dir(path), "events", DefaultNetwork, "checkpoint.json"))
+	file := filepath.Join(filepath.Dir(path), "events", DefaultNetwork, "0x5fc8d32690cc91d4c39d9d3abcbd16989f875707.jsonl")
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(content), "\n"))
+
+	// Events past the checkpoint and a line cut by a crash are dropped
+	f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"network": "dbc-mainnet", "contract": "` + testContract + `", "block_number": 11}` + "\n" + `{"network": "dbc-`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	s, err = OpenStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{6, 5, 9}, eventBlocks(s.Events(all)))
+	content, err = os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(content), "\n"))
+
+	// A reorg drops the events above the fork from the files
+	fork := uint64(7)
+	rollback(&checkpoint, fork)
+	require.NoError(t, s.SaveIndexed(checkpoint, nil, &fork))
+	assert.Equal(t, []uint64{6, 5}, eventBlocks(s.Events(all)))
+	s, err = OpenStore(path)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{6, 5}, eventBlocks(s.Events(all)))
+	saved, ok := s.Checkpoint(DefaultNetwork)
+	require.True(t, ok)
+	assert.Equal(t, checkpoint, saved)
+}
+
+// TestStore_MigratesEvents checks the events and checkpoints of a store
+// written before the events files are moved to them
+func TestStore_MigratesEvents(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	require.NoError(t, os.WriteFile(path, []byte(legacyStore), 0644))
+
+	s, err := OpenStore(path)
+	require.NoError(t, err)
+	assert.Len(t, s.Events(func(*IndexedEvent) bool { return true }), 1)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), `"events"`)
+	assert.NotContains(t, string(content), `"checkpoints"`)
+
+	s, err = OpenStore(path)
+	require.NoError(t, err)
+	assert.Len(t, s.Events(func(*IndexedEvent) bool { return true }), 1)
+	checkpoint, ok := s.Checkpoint(DefaultNetwork)
+	require.True(t, ok)
+	assert.Equal(t, uint64(3), checkpoint.Contracts["0x5fc8d32690cc91d4c39d9d3abcbd16989f875707"])
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Toolchain describes the binaries used to build and deploy contracts and
+// their probed versions
+type Toolchain struct {
+	// Runner is host, or the container runtime running the toolchain image
+	Runner       string `json:"runner"`
+	Image        string `json:"image,omitempty"`
+	Forge        string `json:"forge"`
+	Cast         string `json:"cast"`
+	Make         string `json:"make"`
+	Node         string `json:"node"`
+	Solc         string `json:"solc"`
+	ForgeVersion string `json:"forge_version"`
+	CastVersion  string `json:"cast_version"`
+	MakeVersion  string `json:"make_version"`
+	NodeVersion  string `json:"node_version"`
+	SolcVersion  string `json:"solc_version"`
+}
+
+var (
+	toolchain *Toolchain
+
+	solcVersionPattern = regexp.MustCompile(`(?m)^\s*solc_version\s*=\s*"([^"]+)"`)
+
+	// userHomeDir locates the foundry, nvm and svm install dirs, replaced in
+	// tests
+	userHomeDir = os.UserHomeDir
+)
+
+// CurrentToolchain returns the toolchain discovered at startup
+func CurrentToolchain() *Toolchain {
+	return toolchain
+}
+
+// DiscoverToolchain locates forge, cast, make, node and the solc version
+// pinned by foundry.toml under contractPath. Binaries are taken from the
+// toolchain config when set, then from PATH and the default foundry and nvm
+// install dirs. The config optionally pins the forge and node versions.
+func DiscoverToolchain(contractPath string) (*Toolchain, error) {
+	var problems []string
+	tc := &Toolchain{Runner: "host"}
+
+	home, _ := userHomeDir()
+	foundryDirs := []string{filepath.Join(home, ".foundry", "bin")}
+	nodeDirs := nvmBinDirs(home)
+
+	find := func(name, configured string, dirs []string, versionArgs ...string) (string, string) {
+		bin, err := lookupBinary(name, configured, dirs)
+		if err != nil {
+			problems = append(problems, err.Error())
+			return "", ""
+		}
+		version, err := probeVersion(bin, versionArgs...)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+		return bin, version
+	}
+	tc.Forge, tc.ForgeVersion = find("forge", CurrentConfig().Toolchain.Forge, foundryDirs, "--version")
+	tc.Cast, tc.CastVersion = find("cast", CurrentConfig().Toolchain.Cast, foundryDirs, "--version")
+	tc.Make, tc.MakeVersion = find("make", CurrentConfig().Toolchain.Make, nil, "--version")
+	tc.Node, tc.NodeVersion = find("node", CurrentConfig().Toolchain.Node, nodeDirs, "--version")
+
+	if err := checkPinnedVersion("forge", tc.ForgeVersion, CurrentConfig().Toolchain.ForgeVersion); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := checkPinnedVersion("node", tc.NodeVersion, CurrentConfig().Toolchain.NodeVersion); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	solcVersion, err := foundrySolcVersion(contractPath)
+	if err != nil {
+		problems = append(problems, err.Error())
+	} else {
+		tc.Solc, tc.SolcVersion, err = findSolc(home, solcVersion)
+		if err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	if len(problems) > 0 {
+		return tc, fmt.Errorf("toolchain check failed:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return tc, nil
+}
+
+// toolchainEnv returns the process environment with the discovered toolchain
+// dirs prioritised on PATH to avoid version conflicts
+func toolchainEnv() []string {
+	env := os.Environ()
+	if toolchain == nil {
+		return env
+	}
+
+	var dirs []string
+	seen := map[string]bool{}
+	for _, bin := range []string{toolchain.Node, toolchain.Forge, toolchain.Cast, toolchain.Make} {
+		dir := filepath.Dir(bin)
+		if !filepath.IsAbs(bin) || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
+	}
+	dirs = append(dirs, os.Getenv("PATH"))
+	return append(env, "PATH="+strings.Join(dirs, string(os.PathListSeparator)))
+}
+
+// toolPath returns the discovered binary for name, falling back to a PATH
+// lookup when the toolchain has not been discovered
+func toolPath(name string) string {
+	if toolchain == nil {
+		return name
+	}
+	bin := map[string]string{
+		"forge": toolchain.Forge,
+		"cast":  toolchain.Cast,
+		"make":  toolchain.Make,
+		"node":  toolchain.Node,
+	}[name]
+	if bin == "" {
+		return name
+	}
+	return bin
+}
+
+// lookupBinary returns configured, or locates name in PATH and dirs
+func lookupBinary(name, configured string, dirs []string) (string, error) {
+	if configured != "" {
+		if _, err := os.Stat(configured); err != nil {
+			return "", fmt.Errorf("%s configured at %s not found", name, configured)
+		}
+		return configured, nil
+	}
+	if bin, err := exec.LookPath(name); err == nil {
+		return filepath.Abs(bin)
+	}
+	for _, dir := range dirs {
+		bin := filepath.Join(dir, name)
+		if info, err := os.Stat(bin); err == nil && !info.IsDir() {
+			return bin, nil
+		}
+	}
+	return "", fmt.Errorf("%s not found in PATH or %v, set toolchain.%s", name, dirs, name)
+}
+
+func probeVersion(bin string, args ...string) (string, error) {
+	output, err := exec.Command(bin, args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("failed to probe %s version: %v: %s", bin, err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(strings.SplitN(string(output), "\n", 2)[0]), nil
+}
+
+func checkPinnedVersion(name, detected, pinned string) error {
+	if pinned == "" || detected == "" {
+		return nil
+	}
+	if !strings.Contains(detected, pinned) {
+		return fmt.Errorf("%s version %q does not match pinned version %s", name, detected, pinned)
+	}
+	return nil
+}
+
+// foundrySolcVersion reads solc_version from the foundry.toml of contractPath
+func foundrySolcVersion(contractPath string) (string, error) {
+	content, err := os.ReadFile(filepath.Join(contractPath, "foundry.toml"))
+	if err != nil {
+		return "", fmt.Errorf("failed to read foundry.toml: %v", err)
+	}
+	match := solcVersionPattern.FindSubmatch(content)
+	if match == nil {
+		return "", fmt.Errorf("solc_version not set in foundry.toml")
+	}
+	return string(match[1]), nil
+}
+
+// findSolc locates the solc binary for version, either from the toolchain
+// config or from the svm install dirs used by forge
+func findSolc(home, version string) (string, string, error) {
+	var bin string
+	if configured := CurrentConfig().Toolchain.Solc; configured != "" {
+		if _, err := os.Stat(configured); err != nil {
+			return "", "", fmt.Errorf("solc configured at %s not found", configured)
+		}
+		bin = configured
+	} else {
+		dirs := svmDirs(home)
+		for _, dir := range dirs {
+			candidate := filepath.Join(dir, version, "solc-"+version)
+			if _, err := os.Stat(candidate); err == nil {
+				bin = candidate
+				break
+			}
+		}
+		if bin == "" {
+			return "", "", fmt.Errorf("solc %s required by foundry.toml not found in %v, install it with `svm install %s` or set toolchain.solc", version, dirs, version)
+		}
+	}
+	output, err := exec.Command(bin, "--version").CombinedOutput()
+	if err != nil {
+		return bin, "", fmt.Errorf("failed to probe solc version: %v", err)
+	}
+	if !strings.Contains(string(output), "Version: "+version) {
+		return bin, "", fmt.Errorf("solc at %s is not version %s required by foundry.toml", bin, version)
+	}
+	return bin, version, nil
+}
+
+// svmDirs returns the dirs svm installs solc versions into: ~/.svm, or the
+// XDG data dir used by newer releases
+func svmDirs(home string) []string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return []string{filepath.Join(home, ".svm"), filepath.Join(dataHome, "svm")}
+}
+
+// nvmBinDirs returns the bin dirs of the node versions installed with nvm,
+// newest first
+func nvmBinDirs(home string) []string {
+	if dir := os.Getenv("NVM_BIN"); dir != "" {
+		return []string{dir}
+	}
+	versions, _ := filepath.Glob(filepath.Join(home, ".nvm", "versions", "node", "v*"))
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersions(filepath.Base(versions[i]), filepath.Base(versions[j])) > 0
+	})
+	dirs := make([]string, 0, len(versions))
+	for _, version := range versions {
+		dirs = append(dirs, filepath.Join(version, "bin"))
+	}
+	return dirs
+}
+
+// compareVersions compares dotted versions such as "v23.9.0"
+func compareVersions(a, b string) int {
+	pa := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	pb := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(pa) && i < len(pb); i++ {
+		var na, nb int
+		fmt.Sscanf(pa[i], "%d", &na)
+		fmt.Sscanf(pb[i], "%d", &nb)
+		if na != nb {
+			return na - nb
+		}
+	}
+	return len(pa) - len(pb)
+}
+
+func logToolchain(tc *Toolchain) {
+	slog.Info("toolchain", "runner", tc.Runner, "image", tc.Image,
+		"forge", tc.Forge, "forge_version", tc.ForgeVersion,
+		"cast", tc.Cast, "cast_version", tc.CastVersion,
+		"make", tc.Make, "make_version", tc.MakeVersion,
+		"node", tc.Node, "node_version", tc.NodeVersion,
+		"solc", tc.Solc, "solc_version", tc.SolcVersion)
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBinary writes an executable at path printing output
+func fakeBinary(t *testing.T, path, output string) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\necho '"+output+"'\n"), 0755))
+	return path
+}
+
+// TestDiscoverToolchain checks the pinned solc is required from the svm
+// install dirs, with the other binaries taken from the config
+func TestDiscoverToolchain(t *testing.T) {
+	setupTest(t, &FakeExecutor{})
+	home := t.TempDir()
+	originalHome := userHomeDir
+	userHomeDir = func() (string, error) { return home, nil }
+	t.Cleanup(func() { userHomeDir = originalHome })
+	t.Setenv("XDG_DATA_HOME", "")
+
+	bin := t.TempDir()
+	activeConfig.Toolchain.Forge = fakeBinary(t, filepath.Join(bin, "forge"), "forge 1.0.0")
+	activeConfig.Toolchain.Cast = fakeBinary(t, filepath.Join(bin, "cast"), "cast 1.0.0")
+	activeConfig.Toolchain.Make = fakeBinary(t, filepath.Join(bin, "make"), "GNU Make 4.3")
+	activeConfig.Toolchain.Node = fakeBinary(t, filepath.Join(bin, "node"), "v22.0.0")
+
+	t.Run("missing solc", func(t *testing.T) {
+		tc, err := DiscoverToolchain(contractsPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "solc 0.8.26 required by foundry.toml not found")
+		assert.Equal(t, "forge 1.0.0", tc.ForgeVersion)
+		assert.Empty(t, tc.Solc)
+	})
+
+	t.Run("solc in svm dir", func(t *testing.T) {
+		solc := fakeBinary(t, filepath.Join(home, ".svm", "0.8.26", "solc-0.8.26"), "Version: 0.8.26+commit.8a97fa7a")
+		t.Cleanup(func() { os.RemoveAll(filepath.Join(home, ".svm")) })
+
+		tc, err := DiscoverToolchain(contractsPath)
+		require.NoError(t, err)
+		assert.Equal(t, solc, tc.Solc)
+		assert.Equal(t, "0.8.26", tc.SolcVersion)
+		assert.Equal(t, "v22.0.0", tc.NodeVersion)
+	})
+
+	t.Run("solc in XDG data dir", func(t *testing.T) {
+		solc := fakeBinary(t, filepath.Join(home, ".local", "share", "svm", "0.8.26", "solc-0.8.26"), "Version: 0.8.26+commit.8a97fa7a")
+
+		tc, err := DiscoverToolchain(contractsPath)
+		require.NoError(t, err)
+		assert.Equal(t, solc, tc.Solc)
+	})
+
+	t.Run("wrong solc version", func(t *testing.T) {
+		activeConfig.Toolchain.Solc = fakeBinary(t, filepath.Join(bin, "solc"), "Version: 0.8.20+commit.a1b79de6")
+		t.Cleanup(func() { activeConfig.Toolchain.Solc = "" })
+
+		_, err := DiscoverToolchain(contractsPath)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not version 0.8.26")
+	})
+}
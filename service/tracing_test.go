@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	collector "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// TestSetupTracing exports the spans of a deployment to an in-process OTLP
+// collector and checks every stage is traced under the deployment span
+func TestSetupTracing(t *testing.T) {
+	var mu sync.Mutex
+	spans := map[string][]byte{}
+	var deploySpanID []byte
+	collectorServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var req collector.ExportTraceServiceRequest
+		require.NoError(t, proto.Unmarshal(body, &req))
+
+		mu.Lock()
+		defer mu.Unlock()
+		for _, resourceSpans := range req.ResourceSpans {
+			for _, scopeSpans := range resourceSpans.ScopeSpans {
+				for _, span := range scopeSpans.Spans {
+					spans[span.Name] = span.ParentSpanId
+					if span.Name == "DeployContract" {
+						deploySpanID = span.SpanId
+					}
+				}
+			}
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		_, _ = w.Write(nil)
+	}))
+	defer collectorServer.Close()
+
+	fake := &FakeExecutor{Responses: []FakeResponse{
+		{Name: "forge", Args: []string{"script"}, Output: readFixture(t, "forge/success.txt")},
+	}}
+	setupTest(t, fake)
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", collectorServer.URL)
+
+	originalProvider := otel.GetTracerProvider()
+	shutdown, err := SetupTracing(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { otel.SetTracerProvider(originalProvider) })
+
+	spec, err := LookupContractType("token")
+	require.NoError(t, err)
+	network, err := LookupNetwork(DefaultNetwork)
+	require.NoError(t, err)
+	_, err = DeployContract(context.Background(), contractsPath, copyEnv(tokenEnvVars), spec, network)
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.NotNil(t, deploySpanID)
+	for _, name := range []string{SpanWriteEnv, StageCompile, StageBroadcast, StageVerify, SpanParse, SpanCleanup} {
+		if assert.Contains(t, spans, name) {
+			assert.Equal(t, deploySpanID, spans[name], name)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package service
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrSignerScope is returned for transactions outside the scope of their
+// signer
+var ErrSignerScope = errors.New("transaction outside the signer scope")
+
+// Transaction is the call sent by a transact job
+type Transaction struct {
+	Artifact string `json:"artifact"`
+	// Function is the signature of the called function
+	Function string   `json:"function"`
+	Args     []string `json:"args"`
+	// Data is the encoded call
+	Data   string `json:"data"`
+	Signer string `json:"signer"`
+}
+
+// ContractEvent is a log of a transaction, decoded with the ABI of the
+// contract when the contract emitted it
+type ContractEvent struct {
+	Address string `json:"address"`
+	// Event is the signature of the decoded event
+	Event string     `json:"event,omitempty"`
+	Args  []ABIValue `json:"args,omitempty"`
+	// Topics and Data are kept for the logs that could not be decoded
+	Topics   []string `json:"topics,omitempty"`
+	Data     string   `json:"data,omitempty"`
+	LogIndex int64    `json:"log_index"`
+}
+
+// PrepareTransaction checks a state-changing call of a deployed contract
+// against its ABI and the scope of signer, and returns the transact job
+// sending it. Only contracts recorded as deployed can be called. A call
+// outside the scope of the signer is recorded as denied.
+func PrepareTransaction(ctx context.Context, call ContractCall, signer string) (*Job, error) {
+	deployment := findDeployment(call.Address, call.Network, call.ContractType)
+	if deployment == nil {
+		return nil, fmt.Errorf("%w: no deployment recorded at %s", ErrInvalidCall, call.Address)
+	}
+	artifact, _, err := resolveArtifact(call)
+	if err != nil {
+		return nil, err
+	}
+	network, err := LookupNetwork(deployment.Network)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCall, err)
+	}
+
+	function, err := loadFunction(artifact, call.Function)
+	if err != nil {
+		return nil, err
+	}
+	if function.ReadOnly() {
+		return nil, fmt.Errorf("%w: %s is a view function, call it instead", ErrInvalidCall, function.Signature())
+	}
+	data, err := function.EncodeCall(call.Args)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCall, err)
+	}
+	if signer == "" {
+		signer = DeployerSigner
+	}
+	if _, _, err := network.Signer(signer); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCall, err)
+	}
+
+	tx := &Transaction{
+		Artifact: artifact,
+		Function: function.Signature(),
+		Args:     make([]string, len(call.Args)),
+		Data:     "0x" + hex.EncodeToString(data),
+		Signer:   strings.ToLower(signer),
+	}
+	for i, arg := range call.Args {
+		if tx.Args[i], err = envValue(arg); err != nil {
+			return nil, fmt.Errorf("%w: invalid arg %d: %v", ErrInvalidCall, i, err)
+		}
+	}
+	job := &Job{
+		Kind:         JobTransact,
+		ContractType: deployment.ContractType,
+		Network:      network.Name,
+		Contract:     deployment.ProxyAddress,
+		Transaction:  tx,
+	}
+	if err := checkScope(network, job); err != nil {
+		result := transactionResult(ctx, job)
+		result.Status = ActionDenied
+		result.Error = err.Error()
+		auditTransaction(ctx, result)
+		return nil, err
+	}
+	return job, nil
+}
+
+// runTransaction simulates the transaction of a transact job with eth_call
+// from its signer, sends it and decodes the events of its receipt. The
+// outcome is recorded in the audit log.
+func runTransaction(ctx context.Context, job *Job, network *Network) (ActionResult, error) {
+	result := transactionResult(ctx, job)
+	err := sendContractTransaction(ctx, job, network, &result)
+	result.Status = ActionSuccess
+	if errors.Is(err, ErrSignerScope) {
+		result.Status = ActionDenied
+	} else if err != nil {
+		result.Status = ActionFailed
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	auditTransaction(ctx, result)
+	return result, err
+}
+
+func sendContractTransaction(ctx context.Context, job *Job, network *Network, result *ActionResult) error {
+	if job.Transaction == nil {
+		return fmt.Errorf("transact job without transaction")
+	}
+	tx := job.Transaction
+	// The config may have changed since the job was queued
+	if err := checkScope(network, job); err != nil {
+		return err
+	}
+	key, _, err := network.Signer(tx.Signer)
+	if err != nil {
+		return err
+	}
+	if key == "" {
+		return fmt.Errorf("signer %s key not loaded", tx.Signer)
+	}
+	from, err := cachedDeployerAddress(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	Logger(ctx).Info("simulating transaction", "contract", job.Contract, "function", tx.Function, "from", from)
+	call := map[string]string{"from": from, "to": job.Contract, "data": tx.Data}
+	if err := rpcCall(network.RPCURL, "eth_call", nil, call, "latest"); err != nil {
+		if strings.Contains(err.Error(), "revert") {
+			return fmt.Errorf("%w: simulation failed: %v", ErrCallReverted, err)
+		}
+		return fmt.Errorf("simulation failed: %v", err)
+	}
+
+	receipt, err := castSend(ctx, network, key, job.Contract, []string{tx.Data})
+	if err != nil {
+		return err
+	}
+	result.TxHash = receipt.TransactionHash
+	if n, err := parseHexInt(receipt.BlockNumber); err == nil {
+		result.BlockNumber = uint64(n)
+	}
+	if n, err := parseHexInt(receipt.GasUsed); err == nil {
+		result.GasUsed = uint64(n)
+	}
+	result.Events = decodeEvents(ctx, tx.Artifact, job.Contract, receipt.Logs)
+	return receipt.err()
+}
+
+// checkScope checks the transaction of job against the scope of its signer
+func checkScope(network *Network, job *Job) error {
+	_, scope, err := network.Signer(job.Transaction.Signer)
+	if err != nil {
+		return err
+	}
+	if !scope.Allows(network.Name, job.ContractType, job.Transaction.Function) {
+		return fmt.Errorf("%w: %s may not call %s on %s contracts on %s", ErrSignerScope,
+			job.Transaction.Signer, job.Transaction.Function, job.ContractType, network.Name)
+	}
+	return nil
+}
+
+func transactionResult(ctx context.Context, job *Job) ActionResult {
+	return ActionResult{
+		Contract:     job.Contract,
+		ContractType: job.ContractType,
+		Network:      job.Network,
+		Function:     job.Transaction.Function,
+		Args:         job.Transaction.Args,
+		Caller:       callerFrom(ctx),
+		Signer:       job.Transaction.Signer,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// auditTransaction records the outcome of a transact request
+func auditTransaction(ctx context.Context, result ActionResult) {
+	Logger(ctx).Info("contract transaction",
+		"contract", result.Contract,
+		"network", result.Network,
+		"function", result.Function,
+		"caller", result.Caller,
+		"signer", result.Signer,
+		"status", result.Status,
+		"tx_hash", result.TxHash,
+	)
+	recordActions(ctx, result.Contract, []ActionResult{result})
+}
+
+// decodeEvents decodes the logs contract emitted with the ABI of artifact,
+// keeping the other logs as they are
+func decodeEvents(ctx context.Context, artifact, contract string, logs []rpcLog) []ContractEvent {
+	abi, err := LoadABI(artifact)
+	if err != nil {
+		Logger(ctx).Warn("failed to load ABI to decode events", "artifact", artifact, "error", err)
+	}
+	events := make([]ContractEvent, 0, len(logs))
+	for _, log := range logs {
+		event := ContractEvent{Address: log.Address}
+		event.LogIndex, _ = parseHexInt(log.LogIndex)
+		if entry := abi.Event(firstTopic(log.Topics)); entry != nil && strings.EqualFold(log.Address, contract) {
+			data, err := hex.DecodeString(strings.TrimPrefix(log.Data, "0x"))
+			if err == nil {
+				event.Args, err = entry.DecodeLog(log.Topics, data)
+			}
+			if err == nil {
+				event.Event = entry.Signature()
+				events = append(events, event)
+				continue
+			}
+			Logger(ctx).Warn("failed to decode event", "event", entry.Signature(), "error", err)
+		}
+		event.Topics, event.Data = log.Topics, log.Data
+		events = append(events, event)
+	}
+	return events
+}
+
+func firstTopic(topics []string) string {
+	if len(topics) == 0 {
+		return ""
+	}
+	return topics[0]
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testContract = "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707"
+
+// TestTransact sends a transaction through a transact job and checks the
+// scope, the simulation and the audit log
+func TestTransact(t *testing.T) {
+	transferLog := `{"address": "` + testContract + `", "logIndex": "0x0", "data": "0x00000000000000000000000000000000000000000000000000000000000003e8", "topics": [
+		"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+		"0x000000000000000000000000f39fd6e51aad88f6f4ce6ab8827279cfffb92266",
+		"0x0000000000000000000000005aaeb6053f3e94c9b9a09f33669435e7ef1beaed"]}`
+	fake := &FakeExecutor{Responses: []FakeResponse{
+		{Name: "cast", Args: []string{"wallet", "address"}, Output: []byte(testDeployer + "\n")},
+		{Name: "cast", Args: []string{"send", testContract}, Output: []byte(`{"transactionHash": "0xabc", "status": "0x1", "blockNumber": "0x10", "gasUsed": "0x5208", "logs": [` + transferLog + `]}`)},
+	}}
+	setupTest(t, fake)
+	node := newFakeNode(t)
+	setupJobs(t, node)
+	require.NoError(t, StartJobs())
+
+	activeConfig.Signer.Keys = []SignerKeyConfig{{
+		Name:       "ops",
+		PrivateKey: "0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d",
+		Scope:      SignerScope{ContractTypes: []string{"token"}, Functions: []string{"approve"}},
+	}}
+	require.NoError(t, store.AddDeployment(&Deployment{
+		ID:           "dep",
+		ContractType: "token",
+		Network:      DefaultNetwork,
+		ProxyAddress: testContract,
+		ABI:          "out/Token.sol/Token.json",
+		Status:       DeploymentSuccess,
+	}))
+	buildDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(buildDir, "out", "Token.sol"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(buildDir, "out", "Token.sol", "Token.json"), []byte(`{"abi": `+testABI+`}`), 0644))
+	originalBuild := builds.current
+	builds.current = &BuildInfo{Key: "test", Status: BuildReady, dir: buildDir}
+	t.Cleanup(func() { builds.current = originalBuild })
+
+	ctx := WithCaller(context.Background(), "admin")
+	call := ContractCall{
+		Address:  testContract,
+		Function: "transfer",
+		Args:     []interface{}{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", json.Number("1000")},
+	}
+
+	_, err := PrepareTransaction(ctx, call, "ops")
+	assert.ErrorIs(t, err, ErrSignerScope)
+	// The deployer scope lists no functions by default
+	_, err = PrepareTransaction(ctx, call, "")
+	assert.ErrorIs(t, err, ErrSignerScope)
+	activeConfig.Signer.Scope = SignerScope{Networks: []string{DefaultNetwork}, Functions: []string{"transfer(address,uint256)"}}
+	_, err = PrepareTransaction(ctx, ContractCall{Address: testContract, Function: "getLockInfos", Args: call.Args[:1]}, "")
+	assert.ErrorContains(t, err, "is a view function")
+	_, err = PrepareTransaction(ctx, ContractCall{Address: "0x0000000000000000000000000000000000000001", Function: "transfer"}, "")
+	assert.ErrorContains(t, err, "no deployment recorded")
+
+	run := func() *Job {
+		job, err := PrepareTransaction(ctx, call, "")
+		require.NoError(t, err)
+		job, err = SubmitJob(ctx, job)
+		require.NoError(t, err)
+		require.NoError(t, WaitJob(context.Background(), job))
+		return job
+	}
+
+	setCallError := func(message string) {
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		node.callError = message
+	}
+	setCallError("execution reverted: not owner")
+	job := run()
+	assert.ErrorIs(t, job.Err(), ErrCallReverted)
+	for _, cmd := range fake.Calls() {
+		assert.NotEqual(t, "send", cmd.Args[0], "reverted simulation must not send")
+	}
+
+	setCallError("")
+	job = run()
+	require.NoError(t, job.Err())
+	require.Len(t, job.ActionResults, 1)
+	result := job.ActionResults[0]
+	assert.Equal(t, "transfer(address,uint256)", result.Function)
+	assert.Equal(t, []string{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "1000"}, result.Args)
+	assert.Equal(t, "0xabc", result.TxHash)
+	assert.Equal(t, uint64(16), result.BlockNumber)
+	assert.Equal(t, uint64(21000), result.GasUsed)
+	require.Len(t, result.Events, 1)
+	assert.Equal(t, "Transfer(address,address,uint256)", result.Events[0].Event)
+	assert.Equal(t, []ABIValue{
+		{Name: "from", Type: "address", Value: testDeployer},
+		{Name: "to", Type: "address", Value: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"},
+		{Name: "value", Type: "uint256", Value: "1000"},
+	}, result.Events[0].Args)
+
+	audit := store.Actions(testContract)
+	require.Len(t, audit, 4)
+	assert.Equal(t, []string{ActionDenied, ActionDenied, ActionFailed, ActionSuccess}, []string{audit[0].Status, audit[1].Status, audit[2].Status, audit[3].Status})
+	assert.Equal(t, "admin", audit[0].Caller)
+	assert.Equal(t, "ops", audit[0].Signer)
+	assert.Equal(t, DeployerSigner, audit[1].Signer)
+	assert.Equal(t, DeployerSigner, audit[3].Signer)
+}
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// upgradeSignature is the UUPS upgrade entry point of every deployed proxy
+const upgradeSignature = "upgradeToAndCall(address,bytes)"
+
+// ownerFunction reads the owner of a deployed proxy, every contract type
+// being Ownable
+var ownerFunction = &ABIEntry{
+	Type:            "function",
+	Name:            "owner",
+	Outputs:         []ABIParam{{Type: "address"}},
+	StateMutability: "view",
+}
+
+// VerifyDeployment submits the implementation of deployment to the verifier
+// of network again, for deployments whose verification failed
+func VerifyDeployment(ctx context.Context, deployment *Deployment, network *Network) error {
+	if network.VerifierURL == "" {
+		return fmt.Errorf("network %s has no verifier", network.Name)
+	}
+	if deployment.ImplementationAddress == "" || deployment.ContractName == "" {
+		return fmt.Errorf("implementation of deployment %s unknown", deployment.ID)
+	}
+	defer trackInFlight("verify")()
+
+	ws, err := newArtifactWorkspace(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ws.Cleanup(err != nil)
+	}()
+
+	forgeLog := newLineLogger(ctx, "forge")
+	cmd := Command{
+		Name: toolPath("forge"),
+		Args: []string{
+			"verify-contract", deployment.ImplementationAddress, deployment.ContractName,
+			"--chain", strconv.FormatInt(network.ChainID, 10),
+			"--verifier", "blockscout",
+			"--verifier-url", network.VerifierURL,
+			"--watch",
+		},
+		Dir:    ws.Dir,
+		Env:    toolchainEnv(),
+		Output: forgeLog,
+	}
+	Logger(ctx).Info("executing command", "command", cmd.String())
+	start := time.Now()
+	output, err := executor.Run(ctx, cmd)
+	forgeLog.Flush()
+	observeStage(StageVerify, start)
+	if err != nil {
+		return fmt.Errorf("%w: %v: %s", ErrVerifyFailed, err, lastLines(string(output), 20))
+	}
+	return nil
+}
+
+// UpgradeDeployment deploys the current source of the deployment's contract
+// as a new implementation and points the proxy at it, through the upgrade
+// script of the contract type calling Upgrades.upgradeProxy, which validates
+// the storage layout first. It returns the new implementation address and
+// the upgrade transaction hash.
+func UpgradeDeployment(ctx context.Context, deployment *Deployment, network *Network) (implementation, txHash string, err error) {
+	if deployment.ProxyAddress == "" {
+		return "", "", fmt.Errorf("proxy of deployment %s unknown", deployment.ID)
+	}
+	spec, err := LookupContractType(deployment.ContractType)
+	if err != nil {
+		return "", "", err
+	}
+	if spec.UpgradeScript == "" {
+		return "", "", fmt.Errorf("contract type %s has no upgrade script", spec.Name)
+	}
+	// The upgrade is onlyOwner, checked before the implementation is paid for
+	if err := checkUpgradeOwner(ctx, deployment, network); err != nil {
+		return "", "", err
+	}
+	defer trackInFlight("upgrade")()
+
+	ws, err := newArtifactWorkspace(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	defer func() {
+		ws.Cleanup(err != nil)
+	}()
+
+	args := []string{
+		"script", spec.UpgradeScript,
+		"--rpc-url", network.RPCURL,
+		"--broadcast",
+	}
+	if network.VerifierURL != "" {
+		args = append(args,
+			"--verify",
+			"--verifier", "blockscout",
+			"--verifier-url", network.VerifierURL,
+		)
+	}
+	args = append(args,
+		"--skip-simulation",
+		"--legacy",
+	)
+	proxyEnv := spec.ProxyEnv
+	if proxyEnv == "" {
+		proxyEnv = "PROXY_CONTRACT"
+	}
+	forgeLog := newLineLogger(ctx, "forge")
+	cmd := Command{
+		Name:   toolPath("forge"),
+		Args:   args,
+		Dir:    ws.Dir,
+		Env:    append(toolchainEnv(), "PRIVATE_KEY="+network.PrivateKey(), proxyEnv+"="+deployment.ProxyAddress),
+		Image:  spec.Image,
+		Output: forgeLog,
+	}
+	Logger(ctx).Info("executing command", "command", cmd.String())
+	output, runErr := executor.Run(ctx, cmd)
+	forgeLog.Flush()
+
+	implementation, txHash, err = readUpgrade(ws.Dir, spec.UpgradeScript, deployment.ProxyAddress)
+	if err != nil && runErr == nil {
+		return "", "", fmt.Errorf("%w: %v", ErrAddressNotFound, err)
+	}
+	if implementation == "" {
+		if runErr != nil {
+			return "", "", fmt.Errorf("%w: %v: %s", ErrScriptFailed, runErr, lastLines(string(output), 20))
+		}
+		return "", "", ErrAddressNotFound
+	}
+
+	result := ActionResult{
+		Contract:     deployment.ProxyAddress,
+		ContractType: deployment.ContractType,
+		Network:      network.Name,
+		Function:     upgradeSignature,
+		Args:         []string{implementation, "0x"},
+		TxHash:       txHash,
+		Status:       ActionSuccess,
+		CreatedAt:    time.Now(),
+	}
+	err = nil
+	if txHash == "" {
+		err = fmt.Errorf("upgrade transaction to %s not mined", deployment.ProxyAddress)
+		if runErr != nil {
+			err = fmt.Errorf("%w: %v: %s", ErrScriptFailed, runErr, lastLines(string(output), 20))
+		}
+		result.Status = ActionFailed
+		result.Error = err.Error()
+	} else if runErr != nil {
+		// Verification of the new implementation can be retried later
+		Logger(ctx).Warn("proxy upgraded, verification failed", "implementation", implementation, "error", runErr)
+	}
+	if store != nil {
+		if err := store.AddActions([]ActionResult{result}); err != nil {
+			Logger(ctx).Error("failed to record upgrade", "error", err)
+		}
+		if result.Status == ActionSuccess {
+			err := store.UpdateDeployment(deployment.ID, func(d *Deployment) {
+				d.ImplementationAddress = implementation
+			})
+			if err != nil {
+				Logger(ctx).Error("failed to record upgrade", "error", err)
+			}
+		}
+	}
+	if err != nil {
+		return implementation, "", fmt.Errorf("failed to upgrade proxy to %s: %w", implementation, err)
+	}
+	Logger(ctx).Info("proxy upgraded", "proxy_address", deployment.ProxyAddress, "implementation", implementation)
+	return implementation, txHash, nil
+}
+
+// checkUpgradeOwner fails unless the signer of network owns the proxy of
+// deployment
+func checkUpgradeOwner(ctx context.Context, deployment *Deployment, network *Network) error {
+	outputs, err := callFunction(ctx, network, deployment.ProxyAddress, ownerFunction, []interface{}{}, "latest")
+	if err != nil {
+		return fmt.Errorf("failed to read owner of %s: %v", deployment.ProxyAddress, err)
+	}
+	owner := fmt.Sprint(outputs[0].Value)
+	signer, err := cachedDeployerAddress(ctx, network.PrivateKey())
+	if err != nil {
+		return fmt.Errorf("failed to derive signer address: %v", err)
+	}
+	if !strings.EqualFold(owner, signer) {
+		return fmt.Errorf("proxy %s is owned by %s, the upgrade would revert for the signer %s", deployment.ProxyAddress, owner, signer)
+	}
+	return nil
+}
+
+// readUpgrade returns the implementation the upgrade script left in dir
+// created, and the hash of its mined call to proxy
+func readUpgrade(dir, script, proxy string) (implementation, txHash string, err error) {
+	run, err := loadBroadcast(dir, script)
+	if err != nil {
+		return "", "", err
+	}
+	var callHash string
+	for _, tx := range run.Transactions {
+		switch {
+		case tx.TransactionType == "CREATE":
+			implementation = tx.ContractAddress
+		case tx.TransactionType == "CALL" && strings.EqualFold(tx.ContractAddress, proxy):
+			callHash = tx.Hash
+		}
+	}
+	for _, receipt := range run.Receipts {
+		if receipt.TransactionHash == callHash && receipt.Status == "0x1" {
+			txHash = callHash
+		}
+	}
+	return implementation, txHash, nil
+}
+
+// newArtifactWorkspace returns a workspace of the contracts tree with the
+// cached build restored, so forge does not recompile
+func newArtifactWorkspace(ctx context.Context) (*Workspace, error) {
+	contractPath := CurrentConfig().Paths.Contracts
+	ws, err := NewWorkspace(ctx, contractPath)
+	if err != nil {
+		return nil, err
+	}
+	if restoreArtifacts(ctx, contractPath, ws) {
+		Logger(ctx).Info("reusing cached build artifacts", "workspace", ws.Dir)
+	}
+	return ws, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Notification is a lifecycle event of a contract posted to the webhook
+type Notification struct {
+	Event    string      `json:"event"`
+	Network  string      `json:"network"`
+	Contract string      `json:"contract"`
+	Data     interface{} `json:"data,omitempty"`
+	SentAt   time.Time   `json:"sent_at"`
+	// Seeded notifications are recorded without being sent, for the events
+	// that happened before the watcher first saw the contract
+	Seeded bool `json:"seeded,omitempty"`
+}
+
+var webhookClient = &http.Client{}
+
+// WebhooksEnabled reports whether a webhook is configured
+func WebhooksEnabled() bool {
+	return CurrentConfig().Webhooks.URL != ""
+}
+
+// notify posts n to the webhook unless it was already sent for the contract.
+// A failed post is retried by the next call.
+func notify(ctx context.Context, n Notification) error {
+	if store == nil || store.Notified(n.Event, n.Network, n.Contract) {
+		return nil
+	}
+	n.SentAt = time.Now()
+	if err := sendWebhook(ctx, n); err != nil {
+		return err
+	}
+	Logger(ctx).Info("webhook sent", "event", n.Event, "network", n.Network, "contract", n.Contract)
+	return store.AddNotification(n)
+}
+
+// sendWebhook posts n as JSON to the configured webhook, signed with the
+// signing key when set
+func sendWebhook(ctx context.Context, n Notification) error {
+	cfg := CurrentConfig().Webhooks
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.Timeout))
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Webhook-Event", n.Event)
+	if cfg.SigningKey != "" {
+		mac := hmac.New(sha256.New, []byte(cfg.SigningKey))
+		mac.Write(body)
+		req.Header.Set("X-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
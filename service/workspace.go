@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	workspacePrefix       = "job-"
+	failedWorkspacePrefix = "failed-"
+)
+
+var (
+	// defaultWorkspaceRoot is the workspace root unless paths.workspaces is set
+	defaultWorkspaceRoot = filepath.Join(os.TempDir(), "auto-deploy-contract")
+
+	// WorkspaceRoot holds the ephemeral per-job copies of the contracts tree
+	WorkspaceRoot = defaultWorkspaceRoot
+	// KeepFailedWorkspaces keeps the workspace of a failed job for debugging
+	KeepFailedWorkspaces = false
+)
+
+// workspaceSkip lists entries of the contracts tree that are never carried
+// into a workspace: shared env files and build outputs of other runs.
+var workspaceSkip = map[string]bool{
+	".env":      true,
+	".git":      true,
+	"out":       true,
+	"cache":     true,
+	"broadcast": true,
+}
+
+// workspaceLinks lists read-only entries shared through a symlink instead of
+// being copied.
+var workspaceLinks = map[string]bool{
+	"lib":          true,
+	"node_modules": true,
+}
+
+// Workspace is an isolated copy of the contracts tree used by a single job
+type Workspace struct {
+	Dir     string
+	EnvPath string
+
+	logger *slog.Logger
+}
+
+// NewWorkspace creates a workspace from the contracts tree at contractPath
+func NewWorkspace(ctx context.Context, contractPath string) (*Workspace, error) {
+	src, err := filepath.Abs(contractPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve contract path: %v", err)
+	}
+	if err := os.MkdirAll(WorkspaceRoot, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create workspace root: %v", err)
+	}
+	dir, err := os.MkdirTemp(WorkspaceRoot, workspacePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create workspace: %v", err)
+	}
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to read contract path: %v", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if workspaceSkip[name] {
+			continue
+		}
+		if workspaceLinks[name] {
+			err = os.Symlink(filepath.Join(src, name), filepath.Join(dir, name))
+		} else {
+			err = copyPath(filepath.Join(src, name), filepath.Join(dir, name))
+		}
+		if err != nil {
+			os.RemoveAll(dir)
+			return nil, fmt.Errorf("failed to prepare workspace: %v", err)
+		}
+	}
+
+	return &Workspace{Dir: dir, EnvPath: filepath.Join(dir, ".env"), logger: Logger(ctx)}, nil
+}
+
+// Cleanup removes the workspace. A failed workspace is kept when
+// KeepFailedWorkspaces is set, with the private key scrubbed from its env file.
+func (w *Workspace) Cleanup(failed bool) {
+	if failed && KeepFailedWorkspaces {
+		w.Keep()
+		return
+	}
+	if err := os.RemoveAll(w.Dir); err != nil {
+		w.logger.Error("failed to remove workspace", "workspace", w.Dir, "error", err)
+	}
+}
+
+// Keep scrubs the private key from the workspace env file and moves the
+// workspace out of the way of CleanStaleWorkspaces. It returns the kept path.
+func (w *Workspace) Keep() string {
+	if err := scrubEnvFile(w.EnvPath); err != nil {
+		w.logger.Error("failed to scrub workspace env file", "error", err)
+	}
+	kept := filepath.Join(WorkspaceRoot, failedWorkspacePrefix+strings.TrimPrefix(filepath.Base(w.Dir), workspacePrefix))
+	if err := os.Rename(w.Dir, kept); err != nil {
+		w.logger.Error("failed to keep workspace", "workspace", w.Dir, "error", err)
+		kept = w.Dir
+	}
+	w.logger.Info("keeping failed workspace", "workspace", kept)
+	return kept
+}
+
+// CleanStaleWorkspaces removes the workspaces left behind by jobs that were
+// interrupted, e.g. by a crash. Kept failed workspaces are left untouched.
+func CleanStaleWorkspaces() error {
+	entries, err := os.ReadDir(WorkspaceRoot)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read workspace root: %v", err)
+	}
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), workspacePrefix) {
+			continue
+		}
+		path := filepath.Join(WorkspaceRoot, entry.Name())
+		slog.Info("removing stale workspace", "workspace", path)
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove stale workspace: %v", err)
+		}
+	}
+	return nil
+}
+
+// scrubEnvFile drops the private key from the env file at path
+func scrubEnvFile(path string) error {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "PRIVATE_KEY=") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0600)
+}
+
+// copyPath recursively copies the file or directory at src to dst
+func copyPath(src, dst string) error {
+	info, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case info.IsDir():
+		if err := os.MkdirAll(dst, info.Mode().Perm()); err != nil {
+			return err
+		}
+		entries, err := os.ReadDir(src)
+		if err != nil {
+			return err
+		}
+		for _, entry := range entries {
+			if err := copyPath(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return copyFile(src, dst, info.Mode().Perm())
+	}
+}
+
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewWorkspace checks the contracts tree is copied without the shared env
+// and build outputs, with the dependencies linked
+func TestNewWorkspace(t *testing.T) {
+	setupTest(t, &FakeExecutor{})
+
+	src := t.TempDir()
+	for _, name := range []string{"src/Token.sol", "lib/forge-std/Test.sol", "out/Token.json", "cache/solidity-files-cache.json", "broadcast/run.json"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(src, filepath.Dir(name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(src, name), []byte(name), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(src, ".env"), []byte("PRIVATE_KEY=0xshared\n"), 0600))
+
+	ws, err := NewWorkspace(context.Background(), src)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(ws.Dir, ".env"), ws.EnvPath)
+
+	content, err := os.ReadFile(filepath.Join(ws.Dir, "src", "Token.sol"))
+	require.NoError(t, err)
+	assert.Equal(t, "src/Token.sol", string(content))
+
+	target, err := os.Readlink(filepath.Join(ws.Dir, "lib"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(src, "lib"), target)
+
+	for _, name := range []string{".env", "out", "cache", "broadcast"} {
+		_, err := os.Lstat(filepath.Join(ws.Dir, name))
+		assert.True(t, os.IsNotExist(err), name)
+	}
+
+	// Writes in the workspace leave the contracts tree untouched
+	require.NoError(t, os.WriteFile(filepath.Join(ws.Dir, "src", "Token.sol"), []byte("changed"), 0644))
+	content, err = os.ReadFile(filepath.Join(src, "src", "Token.sol"))
+	require.NoError(t, err)
+	assert.Equal(t, "src/Token.sol", string(content))
+
+	ws.Cleanup(false)
+	_, err = os.Stat(ws.Dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// TestCleanStaleWorkspaces checks the workspaces of interrupted jobs are
+// removed and the kept failed ones are left with the key scrubbed
+func TestCleanStaleWorkspaces(t *testing.T) {
+	setupTest(t, &FakeExecutor{})
+
+	stale, err := NewWorkspace(context.Background(), contractsPath)
+	require.NoError(t, err)
+	failed, err := NewWorkspace(context.Background(), contractsPath)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(failed.EnvPath, []byte("PRIVATE_KEY=0xkey\nTOKEN_NAME=TokenName\n"), 0600))
+	kept := failed.Keep()
+
+	require.NoError(t, CleanStaleWorkspaces())
+
+	_, err = os.Stat(stale.Dir)
+	assert.True(t, os.IsNotExist(err))
+	content, err := os.ReadFile(filepath.Join(kept, ".env"))
+	require.NoError(t, err)
+	assert.Equal(t, "TOKEN_NAME=TokenName\n", string(content))
+}